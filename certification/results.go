@@ -9,9 +9,31 @@ import (
 
 type openshiftClusterVersion = runtime.OpenshiftClusterVersion
 
+// Reasons a check may be skipped.
+const (
+	// SkipReasonPolicyException indicates the check is not part of the policy
+	// that applies to the image, e.g. because its project is for a scratch image.
+	SkipReasonPolicyException = "policy exception"
+	// SkipReasonMissingPrerequisite indicates the check could not apply, e.g.
+	// because something it needs is not available.
+	SkipReasonMissingPrerequisite = "missing prerequisite"
+	// SkipReasonUserExclusion indicates the check was excluded by configuration.
+	SkipReasonUserExclusion = "user exclusion"
+	// SkipReasonOptionalLevel indicates the check ran, but its outcome does not
+	// count because its level is optional.
+	SkipReasonOptionalLevel = "optional level"
+)
+
 type Result struct {
 	check.Check
 	ElapsedTime time.Duration
+	// Findings details the specific problems the check detected, if any.
+	Findings []check.Finding
+	// SkipReason is one of the SkipReason constants if this Result is in
+	// the Results{}.Skipped slice.
+	SkipReason string
+	// SkipMessage details why the check was skipped.
+	SkipMessage string
 	// Err contains the error a check itself throws if it failed to run.
 	// If populated, the expectation is that this Result is in the
 	// Results{}.Errors slice.
@@ -27,6 +49,21 @@ type Results struct {
 	Failed            []Result
 	Errors            []Result
 	Warned            []Result
+	// TimedOut contains the checks that were cancelled because they ran for
+	// longer than their timeout. The error of each Result describes the timeout.
+	TimedOut []Result
+	// Skipped contains the checks that were not run, or whose outcome does
+	// not count towards PassedOverall. Each Result records why.
+	Skipped []Result
+	// Checks lists the names of the checks that were run, in order.
+	Checks []string
+	// LevelOverrides maps the names of checks whose level was changed by
+	// configuration to the level they were run with.
+	LevelOverrides map[string]string
+	// Partial is true if the checks run differ from those of the policy, e.g.
+	// because checks were included, excluded or had their level changed.
+	// Partial results cannot be submitted.
+	Partial bool
 }
 
 func (r Result) Error() error {
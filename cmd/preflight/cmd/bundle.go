@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	rt "runtime"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/spf13/cobra"
+)
+
+func bundleCmd() *cobra.Command {
+	bundleCmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Work with operator bundles",
+	}
+
+	bundleCmd.AddCommand(bundleHashCmd())
+
+	return bundleCmd
+}
+
+func bundleHashCmd() *cobra.Command {
+	bundleHashCmd := &cobra.Command{
+		Use:   "hash",
+		Short: "Work with the certification hash of operator bundles",
+	}
+
+	bundleHashCmd.AddCommand(bundleHashVerifyCmd())
+
+	return bundleHashCmd
+}
+
+func bundleHashVerifyCmd() *cobra.Command {
+	var pull bundlePullConfig
+	bundleHashVerifyCmd := &cobra.Command{
+		Use:   "verify <directory|image> <hash>",
+		Short: "Verify that a bundle matches a certification hash",
+		Long: "This command will compute the certification hash of a bundle and compare it to the certification_hash " +
+			"reported by `preflight check operator`. The bundle can be an extracted bundle directory, or a bundle image.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hash, err := hashBundle(cmd.Context(), args[0], pull)
+			if err != nil {
+				return err
+			}
+
+			if err := bundle.VerifyHash(hash, args[1]); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s matches certification hash %s\n", args[0], args[1])
+			return nil
+		},
+	}
+
+	flags := bundleHashVerifyCmd.Flags()
+	flags.StringVarP(&pull.dockerConfig, "docker-config", "d", "", "Path to docker config.json file. This value is optional for publicly accessible images.")
+	flags.BoolVar(&pull.insecure, "insecure", false, "Use insecure protocol for the registry.")
+
+	return bundleHashVerifyCmd
+}
+
+// hashBundle computes the certification hash of the bundle directory or
+// image at src.
+func hashBundle(ctx context.Context, src string, pull bundlePullConfig) (bundle.Hash, error) {
+	if info, err := os.Stat(src); err == nil && info.IsDir() {
+		return bundle.HashDir(src)
+	}
+
+	img, err := crane.Pull(src, option.GenerateCraneOptions(ctx, pull)...)
+	if err != nil {
+		return bundle.Hash{}, fmt.Errorf("failed to pull bundle image: %v", err)
+	}
+
+	rc := mutate.Extract(img)
+	defer rc.Close()
+
+	return bundle.HashTar(rc)
+}
+
+// bundlePullConfig configures how bundle images are pulled.
+type bundlePullConfig struct {
+	dockerConfig string
+	insecure     bool
+}
+
+var _ option.CraneConfig = bundlePullConfig{}
+
+func (c bundlePullConfig) CraneDockerConfig() string {
+	return c.dockerConfig
+}
+
+// CranePlatform returns the runtime platform. Bundle images contain no
+// binaries, so they are rarely published for more than one platform.
+func (c bundlePullConfig) CranePlatform() string {
+	return rt.GOARCH
+}
+
+func (c bundlePullConfig) CraneInsecure() bool {
+	return c.insecure
+}
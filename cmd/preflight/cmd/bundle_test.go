@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("bundle subcommand", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(dir, "metadata"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "metadata", "annotations.yaml"), []byte("annotations"), 0o644)).To(Succeed())
+	})
+
+	It("should verify a bundle directory matching the hash", func() {
+		hash, err := bundle.HashDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+
+		out, err := executeCommand(bundleCmd(), "hash", "verify", dir, hash.String())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(ContainSubstring("matches certification hash"))
+	})
+
+	It("should fail for a bundle directory not matching the hash", func() {
+		_, err := executeCommand(bundleCmd(), "hash", "verify", dir, bundle.HashScheme+":0000")
+		Expect(err).To(MatchError(bundle.ErrHashMismatch))
+	})
+})
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layercache"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
+
+	"github.com/spf13/cobra"
+)
+
+func cacheCmd() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the persistent layer cache",
+		Long: "This command will allow you to inspect and prune the layer cache configured with --cache-dir (env: PFLT_CACHE_DIR). " +
+			"The cache is shared by all preflight processes using the same directory.",
+	}
+
+	cacheCmd.AddCommand(cachePruneCmd())
+	cacheCmd.AddCommand(cacheStatsCmd())
+
+	return cacheCmd
+}
+
+func cachePruneCmd() *cobra.Command {
+	var all bool
+	cachePruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Evict least recently used layers from the cache",
+		Long: "This command will evict least recently used layers until the cache is no larger than --cache-max-size. " +
+			"Pruning is not possible while another preflight process is using the cache.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := cacheDir()
+			if err != nil {
+				return err
+			}
+
+			maxSize := int64(0)
+			if !all {
+				maxSize, err = runtime.ParseCacheMaxSize(viper.Instance().GetString("cache_max_size"))
+				if err != nil {
+					return err
+				}
+			}
+
+			result, err := layercache.Prune(dir, maxSize)
+			if errors.Is(err, layercache.ErrCacheInUse) {
+				return fmt.Errorf("%w: try again once other preflight runs have completed", err)
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %d layers, reclaiming %s\n", result.Removed, formatBytes(result.Reclaimed))
+			return nil
+		},
+	}
+
+	cachePruneCmd.Flags().BoolVar(&all, "all", false, "Remove all layers from the cache.")
+
+	return cachePruneCmd
+}
+
+func cacheStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show information about the layer cache",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := cacheDir()
+			if err != nil {
+				return err
+			}
+
+			stats, err := layercache.ReadStats(dir)
+			if err != nil {
+				return err
+			}
+
+			printCacheStats(cmd.OutOrStdout(), dir, stats)
+			return nil
+		},
+	}
+}
+
+// cacheDir returns the configured cache directory.
+func cacheDir() (string, error) {
+	dir := viper.Instance().GetString("cache_dir")
+	if dir == "" {
+		return "", errors.New("no layer cache is configured: set --cache-dir or PFLT_CACHE_DIR")
+	}
+	return dir, nil
+}
+
+// printCacheStats writes the formatted cache statistics to w.
+func printCacheStats(w io.Writer, dir string, stats layercache.Stats) {
+	fmt.Fprintf(w, "Directory: %s\n", dir)
+	fmt.Fprintf(w, "Layers: %d\n", stats.Entries)
+	fmt.Fprintf(w, "Size: %s\n", formatBytes(stats.Size))
+	if stats.Entries > 0 {
+		fmt.Fprintf(w, "Least recently used: %s\n", stats.Oldest.Format(time.RFC3339))
+		fmt.Fprintf(w, "Most recently used: %s\n", stats.Newest.Format(time.RFC3339))
+	}
+}
+
+// formatBytes returns n in human readable binary units, e.g. 1.5GiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
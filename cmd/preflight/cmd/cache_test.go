@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cache subcommand", func() {
+	BeforeEach(createAndCleanupDirForArtifactsAndLogs)
+
+	Context("When no cache directory is configured", func() {
+		It("should return an error", func() {
+			_, err := executeCommand(cacheCmd(), "stats")
+			Expect(err).To(MatchError(ContainSubstring("no layer cache is configured")))
+		})
+	})
+
+	Context("When a cache directory is configured", func() {
+		var dir string
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			blob := filepath.Join(dir, "blobs", "sha256", "aaaa")
+			Expect(os.MkdirAll(filepath.Dir(blob), 0o755)).To(Succeed())
+			Expect(os.WriteFile(blob, make([]byte, 2048), 0o644)).To(Succeed())
+
+			viper.Instance().Set("cache_dir", dir)
+			DeferCleanup(viper.Instance().Set, "cache_dir", "")
+		})
+
+		It("should print cache statistics", func() {
+			out, err := executeCommand(cacheCmd(), "stats")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(ContainSubstring("Layers: 1"))
+			Expect(out).To(ContainSubstring("Size: 2.0KiB"))
+		})
+
+		It("should remove all layers with prune --all", func() {
+			out, err := executeCommand(cacheCmd(), "prune", "--all")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(ContainSubstring("Removed 1 layers, reclaiming 2.0KiB"))
+		})
+	})
+
+	DescribeTable("Formatting byte counts",
+		func(n int64, expected string) {
+			Expect(formatBytes(n)).To(Equal(expected))
+		},
+		Entry("bytes", int64(512), "512B"),
+		Entry("kibibytes", int64(1536), "1.5KiB"),
+		Entry("gibibytes", int64(10<<30), "10.0GiB"),
+	)
+})
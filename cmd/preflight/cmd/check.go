@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/cli"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 
 	"github.com/spf13/cobra"
@@ -23,6 +24,30 @@ func checkCmd() *cobra.Command {
 	checkCmd.PersistentFlags().String("artifacts", "", "Where check-specific artifacts will be written. (env: PFLT_ARTIFACTS)")
 	_ = viper.BindPFlag("artifacts", checkCmd.PersistentFlags().Lookup("artifacts"))
 
+	checkCmd.PersistentFlags().Int("parallelism", runtime.DefaultParallelism, "Maximum number of checks to run concurrently. (env: PFLT_PARALLELISM)")
+	_ = viper.BindPFlag("parallelism", checkCmd.PersistentFlags().Lookup("parallelism"))
+
+	checkCmd.PersistentFlags().String("extract-max-size", "", "Maximum total size of the image filesystem to extract, e.g. 32Gi. (env: PFLT_EXTRACT_MAX_SIZE)")
+	_ = viper.BindPFlag("extract_max_size", checkCmd.PersistentFlags().Lookup("extract-max-size"))
+
+	checkCmd.PersistentFlags().String("extract-max-file-size", "", "Maximum size of any single file in the image filesystem, e.g. 16Gi. (env: PFLT_EXTRACT_MAX_FILE_SIZE)")
+	_ = viper.BindPFlag("extract_max_file_size", checkCmd.PersistentFlags().Lookup("extract-max-file-size"))
+
+	checkCmd.PersistentFlags().Int("extract-max-entries", 0, "Maximum number of files, directories and links to extract from the image. (env: PFLT_EXTRACT_MAX_ENTRIES)")
+	_ = viper.BindPFlag("extract_max_entries", checkCmd.PersistentFlags().Lookup("extract-max-entries"))
+
+	checkCmd.PersistentFlags().Duration("check-timeout", runtime.DefaultCheckTimeout, "Maximum time any single check may run before it is cancelled and recorded as timed out.\n"+
+		"Use 0 to disable. Timeouts for specific checks can be set in the config file. (env: PFLT_CHECK_TIMEOUT)")
+	_ = viper.BindPFlag("check_timeout", checkCmd.PersistentFlags().Lookup("check-timeout"))
+
+	checkCmd.PersistentFlags().StringSlice("include-checks", nil, "Only run the named checks, e.g. HasLicense,RunAsNonRoot. Results of a run that does not include\n"+
+		"every check of the policy cannot be submitted. (env: PFLT_INCLUDE_CHECKS)")
+	_ = viper.BindPFlag("include_checks", checkCmd.PersistentFlags().Lookup("include-checks"))
+
+	checkCmd.PersistentFlags().StringSlice("exclude-checks", nil, "Do not run the named checks, e.g. DeployableByOLM. Results of a run that does not include\n"+
+		"every check of the policy cannot be submitted. (env: PFLT_EXCLUDE_CHECKS)")
+	_ = viper.BindPFlag("exclude_checks", checkCmd.PersistentFlags().Lookup("exclude-checks"))
+
 	checkCmd.AddCommand(checkOperatorCmd(cli.RunPreflight))
 	checkCmd.AddCommand(checkContainerCmd(cli.RunPreflight))
 
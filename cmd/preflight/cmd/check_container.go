@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,13 +12,16 @@ import (
 	rt "runtime"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/container"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/cli"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/engine"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
@@ -28,6 +32,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -42,10 +47,15 @@ func checkContainerCmd(runpreflight runPreflight) *cobra.Command {
 	checkContainerCmd := &cobra.Command{
 		Use:   "container",
 		Short: "Run checks for a container",
-		Long:  `This command will run the Certification checks for a container image. `,
-		Args:  checkContainerPositionalArgs,
+		Long: `This command will run the Certification checks for a container image. ` +
+			`Images may also be read from the local filesystem by prefixing the reference with ` +
+			`oci:, oci-archive:, or docker-archive:.`,
+		Args: checkContainerPositionalArgs,
 		// this fmt.Sprintf is in place to keep spacing consistent with cobras two spaces that's used in: Usage, Flags, etc
-		Example: fmt.Sprintf("  %s", "preflight check container quay.io/repo-name/container-name:version"),
+		Example: fmt.Sprintf("  %s\n  %s\n  %s",
+			"preflight check container quay.io/repo-name/container-name:version",
+			"preflight check container oci:/path/to/layout:version",
+			"preflight check container docker-archive:/path/to/image.tar"),
 		PreRunE: validateCertificationProjectID,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return checkContainerRunE(cmd, args, runpreflight)
@@ -72,6 +82,14 @@ func checkContainerCmd(runpreflight runPreflight) *cobra.Command {
 	// Make --submit mutually exclusive to --insecure
 	checkContainerCmd.MarkFlagsMutuallyExclusive("submit", "insecure")
 
+	flags.Bool("metadata-only", false, "Only run checks that can be evaluated from the image's manifest and config, without downloading\n"+
+		"its layers. Useful for quick feedback, e.g. in pre-commit hooks. Cannot be used with submit or offline. (env: PFLT_METADATA_ONLY)")
+	_ = viper.BindPFlag("metadata_only", flags.Lookup("metadata-only"))
+
+	// Results of a partial run cannot be submitted
+	checkContainerCmd.MarkFlagsMutuallyExclusive("submit", "metadata-only")
+	checkContainerCmd.MarkFlagsMutuallyExclusive("offline", "metadata-only")
+
 	flags.String("pyxis-api-token", "", "API token for Pyxis authentication (env: PFLT_PYXIS_API_TOKEN)")
 	_ = viper.BindPFlag("pyxis_api_token", flags.Lookup("pyxis-api-token"))
 
@@ -89,6 +107,45 @@ func checkContainerCmd(runpreflight runPreflight) *cobra.Command {
 	flags.String("platform", rt.GOARCH, "Architecture of image to pull. Defaults to runtime platform.")
 	_ = viper.BindPFlag("platform", flags.Lookup("platform"))
 
+	flags.Bool("parallel-platforms", false, "Check the platforms of a manifest list concurrently, sharing layers common to several platforms.\n"+
+		"(env: PFLT_PARALLEL_PLATFORMS)")
+	_ = viper.BindPFlag("parallel_platforms", flags.Lookup("parallel-platforms"))
+
+	flags.StringSlice("required-architectures", nil, "Architectures the manifest list must contain an image for, e.g. amd64,s390x. (env: PFLT_REQUIRED_ARCHITECTURES)")
+	_ = viper.BindPFlag("required_architectures", flags.Lookup("required-architectures"))
+
+	flags.StringSlice("allowed-capabilities", nil, "File capabilities executables in the image may carry, e.g. cap_net_bind_service,cap_net_raw. "+
+		"Defaults to cap_net_bind_service. (env: PFLT_ALLOWED_CAPABILITIES)")
+	_ = viper.BindPFlag("allowed_capabilities", flags.Lookup("allowed-capabilities"))
+
+	flags.Bool("enforce-secrets-check", false, "Scan the image for secrets, and fail the certification if any are found. "+
+		"(env: PFLT_ENFORCE_SECRETS_CHECK)")
+	_ = viper.BindPFlag("enforce_secrets_check", flags.Lookup("enforce-secrets-check"))
+
+	flags.Bool("fips-check", false, "Check that the crypto used by the image's binaries can run in FIPS mode, to back a fips-compliant claim. "+
+		"(env: PFLT_FIPS_CHECK)")
+	_ = viper.BindPFlag("fips_check", flags.Lookup("fips-check"))
+
+	flags.StringSlice("enable-checks", nil, fmt.Sprintf("Optional checks to add to the policy: %s. ", strings.Join(engine.OptionalContainerChecks, ", "))+
+		"(env: PFLT_ENABLE_CHECKS)")
+	_ = viper.BindPFlag("enable_checks", flags.Lookup("enable-checks"))
+
+	flags.StringSlice("sbom-format", []string{"none"}, "Formats to write the image's SBOM to the artifacts directory in: spdx, cyclonedx or none. "+
+		"(env: PFLT_SBOM_FORMAT)")
+	_ = viper.BindPFlag("sbom_format", flags.Lookup("sbom-format"))
+
+	flags.String("advisory-db", "", "An OSV or CSAF JSON file, or a directory of them, to match the image's rpms and Go modules against\n"+
+		"for known vulnerabilities. No network access is required. (env: PFLT_ADVISORY_DB)")
+	_ = viper.BindPFlag("advisory_db", flags.Lookup("advisory-db"))
+
+	flags.StringSlice("binary-hardening-minimum", nil, "Hardening properties binaries not installed by rpms must have: pie, relro, nx, stack-protector, fortify, or none.\n"+
+		"Defaults to pie,relro,nx,stack-protector. (env: PFLT_BINARY_HARDENING_MINIMUM)")
+	_ = viper.BindPFlag("binary_hardening_minimum", flags.Lookup("binary-hardening-minimum"))
+
+	flags.String("base-image-catalog", "", "A JSON or YAML catalog of approved base images to look the image's layers up in, instead of Pyxis.\n"+
+		"Export it with preflight runtime-assets base-catalog export. (env: PFLT_BASE_IMAGE_CATALOG)")
+	_ = viper.BindPFlag("base_image_catalog", flags.Lookup("base-image-catalog"))
+
 	return checkContainerCmd
 }
 
@@ -116,84 +173,206 @@ func checkContainerRunE(cmd *cobra.Command, args []string, runpreflight runPrefl
 		return err
 	}
 
-	for _, platform := range containerImagePlatforms {
-		logger.Info(fmt.Sprintf("running checks for %s for platform %s", containerImage, platform))
-		artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(filepath.Join(cfg.Artifacts, platform)))
-		if err != nil {
-			return err
-		}
+	opts := generateContainerCheckOptions(cfg)
+	if cfg.ManifestListDigest != "" {
+		opts = append(opts, container.WithManifestListPlatforms(containerImagePlatforms...))
+	}
 
-		// Add the artifact writer to the context for use by checks.
-		ctx := artifacts.ContextWithWriter(ctx, artifactsWriter)
+	// Run the container checks.
+	cmd.SilenceUsage = true
 
-		formatter, err := formatters.NewByName(formatters.DefaultFormat)
-		if err != nil {
-			return err
+	// Summarize the manifest list if more than one of its platforms is checked.
+	var index *formatters.ResultsIndex
+	if cfg.ManifestListDigest != "" && len(containerImagePlatforms) > 1 {
+		index = formatters.NewResultsIndex(containerImage, cfg.ManifestListDigest)
+	}
+
+	var runErr error
+	if cfg.ParallelPlatforms && len(containerImagePlatforms) > 1 {
+		// Platforms that could not be checked are recorded in the index.
+		runErr = checkContainerPlatformsConcurrently(ctx, cfg, containerImage, containerImagePlatforms, opts, runpreflight, index)
+	} else {
+		for _, platform := range containerImagePlatforms {
+			logger.Info(fmt.Sprintf("running checks for %s for platform %s", containerImage, platform))
+			results, resultsPath, err := checkContainerPlatform(ctx, cfg, containerImage, platform, opts, runpreflight)
+			if err != nil {
+				return err
+			}
+			if index != nil {
+				index.Add(platform, resultsPath, results, nil)
+			}
 		}
+	}
 
-		opts := generateContainerCheckOptions(cfg)
-		opts = append(opts, container.WithPlatform(platform))
-
-		checkcontainer := container.NewCheck(
-			containerImage,
-			opts...,
-		)
-
-		pc := lib.NewPyxisClient(ctx, cfg.CertificationProjectID, cfg.PyxisAPIToken, cfg.PyxisHost)
-		resultSubmitter := lib.ResolveSubmitter(pc, cfg.CertificationProjectID, cfg.DockerConfig, cfg.LogFile)
-
-		// Run the  container check.
-		cmd.SilenceUsage = true
-
-		if err := runpreflight(
-			ctx,
-			checkcontainer.Run,
-			cli.CheckConfig{
-				IncludeJUnitResults: cfg.WriteJUnit,
-				SubmitResults:       cfg.Submit,
-			},
-			formatter,
-			&runtime.ResultWriterFile{},
-			resultSubmitter,
-		); err != nil {
-			return err
+	if index != nil {
+		if err := writeResultsIndex(ctx, cmd.OutOrStdout(), cfg.Artifacts, index); err != nil {
+			return errors.Join(runErr, err)
 		}
+	}
 
-		// checking for offline flag, if present tar up the contents of the artifacts directory
-		if cfg.Offline {
-			src := artifactsWriter.Path()
-			var buf bytes.Buffer
+	return runErr
+}
 
-			// check to see if a tar file already exist to account for someone re-running
-			exists, err := artifactsWriter.Exists(check.DefaultArtifactsTarFileName)
-			if err != nil {
-				return fmt.Errorf("unable to check if tar already exists: %v", err)
-			}
+// checkContainerPlatformsConcurrently checks all platforms at the same time,
+// recording their outcomes in index, if not nil. Layers common to several
+// platforms are shared through the layer cache, or a temporary one if none
+// is configured. The checks of every platform are run, even if some fail to run.
+func checkContainerPlatformsConcurrently(ctx context.Context, cfg *runtime.Config, containerImage string, platforms []string, opts []container.Option, runpreflight runPreflight, index *formatters.ResultsIndex) error {
+	logger := logr.FromContextOrDiscard(ctx)
 
-			// remove the tar file if it exists
-			if exists {
-				err = artifactsWriter.Remove(check.DefaultArtifactsTarFileName)
-				if err != nil {
-					return fmt.Errorf("unable to remove existing tar: %v", err)
-				}
+	if cfg.CacheDir == "" {
+		sharedLayers, err := os.MkdirTemp(os.TempDir(), "preflight-layers-*")
+		if err != nil {
+			return fmt.Errorf("failed to create shared layer directory: %v", err)
+		}
+		defer func() {
+			if err := os.RemoveAll(sharedLayers); err != nil {
+				logger.Error(err, "unable to clean up shared layer directory", "path", sharedLayers)
 			}
+		}()
+		// The directory is removed once all platforms are checked, so it is left
+		// unbounded until then.
+		opts = append(slices.Clip(opts), container.WithLayerCache(sharedLayers, 0))
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for _, platform := range platforms {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 
-			// tar the directory
-			err = artifactsTar(ctx, src, &buf)
+			platformLogger := logger.WithValues("platform", platform)
+			platformLogger.Info(fmt.Sprintf("running checks for %s for platform %s", containerImage, platform))
+			results, resultsPath, err := checkContainerPlatform(logr.NewContext(ctx, platformLogger), cfg, containerImage, platform, opts, runpreflight)
+
+			mu.Lock()
+			defer mu.Unlock()
 			if err != nil {
-				return fmt.Errorf("unable to tar up artifacts directory: %v", err)
+				errs = append(errs, fmt.Errorf("platform %s: %w", platform, err))
+			}
+			if index != nil {
+				index.Add(platform, resultsPath, results, err)
 			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// checkContainerPlatform runs the container checks for platform, writing its
+// artifacts to a directory named after the platform. The results and the path of
+// the results file, relative to the artifacts directory, are returned.
+func checkContainerPlatform(ctx context.Context, cfg *runtime.Config, containerImage, platform string, opts []container.Option, runpreflight runPreflight) (certification.Results, string, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(filepath.Join(cfg.Artifacts, platform)))
+	if err != nil {
+		return certification.Results{}, "", err
+	}
+
+	// Add the artifact writer to the context for use by checks.
+	ctx = artifacts.ContextWithWriter(ctx, artifactsWriter)
+
+	formatter, err := formatters.NewByName(formatters.DefaultFormat)
+	if err != nil {
+		return certification.Results{}, "", err
+	}
+	resultsPath := filepath.Join(platform, cli.ResultsFilenameWithExtension(formatter.FileExtension()))
+
+	checkcontainer := container.NewCheck(
+		containerImage,
+		append(slices.Clip(opts), container.WithPlatform(platform))...,
+	)
+
+	pc := lib.NewPyxisClient(ctx, cfg.CertificationProjectID, cfg.PyxisAPIToken, cfg.PyxisHost)
+	resultSubmitter := lib.ResolveSubmitter(pc, cfg.CertificationProjectID, cfg.DockerConfig, cfg.LogFile)
+
+	// Keep the results for the results index.
+	var results certification.Results
+	run := func(ctx context.Context) (certification.Results, error) {
+		var err error
+		results, err = checkcontainer.Run(ctx)
+		return results, err
+	}
+
+	if err := runpreflight(
+		ctx,
+		run,
+		cli.CheckConfig{
+			IncludeJUnitResults: cfg.WriteJUnit,
+			SubmitResults:       cfg.Submit,
+		},
+		formatter,
+		&runtime.ResultWriterFile{},
+		resultSubmitter,
+	); err != nil {
+		return certification.Results{}, resultsPath, err
+	}
+
+	// checking for offline flag, if present tar up the contents of the artifacts directory
+	if cfg.Offline {
+		src := artifactsWriter.Path()
+		var buf bytes.Buffer
+
+		// check to see if a tar file already exist to account for someone re-running
+		exists, err := artifactsWriter.Exists(check.DefaultArtifactsTarFileName)
+		if err != nil {
+			return results, resultsPath, fmt.Errorf("unable to check if tar already exists: %v", err)
+		}
 
-			// writing the tar file to disk
-			_, err = artifactsWriter.WriteFile(check.DefaultArtifactsTarFileName, &buf)
+		// remove the tar file if it exists
+		if exists {
+			err = artifactsWriter.Remove(check.DefaultArtifactsTarFileName)
 			if err != nil {
-				return fmt.Errorf("could not artifacts tar to artifacts dir: %w", err)
+				return results, resultsPath, fmt.Errorf("unable to remove existing tar: %v", err)
 			}
+		}
+
+		// tar the directory
+		err = artifactsTar(ctx, src, &buf)
+		if err != nil {
+			return results, resultsPath, fmt.Errorf("unable to tar up artifacts directory: %v", err)
+		}
 
-			logger.Info("artifact tar written to disk", "filename", check.DefaultArtifactsTarFileName)
+		// writing the tar file to disk
+		_, err = artifactsWriter.WriteFile(check.DefaultArtifactsTarFileName, &buf)
+		if err != nil {
+			return results, resultsPath, fmt.Errorf("could not artifacts tar to artifacts dir: %w", err)
 		}
+
+		logger.Info("artifact tar written to disk", "filename", check.DefaultArtifactsTarFileName)
+	}
+
+	return results, resultsPath, nil
+}
+
+// writeResultsIndex writes index to the artifacts directory and to w, and
+// logs the verdict for the manifest list.
+func writeResultsIndex(ctx context.Context, w io.Writer, artifactsDir string, index *formatters.ResultsIndex) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	out, err := formatters.FormatResultsIndex(index)
+	if err != nil {
+		return err
+	}
+
+	artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(artifactsDir))
+	if err != nil {
+		return err
+	}
+	if _, err := artifactsWriter.WriteFile(formatters.ResultsIndexFilename, bytes.NewReader(out)); err != nil {
+		return fmt.Errorf("could not write results index: %w", err)
 	}
+	fmt.Fprintln(w, string(out))
 
+	verdict := "FAILED"
+	if index.PassedOverall {
+		verdict = "PASSED"
+	}
+	logger.Info(fmt.Sprintf("Preflight result for manifest list %s: %s", index.ManifestListDigest, verdict))
 	return nil
 }
 
@@ -213,6 +392,11 @@ func checkContainerPositionalArgs(cmd *cobra.Command, args []string) error {
 	// --submit was specified
 	viper := viper.Instance()
 	if submit {
+		// Only images in a registry can be certified.
+		if image.IsLocalSource(args[0]) {
+			return fmt.Errorf("images read from a local source cannot be submitted: push the image to a registry first")
+		}
+
 		// If the flag is not marked as changed AND viper hasn't gotten it from environment, it's an error
 		if !cmd.Flag("certification-project-id").Changed && !viper.IsSet("certification_project_id") {
 			return fmt.Errorf("certification Project ID must be specified when --submit is present")
@@ -267,6 +451,49 @@ func generateContainerCheckOptions(cfg *runtime.Config) []container.Option {
 		container.WithPyxisHost(cfg.PyxisHost),
 		container.WithPlatform(cfg.Platform),
 		container.WithManifestListDigest(cfg.ManifestListDigest),
+		container.WithParallelism(cfg.Parallelism),
+		container.WithExtractionLimits(cfg.ExtractionLimits.MaxSize, cfg.ExtractionLimits.MaxFileSize, cfg.ExtractionLimits.MaxEntries),
+		container.WithCheckTimeouts(cfg.CheckTimeouts.Default, cfg.CheckTimeouts.Overrides),
+	}
+
+	if cfg.CacheDir != "" {
+		o = append(o, container.WithLayerCache(cfg.CacheDir, cfg.CacheMaxSize))
+	}
+
+	if len(cfg.RequiredArchitectures) > 0 {
+		o = append(o, container.WithRequiredArchitectures(cfg.RequiredArchitectures...))
+	}
+
+	if len(cfg.AllowedCapabilities) > 0 {
+		o = append(o, container.WithAllowedCapabilities(cfg.AllowedCapabilities...))
+	}
+
+	if cfg.EnforceSecretsCheck {
+		o = append(o, container.WithEnforcedSecretsCheck())
+	}
+
+	if cfg.FIPSCheck {
+		o = append(o, container.WithFIPSCheck())
+	}
+
+	if len(cfg.EnabledChecks) > 0 {
+		o = append(o, container.WithEnabledChecks(cfg.EnabledChecks...))
+	}
+
+	if len(cfg.SBOMFormats) > 0 {
+		o = append(o, container.WithSBOMFormats(cfg.SBOMFormats...))
+	}
+
+	if cfg.AdvisoryDB != "" {
+		o = append(o, container.WithAdvisoryDB(cfg.AdvisoryDB))
+	}
+
+	if len(cfg.HardeningMinimum) > 0 {
+		o = append(o, container.WithBinaryHardeningMinimum(cfg.HardeningMinimum...))
+	}
+
+	if cfg.BaseImageCatalog != "" {
+		o = append(o, container.WithBaseImageCatalog(cfg.BaseImageCatalog))
 	}
 
 	// set auth information if both are present in config.
@@ -274,6 +501,17 @@ func generateContainerCheckOptions(cfg *runtime.Config) []container.Option {
 		o = append(o, container.WithCertificationProject(cfg.CertificationProjectID, cfg.PyxisAPIToken))
 	}
 
+	if cfg.MetadataOnly {
+		// Do not allow for submission of a partial run.
+		// This is a secondary check to be safe.
+		cfg.Submit = false
+		o = append(o, container.WithMetadataOnly())
+	}
+
+	if !cfg.CheckSelection.IsZero() {
+		o = append(o, container.WithCheckSelection(cfg.CheckSelection.Include, cfg.CheckSelection.Exclude, cfg.CheckSelection.Levels))
+	}
+
 	if cfg.Insecure {
 		// Do not allow for submission if Insecure is set.
 		// This is a secondary check to be safe.
@@ -363,6 +601,10 @@ func platformsToBeProcessed(cmd *cobra.Command, cfg *runtime.Config) ([]string,
 	_, platformEnvPresent := os.LookupEnv("PFLT_PLATFORM")
 	platformChanged := cmd.Flags().Lookup("platform").Changed || platformEnvPresent
 
+	if image.IsLocalSource(cfg.Image) {
+		return localPlatformsToBeProcessed(ctx, cfg, platformChanged)
+	}
+
 	containerImagePlatforms := []string{cfg.Platform}
 
 	options := crane.GetOptions(option.GenerateCraneOptions(ctx, cfg)...)
@@ -412,40 +654,88 @@ func platformsToBeProcessed(cmd *cobra.Command, cfg *runtime.Config) ([]string,
 		if err != nil {
 			return nil, fmt.Errorf("could not convert descriptor to index: %w", err)
 		}
-		manifestListDigest, err := idx.Digest()
-		if err != nil {
-			return nil, fmt.Errorf("could not retrieve index digest: %w", err)
-		}
-		cfg.ManifestListDigest = manifestListDigest.String()
 
-		manifest, err := idx.IndexManifest()
-		if err != nil {
-			return nil, fmt.Errorf("could not retrieve index manifest: %w", err)
-		}
+		return indexPlatformsToBeProcessed(idx, cfg, platformChanged)
+	}
 
-		// Preflight was given a manifest list. --platform was not specified.
-		// Therefore, all platforms in the manifest list that we support
-		// for certification ie: {"arm64", "amd64", "ppc64le", "s390x"}, should be processed.
-		// Create a new slice since the original was for a single platform.
-		containerImagePlatforms = make([]string, 0, len(manifest.Manifests))
-		for _, img := range manifest.Manifests {
-			if platformChanged && cfg.Platform != img.Platform.Architecture {
-				// The user selected a platform. If this isn't it, continue.
-				continue
-			}
-			if img.Platform.Architecture == "unknown" && img.Platform.OS == "unknown" {
-				// This must be an attestation manifest. Skip it.
-				continue
-			}
-			if !slices.Contains(allowedArchitectures(), img.Platform.Architecture) {
-				// The user has a architecture type in the manifest list that we do not support.
-				continue
-			}
-			containerImagePlatforms = append(containerImagePlatforms, img.Platform.Architecture)
+	return containerImagePlatforms, nil
+}
+
+// localPlatformsToBeProcessed is the equivalent of platformsToBeProcessed for
+// images read from a local source, such as an OCI layout or a docker archive.
+func localPlatformsToBeProcessed(ctx context.Context, cfg *runtime.Config, platformChanged bool) ([]string, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	src, err := image.OpenLocalSource(cfg.Image)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local image source: %w", err)
+	}
+	defer src.Close()
+
+	idx, err := src.Index()
+	if err != nil {
+		return nil, fmt.Errorf("could not read local image source: %w", err)
+	}
+
+	if idx != nil {
+		logger.V(log.DBG).Info("image index detected, checking all platforms in index")
+		return indexPlatformsToBeProcessed(idx, cfg, platformChanged)
+	}
+
+	img, err := src.Image(cfg.Platform)
+	if err != nil {
+		return nil, fmt.Errorf("could not read local image: %w", err)
+	}
+	cfgFile, err := img.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve image config: %w", err)
+	}
+
+	if cfgFile.Architecture != cfg.Platform && !platformChanged {
+		return nil, fmt.Errorf("cannot process image manifest of different arch without platform override")
+	}
+
+	return []string{cfg.Platform}, nil
+}
+
+// indexPlatformsToBeProcessed returns the platforms in idx that should be
+// processed, and records the index digest in cfg.
+func indexPlatformsToBeProcessed(idx cranev1.ImageIndex, cfg *runtime.Config, platformChanged bool) ([]string, error) {
+	manifestListDigest, err := idx.Digest()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve index digest: %w", err)
+	}
+	cfg.ManifestListDigest = manifestListDigest.String()
+
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve index manifest: %w", err)
+	}
+
+	// Preflight was given a manifest list. --platform was not specified.
+	// Therefore, all platforms in the manifest list that we support
+	// for certification ie: {"arm64", "amd64", "ppc64le", "s390x"}, should be processed.
+	containerImagePlatforms := make([]string, 0, len(manifest.Manifests))
+	for _, img := range manifest.Manifests {
+		if img.Platform == nil {
+			continue
 		}
-		if platformChanged && len(containerImagePlatforms) == 0 {
-			return nil, fmt.Errorf("invalid platform specified")
+		if platformChanged && cfg.Platform != img.Platform.Architecture {
+			// The user selected a platform. If this isn't it, continue.
+			continue
 		}
+		if img.Platform.Architecture == "unknown" && img.Platform.OS == "unknown" {
+			// This must be an attestation manifest. Skip it.
+			continue
+		}
+		if !slices.Contains(allowedArchitectures(), img.Platform.Architecture) {
+			// The user has a architecture type in the manifest list that we do not support.
+			continue
+		}
+		containerImagePlatforms = append(containerImagePlatforms, img.Platform.Architecture)
+	}
+	if platformChanged && len(containerImagePlatforms) == 0 {
+		return nil, fmt.Errorf("invalid platform specified")
 	}
 
 	return containerImagePlatforms, nil
@@ -454,5 +744,5 @@ func platformsToBeProcessed(cmd *cobra.Command, cfg *runtime.Config) ([]string,
 // allowedArchitectures returns a list of container architectures that are supported for certification.
 // Only supported architectures are "arm64", "amd64", "ppc64le", "s390x".
 func allowedArchitectures() []string {
-	return []string{"arm64", "amd64", "ppc64le", "s390x"}
+	return image.SupportedArchitectures()
 }
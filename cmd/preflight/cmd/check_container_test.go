@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -26,6 +27,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
 	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
@@ -121,14 +124,85 @@ var _ = Describe("Check Container Command", func() {
 	BeforeEach(createAndCleanupDirForArtifactsAndLogs)
 
 	When("a manifest list is passed", func() {
+		var artifactsDir string
+		BeforeEach(func() {
+			artifactsDir = GinkgoT().TempDir()
+			viper.Instance().Set("artifacts", artifactsDir)
+			DeferCleanup(viper.Instance().Set, "artifacts", artifacts.DefaultArtifactsDir)
+		})
+
 		When("default params otherwise", func() {
 			It("should not error", func() {
 				_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), manifestListSrc)
 				Expect(err).ToNot(HaveOccurred())
+				Expect(filepath.Join(artifactsDir, formatters.ResultsIndexFilename)).To(BeAnExistingFile())
+			})
+		})
+		When("platforms are checked concurrently", func() {
+			readIndex := func() formatters.ResultsIndex {
+				b, err := os.ReadFile(filepath.Join(artifactsDir, formatters.ResultsIndexFilename))
+				Expect(err).ToNot(HaveOccurred())
+				var index formatters.ResultsIndex
+				Expect(json.Unmarshal(b, &index)).To(Succeed())
+				return index
+			}
+
+			It("should write a results index covering every platform", func() {
+				out, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), manifestListSrc, "--parallel-platforms")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out).To(ContainSubstring(`"manifest_list_digest"`))
+
+				index := readIndex()
+				Expect(index.Image).To(Equal(manifestListSrc))
+				Expect(index.ManifestListDigest).To(HavePrefix("sha256:"))
+				Expect(index.PassedOverall).To(BeFalse())
+				var platforms []string
+				for _, p := range index.Platforms {
+					platforms = append(platforms, p.Platform)
+					Expect(p.Results).To(Equal(filepath.Join(p.Platform, "results.json")))
+				}
+				Expect(platforms).To(Equal([]string{"amd64", "arm64", "ppc64le", "s390x"}))
+			})
+
+			It("should record platforms that could not be checked, and return their errors", func() {
+				_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnErr), logr.Discard(), manifestListSrc, "--parallel-platforms")
+				Expect(err).To(MatchError(ContainSubstring("platform arm64: random error")))
+
+				index := readIndex()
+				Expect(index.Platforms).To(HaveLen(4))
+				for _, p := range index.Platforms {
+					Expect(p.Error).To(Equal("random error"))
+				}
 			})
 		})
 	})
 
+	When("an image from a local OCI layout is passed", func() {
+		var layoutDir string
+		BeforeEach(func() {
+			layoutDir = GinkgoT().TempDir()
+			p, err := layout.Write(layoutDir, empty.Index)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.AppendImage(createPlatformImage("amd64", 0))).To(Succeed())
+		})
+		It("should not error", func() {
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), "oci:"+layoutDir, "--platform", "amd64")
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should error when the image arch differs and no platform override is given", func() {
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), "oci:"+layoutDir)
+			if runtime.GOARCH == "amd64" {
+				Expect(err).ToNot(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		})
+		It("should error when the layout does not exist", func() {
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), "oci:/does/not/exist")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	DescribeTable("--platform tests",
 		func(manifestKey string, platform string, match types.GomegaMatcher, includePlatformArg bool) {
 			args := []string{manifests[manifestKey]}
@@ -173,7 +247,9 @@ var _ = Describe("Check Container Command", func() {
 			Entry("certification-project-id flag is present but empty because of '='", "cannot be empty when --submit is present", []string{"foo", "--submit", "--certification-project-id=", "--pyxis-api-token=footoken"}),
 			Entry("submit is passed after empty api token", "pyxis API token and certification ID are required when --submit is present", []string{"foo", "--certification-project-id=fooid", "--pyxis-api-token", "--submit"}),
 			Entry("submit is passed with explicit value after empty api token", "pyxis API token and certification ID are required when --submit is present", []string{"foo", "--certification-project-id=fooid", "--pyxis-api-token", "--submit=true"}),
+			Entry("submit is passed with a local image source", "images read from a local source cannot be submitted", []string{"oci:/tmp/layout", "--submit", "--certification-project-id=fooid", "--pyxis-api-token=footoken"}),
 			Entry("submit is passed and insecure is specified", "if any flags in the group [submit insecure] are set", []string{"foo", "--submit", "--insecure", "--certification-project-id=fooid", "--pyxis-api-token=footoken"}),
+			Entry("submit is passed and metadata-only is specified", "if any flags in the group [submit metadata-only] are set", []string{"foo", "--submit", "--metadata-only", "--certification-project-id=fooid", "--pyxis-api-token=footoken"}),
 		)
 
 		When("the user enables the submit flag", func() {
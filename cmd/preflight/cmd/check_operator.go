@@ -114,16 +114,14 @@ func checkOperatorRunE(cmd *cobra.Command, args []string, runpreflight runPrefli
 
 	opts := generateOperatorCheckOptions(cfg)
 
-	kubeconfig, err := func() ([]byte, error) {
-		kubeconfigFile, err := os.Open(cfg.Kubeconfig)
+	// A kubeconfig is not needed if the checks requiring a cluster were excluded,
+	// which is validated when the checks are resolved.
+	var kubeconfig []byte
+	if cfg.Kubeconfig != "" {
+		kubeconfig, err = readKubeconfig(cfg.Kubeconfig)
 		if err != nil {
-			return nil, fmt.Errorf("unable to open provided kubeconfig file: %s", err)
+			return err
 		}
-		defer kubeconfigFile.Close()
-		return io.ReadAll(kubeconfigFile)
-	}()
-	if err != nil {
-		return fmt.Errorf("unable to read provided kubeconfig file's contents: %s", err)
 	}
 
 	checkoperator := operator.NewCheck(operatorImage, cfg.IndexImage, kubeconfig, opts...)
@@ -147,6 +145,13 @@ func checkOperatorPositionalArgs(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("an operator bundle image positional argument is required")
 	}
 
+	// With a check selection, whether a cluster is needed depends on the
+	// selected checks, so it is left to the operator check to decide.
+	v := viper.Instance()
+	if len(v.GetStringSlice("include_checks")) > 0 || len(v.GetStringSlice("exclude_checks")) > 0 {
+		return nil
+	}
+
 	if err := ensureKubeconfigIsSet(); err != nil {
 		return err
 	}
@@ -158,6 +163,21 @@ func checkOperatorPositionalArgs(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// readKubeconfig returns the contents of the kubeconfig file at path.
+func readKubeconfig(path string) ([]byte, error) {
+	kubeconfigFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open provided kubeconfig file: %s", err)
+	}
+	defer kubeconfigFile.Close()
+
+	kubeconfig, err := io.ReadAll(kubeconfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read provided kubeconfig file's contents: %s", err)
+	}
+	return kubeconfig, nil
+}
+
 // generateOperatorCheckOptions returns options to be used with OperatorCheck based on cfg.
 func generateOperatorCheckOptions(cfg *runtime.Config) []operator.Option {
 	opts := []operator.Option{
@@ -166,6 +186,9 @@ func generateOperatorCheckOptions(cfg *runtime.Config) []operator.Option {
 		operator.WithScorecardImage(cfg.ScorecardImage),
 		operator.WithScorecardServiceAccount(cfg.ServiceAccount),
 		operator.WithScorecardNamespace(cfg.Namespace),
+		operator.WithParallelism(cfg.Parallelism),
+		operator.WithExtractionLimits(cfg.ExtractionLimits.MaxSize, cfg.ExtractionLimits.MaxFileSize, cfg.ExtractionLimits.MaxEntries),
+		operator.WithCheckTimeouts(cfg.CheckTimeouts.Default, cfg.CheckTimeouts.Overrides),
 	}
 
 	if cfg.ScorecardWaitTime != "" {
@@ -188,6 +211,14 @@ func generateOperatorCheckOptions(cfg *runtime.Config) []operator.Option {
 		opts = append(opts, operator.WithSubscriptionTimeout(cfg.SubscriptionTimeout))
 	}
 
+	if cfg.CacheDir != "" {
+		opts = append(opts, operator.WithLayerCache(cfg.CacheDir, cfg.CacheMaxSize))
+	}
+
+	if !cfg.CheckSelection.IsZero() {
+		opts = append(opts, operator.WithCheckSelection(cfg.CheckSelection.Include, cfg.CheckSelection.Exclude, cfg.CheckSelection.Levels))
+	}
+
 	return opts
 }
 
@@ -5,4 +5,5 @@ var (
 	DefaultLogLevel       = "info"
 	DefaultNamespace      = "default"
 	DefaultServiceAccount = "default"
+	DefaultCacheMaxSize   = "10Gi"
 )
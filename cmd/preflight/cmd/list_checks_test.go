@@ -48,6 +48,13 @@ var _ = Describe("list checks subcommand", func() {
 			Expect(buf.String()).To(ContainSubstring(expected))
 		})
 
+		It("should list the optional container checks", func() {
+			buf := strings.Builder{}
+			printChecks(&buf)
+
+			Expect(buf.String()).To(ContainSubstring(formatList(engine.OptionalContainerChecks)))
+		})
+
 		It("should always contain the operator policy", func() {
 			expected := formatList(engine.OperatorPolicy(context.TODO()))
 			buf := strings.Builder{}
@@ -45,6 +45,14 @@ func rootCmd() *cobra.Command {
 	rootCmd.PersistentFlags().String("loglevel", "", "The verbosity of the preflight tool itself. Ex. warn, debug, trace, info, error. (env: PFLT_LOGLEVEL)")
 	_ = viper.BindPFlag("loglevel", rootCmd.PersistentFlags().Lookup("loglevel"))
 
+	rootCmd.PersistentFlags().String("cache-dir", "", "A directory in which to persist image layers across runs. Disabled if empty. (env: PFLT_CACHE_DIR)")
+	_ = viper.BindPFlag("cache_dir", rootCmd.PersistentFlags().Lookup("cache-dir"))
+
+	rootCmd.PersistentFlags().String("cache-max-size", "", "The size the layer cache is pruned to, e.g. 500Mi or 10Gi. 0 disables pruning after a run. (env: PFLT_CACHE_MAX_SIZE)")
+	_ = viper.BindPFlag("cache_max_size", rootCmd.PersistentFlags().Lookup("cache-max-size"))
+
+	rootCmd.AddCommand(bundleCmd())
+	rootCmd.AddCommand(cacheCmd())
 	rootCmd.AddCommand(checkCmd())
 	rootCmd.AddCommand(listChecksCmd())
 	rootCmd.AddCommand(runtimeAssetsCmd())
@@ -95,6 +103,15 @@ func initConfig(viper *spfviper.Viper) {
 
 	// Set up subscription timeout default
 	viper.SetDefault("subscription_timeout", runtime.DefaultSubscriptionTimeout)
+
+	// Set up layer cache defaults
+	viper.SetDefault("cache_max_size", DefaultCacheMaxSize)
+
+	// Set up check parallelism default
+	viper.SetDefault("parallelism", runtime.DefaultParallelism)
+
+	// Set up check timeout default
+	viper.SetDefault("check_timeout", runtime.DefaultCheckTimeout)
 }
 
 // preRunConfig is used by cobra.PreRun in all non-root commands to load all necessary configurations
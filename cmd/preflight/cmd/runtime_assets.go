@@ -5,8 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"strings"
+	"time"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/basecatalog"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 
 	"github.com/spf13/cobra"
 )
@@ -19,9 +26,102 @@ func runtimeAssetsCmd() *cobra.Command {
 		RunE:  runtimeAssetsRunE,
 	}
 
+	runtimeAssetsCmd.AddCommand(baseCatalogCmd())
+
 	return runtimeAssetsCmd
 }
 
+func baseCatalogCmd() *cobra.Command {
+	baseCatalogCmd := &cobra.Command{
+		Use:   "base-catalog",
+		Short: "Manage the catalog of approved base images used in disconnected environments",
+		Long: "This command will manage the catalog of approved base images and their uncompressed top layer IDs. " +
+			"Pass the catalog to check container with --base-image-catalog (env: PFLT_BASE_IMAGE_CATALOG) " +
+			"to verify the image's base without querying Pyxis.",
+	}
+
+	baseCatalogCmd.AddCommand(baseCatalogExportCmd())
+
+	return baseCatalogCmd
+}
+
+func baseCatalogExportCmd() *cobra.Command {
+	var (
+		output       string
+		repositories []string
+		pyxisEnv     string
+		pyxisHost    string
+	)
+	baseCatalogExportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the certified base images from Pyxis",
+		Long: fmt.Sprintf("This command will list the certified images of %s in Pyxis, and write them to a catalog "+
+			"that the BasedOnUbi check can use offline. It must be run with access to Pyxis. "+
+			"Listing every repository may take several minutes.", basecatalog.DefaultRegistry),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if pyxisHost == "" {
+				pyxisHost = viper.Instance().GetString("pyxis_host")
+			}
+			host := runtime.PyxisHostLookup(pyxisEnv, pyxisHost)
+			source := pyxis.NewPyxisClient(host, "", "", &http.Client{Timeout: 60 * time.Second})
+			return exportBaseCatalog(cmd.Context(), cmd.OutOrStdout(), source, host, repositories, output)
+		},
+	}
+
+	flags := baseCatalogExportCmd.Flags()
+	flags.StringVarP(&output, "output", "o", "", "File to write the catalog to, as YAML if it ends in .yaml or .yml, and as JSON otherwise.\n"+
+		"Defaults to printing JSON to stdout.")
+	flags.StringSliceVar(&repositories, "repository", nil, "Only export the images of these repositories, e.g. ubi9/ubi,ubi9/ubi-minimal.\n"+
+		"Defaults to every repository, which matches what the BasedOnUbi check accepts when querying Pyxis.")
+	flags.StringVar(&pyxisEnv, "pyxis-env", check.DefaultPyxisEnv, "Env of the Pyxis to export the images from.")
+	flags.StringVar(&pyxisHost, "pyxis-host", "", "Host of the Pyxis to export the images from. This will override Pyxis Env. (env: PFLT_PYXIS_HOST)")
+
+	return baseCatalogExportCmd
+}
+
+// exportBaseCatalog builds the catalog of approved base images from the
+// certified images source lists, and writes it to output, or w if output is
+// empty. host is recorded as the catalog's source.
+func exportBaseCatalog(ctx context.Context, w io.Writer, source baseImageSource, host string, repositories []string, output string) error {
+	catalog, err := basecatalog.Export(ctx, source, basecatalog.DefaultRegistry, splitRepositories(repositories))
+	if err != nil {
+		return err
+	}
+	catalog.Source = host
+
+	if output == "" {
+		contents, err := catalog.Marshal(false)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(contents)
+		return err
+	}
+
+	if err := catalog.WriteFile(output); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Wrote %d base images to %s\n", len(catalog.Images), output)
+	return nil
+}
+
+// baseImageSource lists the certified images of a registry.
+type baseImageSource interface {
+	CertifiedImagesInRegistry(ctx context.Context, registry string, repositories []string) ([]pyxis.CertImage, error)
+}
+
+// splitRepositories trims the repositories and drops empty values.
+func splitRepositories(repositories []string) []string {
+	result := make([]string, 0, len(repositories))
+	for _, repository := range repositories {
+		if repository = strings.TrimSpace(repository); repository != "" {
+			result = append(result, repository)
+		}
+	}
+	return result
+}
+
 func runtimeAssetsRunE(cmd *cobra.Command, args []string) error {
 	if err := printAssets(cmd.Context(), cmd.OutOrStdout()); err != nil {
 		return err
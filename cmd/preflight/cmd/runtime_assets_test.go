@@ -4,7 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/basecatalog"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -62,4 +67,64 @@ var _ = Describe("runtime-assets test", func() {
 			Expect(printed).To(BeEquivalentTo(actual))
 		})
 	})
+
+	Context("When exporting the base image catalog", func() {
+		var source *fakeBaseImageSource
+
+		BeforeEach(func() {
+			source = &fakeBaseImageSource{images: []pyxis.CertImage{{
+				ID:                     "ubi9",
+				Architecture:           "amd64",
+				UncompressedTopLayerID: "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+				Repositories: []pyxis.Repository{
+					{Registry: basecatalog.DefaultRegistry, Repository: "ubi9/ubi", Tags: []pyxis.Tag{{Name: "9.4"}}},
+				},
+			}}}
+		})
+
+		It("should print the catalog as JSON", func() {
+			buf := bytes.NewBuffer([]byte{})
+			Expect(exportBaseCatalog(context.TODO(), buf, source, "catalog.redhat.com/api/containers", nil, "")).To(Succeed())
+
+			catalog, err := basecatalog.Parse(buf.Bytes())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(catalog.Source).To(Equal("catalog.redhat.com/api/containers"))
+			Expect(catalog.Images).To(HaveLen(1))
+			Expect(catalog.Images[0].Repository).To(Equal("ubi9/ubi"))
+		})
+
+		It("should write the catalog to a file and pass the repositories", func() {
+			output := filepath.Join(GinkgoT().TempDir(), "catalog.yaml")
+			buf := bytes.NewBuffer([]byte{})
+			Expect(exportBaseCatalog(context.TODO(), buf, source, "catalog.redhat.com/api/containers", []string{" ubi9/ubi", ""}, output)).To(Succeed())
+			Expect(buf.String()).To(ContainSubstring("Wrote 1 base images to " + output))
+			Expect(source.repositories).To(Equal([]string{"ubi9/ubi"}))
+
+			catalog, err := basecatalog.Load(output)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(catalog.Images).To(HaveLen(1))
+		})
+
+		It("should return an error if the images cannot be listed", func() {
+			source.err = errors.New("pyxis is unavailable")
+			Expect(exportBaseCatalog(context.TODO(), io.Discard, source, "", nil, "")).To(MatchError(ContainSubstring("pyxis is unavailable")))
+		})
+
+		It("should be a subcommand of runtime-assets", func() {
+			cmd, _, err := runtimeAssetsCmd().Find([]string{"base-catalog", "export"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cmd.Flags().Lookup("repository")).ToNot(BeNil())
+		})
+	})
 })
+
+type fakeBaseImageSource struct {
+	images       []pyxis.CertImage
+	err          error
+	repositories []string
+}
+
+func (f *fakeBaseImageSource) CertifiedImagesInRegistry(ctx context.Context, registry string, repositories []string) ([]pyxis.CertImage, error) {
+	f.repositories = repositories
+	return f.images, f.err
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
 	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/events"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/engine"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
@@ -22,9 +23,11 @@ type Option = func(*containerCheck)
 // NewCheck is a check that runs preflight's Container Policy.
 func NewCheck(image string, opts ...Option) *containerCheck {
 	c := &containerCheck{
-		image:     image,
-		pyxisHost: check.DefaultPyxisHost,
-		platform:  goruntime.GOARCH,
+		image:            image,
+		pyxisHost:        check.DefaultPyxisHost,
+		platform:         goruntime.GOARCH,
+		extractionLimits: runtime.DefaultExtractionLimits,
+		checkTimeouts:    runtime.CheckTimeouts{Default: runtime.DefaultCheckTimeout},
 	}
 
 	for _, opt := range opts {
@@ -51,6 +54,15 @@ func (c *containerCheck) Run(ctx context.Context) (certification.Results, error)
 		Insecure:           c.insecure,
 		Platform:           c.platform,
 		ManifestListDigest: c.manifestListDigest,
+		Parallelism:        c.parallelism,
+		CacheDir:           c.cacheDir,
+		CacheMaxSize:       c.cacheMaxSize,
+		ExtractionLimits:   c.extractionLimits,
+		CheckTimeouts:      c.checkTimeouts,
+		SBOMFormats:        c.sbomFormats,
+	}
+	if c.eventHandler != nil {
+		ctx = events.ContextWithHandler(ctx, c.eventHandler)
 	}
 	eng, err := engine.New(ctx, c.checks, nil, cfg)
 	if err != nil {
@@ -61,7 +73,10 @@ func (c *containerCheck) Run(ctx context.Context) (certification.Results, error)
 		return certification.Results{}, err
 	}
 
-	return eng.Results(ctx), nil
+	results := eng.Results(ctx)
+	results.Partial = c.partial
+	results.Skipped = append(results.Skipped, c.skipped...)
+	return results, nil
 }
 
 func (c *containerCheck) resolve(ctx context.Context) error {
@@ -97,11 +112,49 @@ func (c *containerCheck) resolve(ctx context.Context) error {
 		PyxisAPIToken:          c.pyxisToken,
 		CertificationProjectID: c.certificationProjectID,
 		PyxisHost:              c.pyxisHost,
+		RequiredArchitectures:  c.requiredArchitectures,
+		ManifestListPlatforms:  c.manifestListPlatforms,
+		AllowedCapabilities:    c.allowedCapabilities,
+		EnforceSecretsCheck:    c.enforceSecretsCheck,
+		AdvisoryDB:             c.advisoryDB,
+		HardeningMinimum:       c.hardeningMinimum,
+		BaseImageCatalog:       c.baseImageCatalog,
+		Insecure:               c.insecure,
+		CacheDir:               c.cacheDir,
+		MetadataOnly:           c.metadataOnly,
+		FIPSCheck:              c.fipsCheck,
+		EnabledChecks:          c.enabledChecks,
 	})
 	if err != nil {
 		return fmt.Errorf("%w: %s", preflighterr.ErrCannotInitializeChecks, err)
 	}
+	c.skipped = nil
+	if c.policy != policy.PolicyContainer {
+		// Record the checks the policy exception does not apply.
+		defaultChecks, err := engine.InitializeContainerChecks(ctx, policy.PolicyContainer, engine.ContainerCheckConfig{})
+		if err != nil {
+			return fmt.Errorf("%w: %s", preflighterr.ErrCannotInitializeChecks, err)
+		}
+		c.skip(check.Unselected(defaultChecks, newChecks), certification.SkipReasonPolicyException,
+			fmt.Sprintf("not part of the %s policy", c.policy))
+	}
+	policyChecks := len(newChecks)
+	if !c.selection.IsZero() {
+		selected, err := c.selection.Apply(newChecks)
+		if err != nil {
+			return fmt.Errorf("%w: %s", preflighterr.ErrCannotInitializeChecks, err)
+		}
+		c.skip(check.Unselected(newChecks, selected), certification.SkipReasonUserExclusion, "excluded by the check selection")
+		newChecks = selected
+	}
+	if c.metadataOnly {
+		filtered := metadataOnlyChecks(newChecks)
+		c.skip(check.Unselected(newChecks, filtered), certification.SkipReasonMissingPrerequisite,
+			"requires the image filesystem, which is not downloaded in a metadata-only run")
+		newChecks = filtered
+	}
 	c.checks = newChecks
+	c.partial = len(newChecks) != policyChecks || len(check.LevelOverrides(newChecks)) > 0
 	c.resolved = true
 
 	return nil
@@ -113,6 +166,26 @@ func (c *containerCheck) List(ctx context.Context) (policy.Policy, []check.Check
 	return c.policy, c.checks, c.resolve(ctx)
 }
 
+// skip records checks as skipped for reason, explained by message.
+func (c *containerCheck) skip(checks []check.Check, reason, message string) {
+	for _, chk := range checks {
+		c.skipped = append(c.skipped, certification.Result{Check: chk, SkipReason: reason, SkipMessage: message})
+	}
+}
+
+// metadataOnlyChecks returns the checks that need neither the image's
+// filesystem nor a cluster.
+func metadataOnlyChecks(checks []check.Check) []check.Check {
+	filtered := make([]check.Check, 0, len(checks))
+	for _, c := range checks {
+		requirements := check.RequirementsOf(c)
+		if requirements&(check.RequiresFilesystem|check.RequiresCluster) == 0 {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
 // hasPyxisData returns true of the values necessary to make a pyxis
 // API call are not empty. This does not check the validity of the input values.
 func (c *containerCheck) hasPyxisData() bool {
@@ -179,6 +252,184 @@ func WithManifestListDigest(manifestListDigest string) Option {
 	}
 }
 
+// WithManifestListPlatforms declares the platforms of the image's manifest list
+// that are checked in the same run, each with its own Check, e.g. amd64 and
+// s390x. The HasConsistentArchitectures check then compares the manifest list
+// only when checking the first of them. By default, every Check compares it.
+func WithManifestListPlatforms(platforms ...string) Option {
+	return func(cc *containerCheck) {
+		cc.manifestListPlatforms = platforms
+	}
+}
+
+// WithParallelism sets the maximum number of checks that may run concurrently.
+// Values less than 1 are treated as 1, running checks one at a time.
+func WithParallelism(n int) Option {
+	return func(cc *containerCheck) {
+		cc.parallelism = n
+	}
+}
+
+// WithLayerCache stores image layers in the persistent cache at dir, so they
+// can be reused by later runs. The cache is pruned to maxSize bytes after the
+// check has run, if no other process is using it. A maxSize of zero or less
+// leaves the cache unbounded.
+func WithLayerCache(dir string, maxSize int64) Option {
+	return func(cc *containerCheck) {
+		cc.cacheDir = dir
+		cc.cacheMaxSize = maxSize
+	}
+}
+
+// WithExtractionLimits bounds how much of the image is extracted to disk:
+// the total number of bytes, the size of any single file, and the number of
+// archive entries. Exceeding a limit returns an error matching
+// errors.ErrExtractionLimitExceeded. Zero or negative values use preflight's
+// defaults.
+func WithExtractionLimits(maxSize, maxFileSize int64, maxEntries int) Option {
+	return func(cc *containerCheck) {
+		cc.extractionLimits = runtime.ExtractionLimits{
+			MaxSize:     maxSize,
+			MaxFileSize: maxFileSize,
+			MaxEntries:  maxEntries,
+		}.WithDefaults()
+	}
+}
+
+// WithCheckTimeouts cancels any check running for longer than its timeout: the
+// timeout in overrides for its name, matched case-insensitively, or def. A zero
+// timeout disables cancellation. Cancelled checks are recorded in the TimedOut
+// results, and the remaining checks keep running. The default timeout is 30 minutes.
+func WithCheckTimeouts(def time.Duration, overrides map[string]time.Duration) Option {
+	return func(cc *containerCheck) {
+		cc.checkTimeouts = runtime.CheckTimeouts{
+			Default:   def,
+			Overrides: overrides,
+		}
+	}
+}
+
+// WithCheckSelection runs only the checks of the policy named in include, or all
+// of them if include is empty, except those named in exclude. The level of any
+// check named in levels is overridden, e.g. to "warn". Results of a run whose
+// checks differ from the policy are marked as partial, and cannot be submitted.
+func WithCheckSelection(include, exclude []string, levels map[string]string) Option {
+	return func(cc *containerCheck) {
+		cc.selection = check.Selection{
+			Include: include,
+			Exclude: exclude,
+			Levels:  levels,
+		}
+	}
+}
+
+// WithMetadataOnly runs only the checks that can be evaluated from the image's
+// manifest and config, e.g. RunAsNonRoot and HasRequiredLabels. The image's
+// layers are not downloaded, so this is much faster for large images. Results
+// are not suitable for submission, as the checks requiring the image's
+// filesystem are not run.
+func WithMetadataOnly() Option {
+	return func(cc *containerCheck) {
+		cc.metadataOnly = true
+	}
+}
+
+// WithEventHandler calls h with the events of the run as it progresses: the
+// image being pulled and extracted, checks starting and finishing, and artifacts
+// being written. Use events.Channel to receive events on a channel instead.
+func WithEventHandler(h events.Handler) Option {
+	return func(cc *containerCheck) {
+		cc.eventHandler = h
+	}
+}
+
+// WithRequiredArchitectures requires the manifest list of the image to contain
+// an image for each of architectures, e.g. amd64 and s390x. Missing architectures
+// are reported by the HasConsistentArchitectures check, which this enables.
+func WithRequiredArchitectures(architectures ...string) Option {
+	return func(cc *containerCheck) {
+		cc.requiredArchitectures = architectures
+	}
+}
+
+// WithAllowedCapabilities sets the file capabilities executables in the image
+// may carry, e.g. cap_net_bind_service. Executables carrying other capabilities
+// fail the HasNoUnexpectedFileCapabilities check, which this enables. By
+// default, only cap_net_bind_service is allowed.
+func WithAllowedCapabilities(capabilities ...string) Option {
+	return func(cc *containerCheck) {
+		cc.allowedCapabilities = capabilities
+	}
+}
+
+// WithEnforcedSecretsCheck runs the HasNoEmbeddedSecrets check, and fails the
+// certification if it finds secrets in the image. When the check is enabled
+// with WithEnabledChecks instead, they are reported as a warning.
+func WithEnforcedSecretsCheck() Option {
+	return func(cc *containerCheck) {
+		cc.enforceSecretsCheck = true
+	}
+}
+
+// WithEnabledChecks adds the named optional checks, which are not part of any
+// policy by default, to the policy, e.g. HasConsistentArchitectures. Names are
+// not case-sensitive, and unknown names fail the run.
+func WithEnabledChecks(names ...string) Option {
+	return func(cc *containerCheck) {
+		cc.enabledChecks = names
+	}
+}
+
+// WithFIPSCheck adds the SupportsFIPSMode check, which evaluates whether the
+// crypto used by the image's binaries can run in FIPS mode, to back a claim
+// that the image is FIPS compliant. By default, the check is not run.
+func WithFIPSCheck() Option {
+	return func(cc *containerCheck) {
+		cc.fipsCheck = true
+	}
+}
+
+// WithAdvisoryDB matches the image's rpms and Go modules against the
+// advisories in path, an OSV or CSAF JSON file or a directory of them, and
+// reports known vulnerabilities in the HasNoKnownVulnerabilities check, which
+// this enables. By default, that check is not run.
+func WithAdvisoryDB(path string) Option {
+	return func(cc *containerCheck) {
+		cc.advisoryDB = path
+	}
+}
+
+// WithBinaryHardeningMinimum sets the hardening properties the executables and
+// shared objects added to the image outside of rpms must have: pie, relro, nx,
+// stack-protector or fortify. Binaries lacking any fail the
+// HasHardenedBinaries check, which this enables. By default, all but fortify
+// are required, and none only reports the binaries.
+func WithBinaryHardeningMinimum(properties ...string) Option {
+	return func(cc *containerCheck) {
+		cc.hardeningMinimum = properties
+	}
+}
+
+// WithBaseImageCatalog looks the image's layers up in the catalog of approved
+// base images at path, a JSON or YAML file exported with preflight
+// runtime-assets base-catalog export, in the BasedOnUbi check. By default,
+// they are looked up in Pyxis, which is not reachable from disconnected
+// environments.
+func WithBaseImageCatalog(path string) Option {
+	return func(cc *containerCheck) {
+		cc.baseImageCatalog = path
+	}
+}
+
+// WithSBOMFormats writes the image's software bill of materials to the
+// artifacts in each of formats, spdx or cyclonedx. By default, no SBOM is
+// written. An unknown format makes Run return an error.
+func WithSBOMFormats(formats ...string) Option {
+	return func(cc *containerCheck) {
+		cc.sbomFormats = formats
+	}
+}
+
 type containerCheck struct {
 	image                  string
 	dockerconfigjson       string
@@ -188,6 +439,26 @@ type containerCheck struct {
 	platform               string
 	insecure               bool
 	manifestListDigest     string
+	manifestListPlatforms  []string
+	parallelism            int
+	cacheDir               string
+	cacheMaxSize           int64
+	extractionLimits       runtime.ExtractionLimits
+	checkTimeouts          runtime.CheckTimeouts
+	selection              check.Selection
+	metadataOnly           bool
+	requiredArchitectures  []string
+	allowedCapabilities    []string
+	enforceSecretsCheck    bool
+	fipsCheck              bool
+	enabledChecks          []string
+	sbomFormats            []string
+	advisoryDB             string
+	hardeningMinimum       []string
+	baseImageCatalog       string
+	partial                bool
+	skipped                []certification.Result
+	eventHandler           events.Handler
 	checks                 []check.Check
 	resolved               bool
 	policy                 policy.Policy
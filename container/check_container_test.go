@@ -92,6 +92,70 @@ var _ = Describe("Container Check Execution", func() {
 		})
 	})
 
+	When("only image metadata should be checked", func() {
+		It("should resolve only the checks that do not need the image filesystem", func() {
+			chk := NewCheck("placeholder", WithMetadataOnly())
+			Expect(chk.resolve(context.TODO())).To(Succeed())
+
+			names := make([]string, 0, len(chk.checks))
+			for _, c := range chk.checks {
+				names = append(names, c.Name())
+			}
+			Expect(names).To(ConsistOf("HasUniqueTag", "LayerCountAcceptable", "HasRequiredLabel", "RunAsNonRoot", "BasedOnUbi"))
+			Expect(chk.skipped).To(HaveLen(3))
+			for _, skipped := range chk.skipped {
+				Expect(skipped.SkipReason).To(Equal(certification.SkipReasonMissingPrerequisite))
+			}
+		})
+	})
+
+	When("a check selection is provided", func() {
+		It("should resolve only the selected checks and mark the results as partial", func() {
+			chk := NewCheck("placeholder", WithCheckSelection([]string{"haslicense", "RunAsNonRoot"}, nil, map[string]string{"runasnonroot": "warn"}))
+			Expect(chk.resolve(context.TODO())).To(Succeed())
+			Expect(chk.checks).To(HaveLen(2))
+			Expect(chk.checks[1].Metadata().Level).To(Equal("warn"))
+			Expect(chk.partial).To(BeTrue())
+			Expect(chk.skipped).To(HaveLen(6))
+			Expect(chk.skipped[0].SkipReason).To(Equal(certification.SkipReasonUserExclusion))
+		})
+
+		It("should fail to resolve if a check is unknown", func() {
+			chk := NewCheck("placeholder", WithCheckSelection(nil, []string{"NotACheck"}, nil))
+			Expect(chk.resolve(context.TODO())).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+		})
+	})
+
+	When("optional checks are enabled", func() {
+		It("should add them to the policy", func() {
+			chk := NewCheck("placeholder", WithEnabledChecks("HasConsistentArchitectures"))
+			Expect(chk.resolve(context.TODO())).To(Succeed())
+			Expect(chk.checks).To(HaveLen(9))
+			Expect(chk.partial).To(BeFalse())
+		})
+
+		It("should fail to resolve if an optional check is unknown", func() {
+			chk := NewCheck("placeholder", WithEnabledChecks("NotACheck"))
+			Expect(chk.resolve(context.TODO())).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+		})
+	})
+
+	When("the FIPS check is enabled", func() {
+		It("should add it to the policy", func() {
+			chk := NewCheck("placeholder", WithFIPSCheck())
+			Expect(chk.resolve(context.TODO())).To(Succeed())
+			Expect(chk.checks).To(HaveLen(9))
+			Expect(chk.checks[8].Name()).To(Equal("SupportsFIPSMode"))
+		})
+	})
+
+	When("the binary hardening minimum names an unknown property", func() {
+		It("should fail to resolve before running any check", func() {
+			chk := NewCheck("placeholder", WithBinaryHardeningMinimum("pie", "aslr"))
+			Expect(chk.resolve(context.TODO())).To(MatchError(preflighterr.ErrCannotInitializeChecks))
+		})
+	})
+
 	When("Calling the check", func() {
 		It("should fail if you passed an empty image", func() {
 			chk := NewCheck("")
@@ -1,6 +1,9 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Library-wide error messages are here.
 var (
@@ -9,4 +12,26 @@ var (
 	ErrImageEmpty                   = errors.New("image is empty")
 	ErrCannotResolvePolicyException = errors.New("cannot resolve policy exception")
 	ErrCannotInitializeChecks       = errors.New("unable to initialize checks")
+	ErrExtractionLimitExceeded      = errors.New("image extraction limit exceeded")
+	ErrPartialResultsNotSubmittable = errors.New("results of a partial run cannot be submitted")
 )
+
+// ExtractionLimitError is returned when extracting an image's filesystem would
+// exceed one of the configured limits. It matches ErrExtractionLimitExceeded
+// with errors.Is.
+type ExtractionLimitError struct {
+	// Limit names the limit that was exceeded, e.g. "total size".
+	Limit string
+	// Max is the configured value of the limit.
+	Max int64
+	// Entry is the archive entry being extracted when the limit was exceeded.
+	Entry string
+}
+
+func (e *ExtractionLimitError) Error() string {
+	return fmt.Sprintf("%s: %s of %d exceeded while extracting %s", ErrExtractionLimitExceeded, e.Limit, e.Max, e.Entry)
+}
+
+func (e *ExtractionLimitError) Unwrap() error {
+	return ErrExtractionLimitExceeded
+}
@@ -0,0 +1,130 @@
+// Package events reports the progress of a preflight run as it happens. Library
+// callers can observe a run by passing a Handler to the WithEventHandler option
+// of the container and operator checks.
+package events
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+)
+
+// Event is one of the event types in this package.
+type Event interface {
+	isEvent()
+}
+
+// PullStarted is emitted before the image's manifest is fetched.
+type PullStarted struct {
+	Image string
+}
+
+// PullFinished is emitted once the image's manifest has been fetched. Layers
+// are only downloaded, while being extracted, if a check reads the image's
+// filesystem.
+type PullFinished struct {
+	Image  string
+	Digest string
+	// Size is the compressed size of the image's layers in bytes.
+	Size int64
+}
+
+// ExtractionProgress is emitted periodically while the image's filesystem is
+// extracted, and once more with Done set when extraction completes.
+type ExtractionProgress struct {
+	// Entries is the number of archive entries processed so far.
+	Entries int
+	// Bytes is the number of bytes of file contents extracted so far.
+	Bytes int64
+	Done  bool
+}
+
+// CheckStarted is emitted when a check starts running.
+type CheckStarted struct {
+	Name string
+}
+
+// CheckFinished is emitted when a check completes. Status is one of PASSED,
+// FAILED, WARNING, ERROR, TIMED OUT or SKIPPED, matching the bucket the
+// result is reported in.
+type CheckFinished struct {
+	Name   string
+	Status string
+	Result certification.Result
+}
+
+// ArtifactWritten is emitted when an artifact file is written during the run.
+type ArtifactWritten struct {
+	// Path is the location of the file, as returned by the ArtifactWriter.
+	Path string
+}
+
+func (PullStarted) isEvent()        {}
+func (PullFinished) isEvent()       {}
+func (ExtractionProgress) isEvent() {}
+func (CheckStarted) isEvent()       {}
+func (CheckFinished) isEvent()      {}
+func (ArtifactWritten) isEvent()    {}
+
+// Handler receives events. Calls are serialized, so a Handler need not be safe
+// for concurrent use, but the run waits for it to return.
+type Handler func(Event)
+
+// Channel returns a Handler sending events to ch. Sends block, so ch must be
+// read until the run completes.
+func Channel(ch chan<- Event) Handler {
+	return func(e Event) {
+		ch <- e
+	}
+}
+
+type contextKey struct{}
+
+// ContextWithHandler adds h to ctx. Concurrent emits to h are serialized.
+func ContextWithHandler(ctx context.Context, h Handler) context.Context {
+	var mu sync.Mutex
+	return context.WithValue(ctx, contextKey{}, Handler(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		h(e)
+	}))
+}
+
+// Emit sends e to the Handler in ctx, if any.
+func Emit(ctx context.Context, e Event) {
+	if h := handlerFromContext(ctx); h != nil {
+		h(e)
+	}
+}
+
+func handlerFromContext(ctx context.Context) Handler {
+	h, _ := ctx.Value(contextKey{}).(Handler)
+	return h
+}
+
+// ContextWithArtifactEvents wraps the ArtifactWriter in ctx, if any, so that
+// ArtifactWritten is emitted to the Handler in ctx for every file written.
+func ContextWithArtifactEvents(ctx context.Context) context.Context {
+	h := handlerFromContext(ctx)
+	w := artifacts.WriterFromContext(ctx)
+	if h == nil || w == nil {
+		return ctx
+	}
+	return artifacts.ContextWithWriter(ctx, artifactWriter{ArtifactWriter: w, handler: h})
+}
+
+type artifactWriter struct {
+	artifacts.ArtifactWriter
+	handler Handler
+}
+
+func (w artifactWriter) WriteFile(filename string, contents io.Reader) (string, error) {
+	path, err := w.ArtifactWriter.WriteFile(filename, contents)
+	if err == nil {
+		w.handler(ArtifactWritten{Path: path})
+	}
+	return path, err
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Events", func() {
+	It("should do nothing without a handler", func() {
+		Emit(context.Background(), CheckStarted{Name: "check"})
+	})
+
+	It("should serialize calls to the handler", func() {
+		var received []Event
+		ctx := ContextWithHandler(context.Background(), func(e Event) {
+			received = append(received, e)
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				Emit(ctx, CheckStarted{Name: "check"})
+			}()
+		}
+		wg.Wait()
+		Expect(received).To(HaveLen(50))
+	})
+
+	It("should send events to a channel", func() {
+		ch := make(chan Event, 1)
+		ctx := ContextWithHandler(context.Background(), Channel(ch))
+		Emit(ctx, PullStarted{Image: "quay.io/example/image"})
+		Expect(ch).To(Receive(Equal(PullStarted{Image: "quay.io/example/image"})))
+	})
+
+	It("should report artifacts written", func() {
+		aw, err := artifacts.NewMapWriter()
+		Expect(err).ToNot(HaveOccurred())
+
+		ch := make(chan Event, 1)
+		ctx := ContextWithHandler(artifacts.ContextWithWriter(context.Background(), aw), Channel(ch))
+		ctx = ContextWithArtifactEvents(ctx)
+
+		_, err = artifacts.WriterFromContext(ctx).WriteFile("file.txt", bytes.NewBufferString("contents"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ch).To(Receive(Equal(ArtifactWritten{Path: "file.txt"})))
+		Expect(aw.Files()).To(HaveKey("file.txt"))
+	})
+})
@@ -0,0 +1,249 @@
+// Package advisory matches the components of an image against a locally
+// supplied database of security advisories, in the OSV or CSAF format, so
+// that known vulnerabilities are found without network access.
+package advisory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/sbom"
+)
+
+// Advisory describes a vulnerability and the package versions it affects.
+type Advisory struct {
+	// ID identifies the advisory, e.g. CVE-2023-4911 or GO-2023-2102.
+	ID      string
+	Aliases []string
+	Summary string
+	// Severity is the severity assigned by the advisory's publisher, e.g.
+	// Important or HIGH, or empty if it has none.
+	Severity string
+	Affected []Affected
+}
+
+// Affected is a package affected by an advisory.
+type Affected struct {
+	// Type is the type of the package, sbom.TypeRPM or sbom.TypeGolang.
+	Type string
+	Name string
+	// Arch restricts an rpm to an architecture. If empty, all match.
+	Arch string
+	// Ranges are the ranges of affected versions.
+	Ranges []Range
+	// Versions are affected versions listed individually.
+	Versions []string
+}
+
+// Range is a range of affected versions. A version is affected if it is at
+// least Introduced, and below Fixed or at most LastAffected. An empty
+// Introduced affects all versions up to the end of the range.
+type Range struct {
+	Introduced   string
+	Fixed        string
+	LastAffected string
+}
+
+// Match is a component affected by an advisory.
+type Match struct {
+	Advisory  *Advisory
+	Component sbom.Component
+	// FixedVersion is the earliest version that is not affected, or empty if
+	// no fix is known.
+	FixedVersion string
+}
+
+// Database is a set of advisories.
+type Database struct {
+	Advisories []*Advisory
+}
+
+// Load reads the advisories at p, which is a JSON file or a directory of them.
+// Each file may hold an OSV advisory, a list of OSV advisories, or a CSAF
+// document.
+func Load(p string) (*Database, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, fmt.Errorf("could not read advisory database: %w", err)
+	}
+
+	files := []string{p}
+	if info.IsDir() {
+		files = nil
+		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(d.Name(), ".json") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not read advisory database: %w", err)
+		}
+	}
+
+	db := &Database{}
+	for _, f := range files {
+		contents, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("could not read advisory file: %w", err)
+		}
+		advisories, err := Parse(contents)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse advisory file %s: %w", f, err)
+		}
+		db.Advisories = append(db.Advisories, advisories...)
+	}
+	return db, nil
+}
+
+// Parse reads the advisories in contents, which hold an OSV advisory, a list
+// of OSV advisories, or a CSAF document.
+func Parse(contents []byte) ([]*Advisory, error) {
+	contents = []byte(strings.TrimSpace(string(contents)))
+	if len(contents) > 0 && contents[0] == '[' {
+		var entries []osvEntry
+		if err := json.Unmarshal(contents, &entries); err != nil {
+			return nil, err
+		}
+		advisories := make([]*Advisory, 0, len(entries))
+		for _, e := range entries {
+			advisories = append(advisories, e.advisory())
+		}
+		return advisories, nil
+	}
+
+	var probe struct {
+		Document *json.RawMessage `json:"document"`
+		Affected *json.RawMessage `json:"affected"`
+	}
+	if err := json.Unmarshal(contents, &probe); err != nil {
+		return nil, err
+	}
+	switch {
+	case probe.Document != nil:
+		var doc csafDocument
+		if err := json.Unmarshal(contents, &doc); err != nil {
+			return nil, err
+		}
+		return doc.advisories(), nil
+	case probe.Affected != nil:
+		var entry osvEntry
+		if err := json.Unmarshal(contents, &entry); err != nil {
+			return nil, err
+		}
+		return []*Advisory{entry.advisory()}, nil
+	}
+	return nil, errors.New("not an OSV or CSAF document")
+}
+
+// Match returns the advisories affecting components. Each advisory is matched
+// at most once per component. Components other than rpms and Go modules are
+// ignored.
+func (db *Database) Match(components []sbom.Component) []Match {
+	var matches []Match
+	for _, c := range components {
+		if c.Type != sbom.TypeRPM && c.Type != sbom.TypeGolang {
+			continue
+		}
+		for _, a := range db.Advisories {
+			if fixed, ok := a.affects(c); ok {
+				matches = append(matches, Match{Advisory: a, Component: c, FixedVersion: fixed})
+			}
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Component.Name != matches[j].Component.Name {
+			return matches[i].Component.Name < matches[j].Component.Name
+		}
+		return matches[i].Advisory.ID < matches[j].Advisory.ID
+	})
+	return matches
+}
+
+// affects returns true if c is affected by a, along with the version fixing it.
+func (a *Advisory) affects(c sbom.Component) (string, bool) {
+	compare := compareGo
+	if c.Type == sbom.TypeRPM {
+		compare = compareRPM
+	}
+	arch := purlQualifier(c.PURL, "arch")
+
+	for _, affected := range a.Affected {
+		if affected.Type != c.Type || affected.Name != c.Name {
+			continue
+		}
+		if affected.Arch != "" && arch != "" && affected.Arch != arch {
+			continue
+		}
+		for _, v := range affected.Versions {
+			if cmp, err := compare(c.Version, v); err == nil && cmp == 0 {
+				return "", true
+			}
+		}
+		for _, r := range affected.Ranges {
+			if c.Type == sbom.TypeRPM && !sameDistribution(c.Version, r) {
+				continue
+			}
+			if affectedBy(c.Version, r, compare) {
+				return r.Fixed, true
+			}
+		}
+	}
+	return "", false
+}
+
+// affectedBy returns true if version is in r. Versions that cannot be
+// compared are not affected.
+func affectedBy(version string, r Range, compare func(a, b string) (int, error)) bool {
+	if r.Introduced != "" && r.Introduced != "0" {
+		if cmp, err := compare(version, r.Introduced); err != nil || cmp < 0 {
+			return false
+		}
+	}
+	if r.Fixed != "" {
+		cmp, err := compare(version, r.Fixed)
+		return err == nil && cmp < 0
+	}
+	if r.LastAffected != "" {
+		cmp, err := compare(version, r.LastAffected)
+		return err == nil && cmp <= 0
+	}
+	return true
+}
+
+// distributionRelease matches the major release of RHEL in an rpm release,
+// e.g. 9 in 6.el9_2.
+var distributionRelease = regexp.MustCompile(`\.el(\d+)`)
+
+// sameDistribution returns false if the rpm version and the fix in r were
+// built for different major releases of RHEL, as fixes for one release do
+// not apply to the others.
+func sameDistribution(version string, r Range) bool {
+	installed := distributionRelease.FindStringSubmatch(version)
+	fixed := distributionRelease.FindStringSubmatch(r.Fixed)
+	return installed == nil || fixed == nil || installed[1] == fixed[1]
+}
+
+// purlQualifier returns the value of the qualifier called name in purl.
+func purlQualifier(purl, name string) string {
+	_, qualifiers, ok := strings.Cut(purl, "?")
+	if !ok {
+		return ""
+	}
+	for _, q := range strings.Split(qualifiers, "&") {
+		if k, v, ok := strings.Cut(q, "="); ok && k == name {
+			return v
+		}
+	}
+	return ""
+}
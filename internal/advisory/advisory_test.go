@@ -0,0 +1,216 @@
+package advisory
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/sbom"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const osvGo = `{
+	"id": "GO-2023-2102",
+	"aliases": ["CVE-2023-39325", "GHSA-4374-p667-p6c8"],
+	"summary": "HTTP/2 rapid reset can cause excessive work in net/http",
+	"affected": [
+		{
+			"package": {"ecosystem": "Go", "name": "stdlib"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.20.10"}, {"introduced": "1.21.0-0"}, {"fixed": "1.21.3"}]}]
+		},
+		{
+			"package": {"ecosystem": "Go", "name": "golang.org/x/net"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.17.0"}]}],
+			"database_specific": {"severity": "HIGH"}
+		},
+		{
+			"package": {"ecosystem": "npm", "name": "http2"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]
+		}
+	]
+}`
+
+const osvRPM = `[{
+	"id": "RHSA-2023:5455",
+	"summary": "glibc security update",
+	"database_specific": {"severity": "Important"},
+	"affected": [{
+		"package": {"ecosystem": "Red Hat:enterprise_linux:9::baseos", "name": "glibc", "purl": "pkg:rpm/redhat/glibc"},
+		"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "0:2.34-60.el9_2.7"}]}]
+	}]
+}]`
+
+const csafVEX = `{
+	"document": {
+		"category": "csaf_vex",
+		"title": "glibc: buffer overflow in ld.so leading to privilege escalation",
+		"aggregate_severity": {"text": "Important"},
+		"tracking": {"id": "CVE-2023-4911"}
+	},
+	"product_tree": {
+		"branches": [{
+			"branches": [
+				{"product": {"product_id": "glibc-0:2.34-60.el9_2.7.x86_64", "product_identification_helper": {"purl": "pkg:rpm/redhat/glibc@2.34-60.el9_2.7?arch=x86_64"}}},
+				{"product": {"product_id": "glibc-0:2.34-60.el9_2.7.aarch64", "product_identification_helper": {"purl": "pkg:rpm/redhat/glibc@2.34-60.el9_2.7?arch=aarch64"}}},
+				{"product": {"product_id": "glibc-0:2.34-60.el9_2.7.src", "product_identification_helper": {"purl": "pkg:rpm/redhat/glibc@2.34-60.el9_2.7?arch=src"}}},
+				{"product": {"product_id": "glibc-0:2.28-225.el8_8.6.x86_64", "product_identification_helper": {"purl": "pkg:rpm/redhat/glibc@2.28-225.el8_8.6?arch=x86_64"}}}
+			]
+		}],
+		"relationships": [
+			{"product_reference": "glibc-0:2.34-60.el9_2.7.x86_64", "full_product_name": {"product_id": "BaseOS-9.2.0.Z.EUS:glibc-0:2.34-60.el9_2.7.x86_64"}},
+			{"product_reference": "glibc-0:2.34-60.el9_2.7.aarch64", "full_product_name": {"product_id": "BaseOS-9.2.0.Z.EUS:glibc-0:2.34-60.el9_2.7.aarch64"}},
+			{"product_reference": "glibc-0:2.34-60.el9_2.7.src", "full_product_name": {"product_id": "BaseOS-9.2.0.Z.EUS:glibc-0:2.34-60.el9_2.7.src"}},
+			{"product_reference": "glibc-0:2.28-225.el8_8.6.x86_64", "full_product_name": {"product_id": "BaseOS-8.8.0.Z.EUS:glibc-0:2.28-225.el8_8.6.x86_64"}}
+		]
+	},
+	"vulnerabilities": [{
+		"cve": "CVE-2023-4911",
+		"title": "glibc: buffer overflow in ld.so leading to privilege escalation",
+		"product_status": {"fixed": [
+			"BaseOS-9.2.0.Z.EUS:glibc-0:2.34-60.el9_2.7.x86_64",
+			"BaseOS-9.2.0.Z.EUS:glibc-0:2.34-60.el9_2.7.aarch64",
+			"BaseOS-9.2.0.Z.EUS:glibc-0:2.34-60.el9_2.7.src",
+			"BaseOS-8.8.0.Z.EUS:glibc-0:2.28-225.el8_8.6.x86_64"
+		]},
+		"threats": [{"category": "impact", "details": "Important"}]
+	}]
+}`
+
+var _ = Describe("Advisory matching", func() {
+	glibc := func(version, arch string) sbom.Component {
+		return sbom.Component{
+			Type:    sbom.TypeRPM,
+			Name:    "glibc",
+			Version: version,
+			PURL:    "pkg:rpm/redhat/glibc@" + version + "?arch=" + arch,
+		}
+	}
+	golang := func(name, version string) sbom.Component {
+		return sbom.Component{Type: sbom.TypeGolang, Name: name, Version: version}
+	}
+
+	Context("with OSV advisories", func() {
+		var db *Database
+
+		BeforeEach(func() {
+			goAdvisories, err := Parse([]byte(osvGo))
+			Expect(err).ToNot(HaveOccurred())
+			rpmAdvisories, err := Parse([]byte(osvRPM))
+			Expect(err).ToNot(HaveOccurred())
+			db = &Database{Advisories: append(goAdvisories, rpmAdvisories...)}
+		})
+
+		It("should only keep rpm and Go packages", func() {
+			Expect(db.Advisories[0].Affected).To(HaveLen(2))
+			Expect(db.Advisories[0].Affected[0].Ranges).To(Equal([]Range{
+				{Introduced: "0", Fixed: "1.20.10"},
+				{Introduced: "1.21.0-0", Fixed: "1.21.3"},
+			}))
+			Expect(db.Advisories[0].Severity).To(Equal("HIGH"))
+		})
+
+		It("should match affected Go modules and releases", func() {
+			matches := db.Match([]sbom.Component{
+				golang("stdlib", "go1.21.1"),
+				golang("golang.org/x/net", "v0.15.0"),
+				golang("golang.org/x/net", "v0.17.0"),
+				golang("golang.org/x/text", "v0.13.0"),
+				golang("github.com/example/app", "(devel)"),
+			})
+			Expect(matches).To(HaveLen(2))
+			Expect(matches[0].Component.Name).To(Equal("golang.org/x/net"))
+			Expect(matches[0].FixedVersion).To(Equal("0.17.0"))
+			Expect(matches[1].Component.Name).To(Equal("stdlib"))
+			Expect(matches[1].FixedVersion).To(Equal("1.21.3"))
+			Expect(matches[1].Advisory.ID).To(Equal("GO-2023-2102"))
+		})
+
+		It("should not match Go releases outside of the affected ranges", func() {
+			Expect(db.Match([]sbom.Component{golang("stdlib", "go1.20.10"), golang("stdlib", "go1.21.3")})).To(BeEmpty())
+		})
+
+		It("should match affected rpms", func() {
+			matches := db.Match([]sbom.Component{glibc("2.34-60.el9", "x86_64"), glibc("2.34-60.el9_2.7", "x86_64")})
+			Expect(matches).To(HaveLen(1))
+			Expect(matches[0].Component.Version).To(Equal("2.34-60.el9"))
+			Expect(matches[0].FixedVersion).To(Equal("0:2.34-60.el9_2.7"))
+			Expect(matches[0].Advisory.Severity).To(Equal("Important"))
+		})
+	})
+
+	Context("with a CSAF document", func() {
+		var db *Database
+
+		BeforeEach(func() {
+			advisories, err := Parse([]byte(csafVEX))
+			Expect(err).ToNot(HaveOccurred())
+			db = &Database{Advisories: advisories}
+		})
+
+		It("should read the fixed binary rpms", func() {
+			Expect(db.Advisories).To(HaveLen(1))
+			Expect(db.Advisories[0].ID).To(Equal("CVE-2023-4911"))
+			Expect(db.Advisories[0].Severity).To(Equal("Important"))
+			Expect(db.Advisories[0].Affected).To(ConsistOf(
+				Affected{Type: sbom.TypeRPM, Name: "glibc", Arch: "x86_64", Ranges: []Range{{Fixed: "2.34-60.el9_2.7"}}},
+				Affected{Type: sbom.TypeRPM, Name: "glibc", Arch: "aarch64", Ranges: []Range{{Fixed: "2.34-60.el9_2.7"}}},
+				Affected{Type: sbom.TypeRPM, Name: "glibc", Arch: "x86_64", Ranges: []Range{{Fixed: "2.28-225.el8_8.6"}}},
+			))
+		})
+
+		It("should match rpms older than the fix for their release", func() {
+			matches := db.Match([]sbom.Component{glibc("2.34-60.el9_2.3", "x86_64")})
+			Expect(matches).To(HaveLen(1))
+			Expect(matches[0].FixedVersion).To(Equal("2.34-60.el9_2.7"))
+		})
+
+		It("should not match fixes for another release or architecture", func() {
+			Expect(db.Match([]sbom.Component{glibc("2.34-60.el9_2.7", "x86_64")})).To(BeEmpty())
+			Expect(db.Match([]sbom.Component{glibc("2.34-60.el9_2.3", "s390x")})).To(BeEmpty())
+		})
+	})
+
+	Context("loading a database", func() {
+		It("should read every JSON file in a directory", func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.MkdirAll(filepath.Join(dir, "csaf"), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "go.json"), []byte(osvGo), 0o644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "csaf", "cve-2023-4911.json"), []byte(csafVEX), 0o644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "README"), []byte("not json"), 0o644)).To(Succeed())
+
+			db, err := Load(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(db.Advisories).To(HaveLen(2))
+		})
+		It("should reject files that are not advisories", func() {
+			p := filepath.Join(GinkgoT().TempDir(), "advisories.json")
+			Expect(os.WriteFile(p, []byte(`{"name": "something else"}`), 0o644)).To(Succeed())
+			_, err := Load(p)
+			Expect(err).To(MatchError(ContainSubstring("not an OSV or CSAF document")))
+		})
+		It("should fail if the database does not exist", func() {
+			_, err := Load(filepath.Join(GinkgoT().TempDir(), "missing"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = DescribeTable("Comparing rpm versions",
+	func(a, b string, expected int) {
+		cmp, err := compareRPM(a, b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cmp).To(Equal(expected))
+	},
+	Entry("equal", "1.0-1", "1.0-1", 0),
+	Entry("numeric segments", "1.10-1", "1.9-1", 1),
+	Entry("leading zeros", "1.01-1", "1.1-1", 0),
+	Entry("numeric newer than alphabetic", "1.0a-1", "1.0.1-1", -1),
+	Entry("longer version", "1.0.1-1", "1.0-1", 1),
+	Entry("release", "2.34-60.el9_2.7", "2.34-60.el9_2.3", 1),
+	Entry("epoch", "1:1.0-1", "2.0-1", 1),
+	Entry("explicit zero epoch", "0:2.0-1", "2.0-1", 0),
+	Entry("tilde sorts before release", "1.0~rc1-1", "1.0-1", -1),
+	Entry("caret sorts after release", "1.0^git1-1", "1.0-1", 1),
+	Entry("missing release", "1.0", "1.0-5", 0),
+)
@@ -0,0 +1,138 @@
+package advisory
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/sbom"
+)
+
+// csafDocument is a CSAF advisory or VEX document, as published by Red Hat for
+// each CVE and errata. Only the fixed rpms are read: products known to be
+// affected without a fix name no version, so they cannot be told apart from
+// the same package in another release. See
+// https://docs.oasis-open.org/csaf/csaf/v2.0/csaf-v2.0.html.
+type csafDocument struct {
+	Document struct {
+		Title             string `json:"title"`
+		AggregateSeverity struct {
+			Text string `json:"text"`
+		} `json:"aggregate_severity"`
+		Tracking struct {
+			ID string `json:"id"`
+		} `json:"tracking"`
+	} `json:"document"`
+	ProductTree struct {
+		Branches      []csafBranch `json:"branches"`
+		Relationships []struct {
+			ProductReference string `json:"product_reference"`
+			FullProductName  struct {
+				ProductID string `json:"product_id"`
+			} `json:"full_product_name"`
+		} `json:"relationships"`
+	} `json:"product_tree"`
+	Vulnerabilities []struct {
+		CVE           string `json:"cve"`
+		Title         string `json:"title"`
+		ProductStatus struct {
+			Fixed []string `json:"fixed"`
+		} `json:"product_status"`
+		Threats []struct {
+			Category string `json:"category"`
+			Details  string `json:"details"`
+		} `json:"threats"`
+	} `json:"vulnerabilities"`
+}
+
+type csafBranch struct {
+	Branches []csafBranch `json:"branches"`
+	Product  *struct {
+		ProductID                   string `json:"product_id"`
+		ProductIdentificationHelper struct {
+			PURL string `json:"purl"`
+		} `json:"product_identification_helper"`
+	} `json:"product"`
+}
+
+// advisories converts each vulnerability in doc into an advisory affecting
+// the rpms it lists as fixed, in versions below the fix.
+func (doc csafDocument) advisories() []*Advisory {
+	purls := map[string]string{}
+	var collect func(branches []csafBranch)
+	collect = func(branches []csafBranch) {
+		for _, b := range branches {
+			if b.Product != nil && b.Product.ProductIdentificationHelper.PURL != "" {
+				purls[b.Product.ProductID] = b.Product.ProductIdentificationHelper.PURL
+			}
+			collect(b.Branches)
+		}
+	}
+	collect(doc.ProductTree.Branches)
+	// Products are usually listed as a component of a platform, e.g.
+	// AppStream-9.2.0.Z.EUS:bash-0:5.1.8-9.el9_2.x86_64.
+	for _, r := range doc.ProductTree.Relationships {
+		if purl, ok := purls[r.ProductReference]; ok {
+			purls[r.FullProductName.ProductID] = purl
+		}
+	}
+
+	var advisories []*Advisory
+	for _, v := range doc.Vulnerabilities {
+		a := &Advisory{ID: v.CVE, Summary: v.Title, Severity: doc.Document.AggregateSeverity.Text}
+		if a.ID == "" {
+			a.ID = doc.Document.Tracking.ID
+		} else if doc.Document.Tracking.ID != "" && doc.Document.Tracking.ID != a.ID {
+			a.Aliases = []string{doc.Document.Tracking.ID}
+		}
+		if a.Summary == "" {
+			a.Summary = doc.Document.Title
+		}
+		for _, t := range v.Threats {
+			if t.Category == "impact" && t.Details != "" {
+				a.Severity = t.Details
+			}
+		}
+
+		// The same rpm is usually fixed in several products.
+		seen := map[string]bool{}
+		for _, id := range v.ProductStatus.Fixed {
+			name, arch, fixed, ok := parseRPMPURL(purls[id])
+			if !ok || arch == "src" || seen[name+"."+arch+"@"+fixed] {
+				continue
+			}
+			seen[name+"."+arch+"@"+fixed] = true
+			a.Affected = append(a.Affected, Affected{
+				Type:   sbom.TypeRPM,
+				Name:   name,
+				Arch:   arch,
+				Ranges: []Range{{Fixed: fixed}},
+			})
+		}
+		advisories = append(advisories, a)
+	}
+	return advisories
+}
+
+// parseRPMPURL returns the name, architecture and [epoch:]version-release
+// of the rpm identified by purl.
+func parseRPMPURL(purl string) (string, string, string, bool) {
+	rest, ok := strings.CutPrefix(purl, "pkg:rpm/")
+	if !ok {
+		return "", "", "", false
+	}
+	rest, qualifiers, _ := strings.Cut(rest, "?")
+	name, version, ok := strings.Cut(rest[strings.LastIndex(rest, "/")+1:], "@")
+	if !ok {
+		return "", "", "", false
+	}
+	values, err := url.ParseQuery(qualifiers)
+	if err != nil {
+		return "", "", "", false
+	}
+	name, _ = url.PathUnescape(name)
+	version, _ = url.PathUnescape(version)
+	if epoch := values.Get("epoch"); epoch != "" && epoch != "0" {
+		version = epoch + ":" + version
+	}
+	return name, values.Get("arch"), version, true
+}
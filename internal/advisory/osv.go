@@ -0,0 +1,106 @@
+package advisory
+
+import (
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/sbom"
+)
+
+// osvEntry is an advisory in the OSV format, as published by osv.dev and the
+// Go vulnerability database. See https://ossf.github.io/osv-schema/.
+type osvEntry struct {
+	ID               string          `json:"id"`
+	Aliases          []string        `json:"aliases"`
+	Summary          string          `json:"summary"`
+	Affected         []osvAffected   `json:"affected"`
+	DatabaseSpecific osvDatabaseInfo `json:"database_specific"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+		PURL      string `json:"purl"`
+	} `json:"package"`
+	Ranges []struct {
+		Type   string            `json:"type"`
+		Events []osvRangeElement `json:"events"`
+	} `json:"ranges"`
+	Versions          []string        `json:"versions"`
+	EcosystemSpecific osvDatabaseInfo `json:"ecosystem_specific"`
+	DatabaseSpecific  osvDatabaseInfo `json:"database_specific"`
+}
+
+type osvRangeElement struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"last_affected"`
+}
+
+// osvDatabaseInfo holds the fields of the database and ecosystem specific
+// sections of an OSV advisory that are read.
+type osvDatabaseInfo struct {
+	Severity string `json:"severity"`
+}
+
+// advisory converts e, keeping only the rpm and Go packages it affects.
+func (e osvEntry) advisory() *Advisory {
+	a := &Advisory{ID: e.ID, Aliases: e.Aliases, Summary: e.Summary, Severity: e.DatabaseSpecific.Severity}
+	for _, affected := range e.Affected {
+		packageType := osvPackageType(affected.Package.Ecosystem, affected.Package.PURL)
+		if packageType == "" {
+			continue
+		}
+		if a.Severity == "" {
+			a.Severity = affected.DatabaseSpecific.Severity
+		}
+		if a.Severity == "" {
+			a.Severity = affected.EcosystemSpecific.Severity
+		}
+
+		converted := Affected{Type: packageType, Name: affected.Package.Name, Versions: affected.Versions}
+		for _, r := range affected.Ranges {
+			// Git ranges refer to commits, which the image does not record.
+			if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+				continue
+			}
+			converted.Ranges = append(converted.Ranges, osvRanges(r.Events)...)
+		}
+		a.Affected = append(a.Affected, converted)
+	}
+	return a
+}
+
+// osvRanges splits the events of an OSV range, which may introduce and fix a
+// vulnerability several times, into ranges.
+func osvRanges(events []osvRangeElement) []Range {
+	var ranges []Range
+	var current *Range
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			ranges = append(ranges, Range{Introduced: e.Introduced})
+			current = &ranges[len(ranges)-1]
+		case current == nil:
+			// A fix without an introduction affects every earlier version.
+			ranges = append(ranges, Range{Fixed: e.Fixed, LastAffected: e.LastAffected})
+		default:
+			current.Fixed, current.LastAffected = e.Fixed, e.LastAffected
+			current = nil
+		}
+	}
+	return ranges
+}
+
+// osvPackageType returns the component type of packages in ecosystem, or an
+// empty string if they are not matched.
+func osvPackageType(ecosystem, purl string) string {
+	switch {
+	case ecosystem == "Go", strings.HasPrefix(purl, "pkg:golang/"):
+		return sbom.TypeGolang
+	case strings.HasPrefix(ecosystem, "Red Hat"), strings.HasPrefix(ecosystem, "AlmaLinux"),
+		strings.HasPrefix(ecosystem, "Rocky Linux"), strings.HasPrefix(purl, "pkg:rpm/"):
+		return sbom.TypeRPM
+	}
+	return ""
+}
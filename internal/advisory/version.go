@@ -0,0 +1,168 @@
+package advisory
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// compareGo compares two Go module versions, e.g. v0.17.0, or Go releases,
+// e.g. go1.21.5, returning -1, 0 or 1.
+func compareGo(a, b string) (int, error) {
+	va, err := semver.ParseTolerant(strings.TrimPrefix(a, "go"))
+	if err != nil {
+		return 0, err
+	}
+	vb, err := semver.ParseTolerant(strings.TrimPrefix(b, "go"))
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// compareRPM compares two rpm versions in the [epoch:]version-release form,
+// the way rpm does, returning -1, 0 or 1. A missing epoch is 0, and a missing
+// release is not compared.
+func compareRPM(a, b string) (int, error) {
+	ea, va, ra, err := splitEVR(a)
+	if err != nil {
+		return 0, err
+	}
+	eb, vb, rb, err := splitEVR(b)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case ea < eb:
+		return -1, nil
+	case ea > eb:
+		return 1, nil
+	}
+	if cmp := rpmvercmp(va, vb); cmp != 0 {
+		return cmp, nil
+	}
+	if ra == "" || rb == "" {
+		return 0, nil
+	}
+	return rpmvercmp(ra, rb), nil
+}
+
+// splitEVR splits an rpm version into its epoch, version and release.
+func splitEVR(evr string) (int, string, string, error) {
+	epoch := 0
+	if e, rest, ok := strings.Cut(evr, ":"); ok {
+		var err error
+		epoch, err = strconv.Atoi(e)
+		if err != nil {
+			return 0, "", "", err
+		}
+		evr = rest
+	}
+	version, release := evr, ""
+	if i := strings.LastIndex(evr, "-"); i != -1 {
+		version, release = evr[:i], evr[i+1:]
+	}
+	return epoch, version, release, nil
+}
+
+// rpmvercmp compares two version or release strings as rpm does. They are
+// split into alternating numeric and alphabetic segments, which are compared
+// in turn. Numeric segments are newer than alphabetic ones, a tilde sorts
+// before anything, and a caret sorts after the end of the string.
+func rpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	isAlnum := func(c byte) bool {
+		return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+	}
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	isAlpha := func(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+	skipSeparators := func(s string) string {
+		for len(s) > 0 && !isAlnum(s[0]) && s[0] != '~' && s[0] != '^' {
+			s = s[1:]
+		}
+		return s
+	}
+	segment := func(s string, pred func(byte) bool) (string, string) {
+		i := 0
+		for i < len(s) && pred(s[i]) {
+			i++
+		}
+		return s[:i], s[i:]
+	}
+
+	for len(a) > 0 || len(b) > 0 {
+		a, b = skipSeparators(a), skipSeparators(b)
+
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			switch {
+			case a == "":
+				return -1
+			case b == "":
+				return 1
+			case !strings.HasPrefix(a, "^"):
+				return 1
+			case !strings.HasPrefix(b, "^"):
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if a == "" || b == "" {
+			break
+		}
+
+		pred := isAlpha
+		numeric := isDigit(a[0])
+		if numeric {
+			pred = isDigit
+		}
+		var segA, segB string
+		segA, a = segment(a, pred)
+		segB, b = segment(b, pred)
+		if segB == "" {
+			// Segments of different types: numeric is newer.
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		if numeric {
+			segA, segB = strings.TrimLeft(segA, "0"), strings.TrimLeft(segB, "0")
+			if len(segA) != len(segB) {
+				if len(segA) < len(segB) {
+					return -1
+				}
+				return 1
+			}
+		}
+		if cmp := strings.Compare(segA, segB); cmp != 0 {
+			return cmp
+		}
+	}
+
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	}
+	return 1
+}
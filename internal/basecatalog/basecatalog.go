@@ -0,0 +1,247 @@
+// Package basecatalog reads and writes a catalog of approved base images and
+// the uncompressed top layer IDs of those images, so that an image's lineage
+// can be verified without access to Pyxis. The catalog is exported from Pyxis
+// on a connected workstation and copied to the disconnected environment.
+package basecatalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultRegistry is the registry the certified base images are published to.
+// Images found in the catalog without a registry are assumed to be published
+// there.
+const DefaultRegistry = "registry.access.redhat.com"
+
+// Catalog lists the approved base images.
+type Catalog struct {
+	// Generated is when the catalog was exported.
+	Generated time.Time `json:"generated,omitempty"`
+	// Source is the Pyxis host the catalog was exported from.
+	Source string  `json:"source,omitempty"`
+	Images []Image `json:"images"`
+}
+
+// Image is an approved base image.
+type Image struct {
+	// ID is the Pyxis ID of the image, if exported from Pyxis.
+	ID           string   `json:"id,omitempty"`
+	Registry     string   `json:"registry,omitempty"`
+	Repository   string   `json:"repository,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Architecture string   `json:"architecture,omitempty"`
+	// Digest is the image's manifest digest.
+	Digest string `json:"digest,omitempty"`
+	// UncompressedTopLayerID is the diff ID of the image's top layer, which
+	// is found among the diff IDs of the images built from it.
+	UncompressedTopLayerID string `json:"uncompressed_top_layer_id"`
+}
+
+// Load reads the catalog at p, a JSON or YAML file. The file holds either a
+// catalog, or the list of its images.
+func Load(p string) (*Catalog, error) {
+	contents, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("could not read base image catalog: %w", err)
+	}
+
+	catalog, err := Parse(contents)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse base image catalog %s: %w", p, err)
+	}
+	return catalog, nil
+}
+
+// Parse parses a catalog in JSON or YAML. Every image must have a valid
+// uncompressed top layer ID.
+func Parse(contents []byte) (*Catalog, error) {
+	contents, err := yaml.YAMLToJSON(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog Catalog
+	if bytes.HasPrefix(bytes.TrimSpace(contents), []byte("[")) {
+		err = json.Unmarshal(contents, &catalog.Images)
+	} else {
+		err = json.Unmarshal(contents, &catalog)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, image := range catalog.Images {
+		if _, err := cranev1.NewHash(image.UncompressedTopLayerID); err != nil {
+			return nil, fmt.Errorf("image %d (%s): invalid uncompressed_top_layer_id %q: %w", i, image.name(), image.UncompressedTopLayerID, err)
+		}
+	}
+	return &catalog, nil
+}
+
+// name returns the repository of the image, for use in messages.
+func (i Image) name() string {
+	if i.Repository == "" {
+		return i.ID
+	}
+	registry := i.Registry
+	if registry == "" {
+		registry = DefaultRegistry
+	}
+	return registry + "/" + i.Repository
+}
+
+// Marshal returns the catalog as YAML if asYAML is set, and as JSON otherwise.
+func (c *Catalog) Marshal(asYAML bool) ([]byte, error) {
+	contents, err := json.MarshalIndent(c, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	if asYAML {
+		return yaml.JSONToYAML(contents)
+	}
+	return append(contents, '\n'), nil
+}
+
+// WriteFile writes the catalog to p, as YAML if p ends in .yaml or .yml, and
+// as JSON otherwise.
+func (c *Catalog) WriteFile(p string) error {
+	ext := strings.ToLower(filepath.Ext(p))
+	contents, err := c.Marshal(ext == ".yaml" || ext == ".yml")
+	if err != nil {
+		return fmt.Errorf("could not marshal base image catalog: %w", err)
+	}
+	if err := os.WriteFile(p, contents, 0o644); err != nil {
+		return fmt.Errorf("could not write base image catalog: %w", err)
+	}
+	return nil
+}
+
+// CertifiedImagesContainingLayers returns the images of the catalog whose
+// uncompressed top layer ID is one of uncompressedLayerHashes. It has the same
+// semantics as the Pyxis query of the same name, so that the catalog can be
+// used in its place.
+func (c *Catalog) CertifiedImagesContainingLayers(ctx context.Context, uncompressedLayerHashes []cranev1.Hash) ([]pyxis.CertImage, error) {
+	layers := make(map[string]struct{}, len(uncompressedLayerHashes))
+	for _, layer := range uncompressedLayerHashes {
+		layers[layer.String()] = struct{}{}
+	}
+
+	images := []pyxis.CertImage{}
+	for _, image := range c.Images {
+		if _, ok := layers[image.UncompressedTopLayerID]; !ok {
+			continue
+		}
+		registry := image.Registry
+		if registry == "" {
+			registry = DefaultRegistry
+		}
+		tags := make([]pyxis.Tag, 0, len(image.Tags))
+		for _, tag := range image.Tags {
+			tags = append(tags, pyxis.Tag{Name: tag})
+		}
+		images = append(images, pyxis.CertImage{
+			ID:                     image.ID,
+			Certified:              true,
+			Architecture:           image.Architecture,
+			DockerImageDigest:      image.Digest,
+			UncompressedTopLayerID: image.UncompressedTopLayerID,
+			Repositories: []pyxis.Repository{{
+				Registry:   registry,
+				Repository: image.Repository,
+				Tags:       tags,
+			}},
+		})
+	}
+	return images, nil
+}
+
+// NewLayerHashChecker returns a layer hash checker that looks up layers in the
+// catalog at p. The catalog is read when layers are looked up, so that an
+// unreadable catalog is reported by the check using it.
+func NewLayerHashChecker(p string) *fileLayerHashChecker {
+	return &fileLayerHashChecker{path: p}
+}
+
+type fileLayerHashChecker struct {
+	path string
+}
+
+func (f *fileLayerHashChecker) CertifiedImagesContainingLayers(ctx context.Context, uncompressedLayerHashes []cranev1.Hash) ([]pyxis.CertImage, error) {
+	catalog, err := Load(f.path)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.CertifiedImagesContainingLayers(ctx, uncompressedLayerHashes)
+}
+
+// imageSource lists the certified images of a registry.
+type imageSource interface {
+	CertifiedImagesInRegistry(ctx context.Context, registry string, repositories []string) ([]pyxis.CertImage, error)
+}
+
+// Export builds a catalog of the certified images of registry found in
+// source. If repositories is not empty, only the images of those
+// repositories are included. An image published to several repositories of
+// registry is listed once for each. Images without an uncompressed top layer
+// ID cannot be matched, and are left out.
+func Export(ctx context.Context, source imageSource, registry string, repositories []string) (*Catalog, error) {
+	certImages, err := source.CertifiedImagesInRegistry(ctx, registry, repositories)
+	if err != nil {
+		return nil, fmt.Errorf("could not list the certified images of %s: %w", registry, err)
+	}
+
+	catalog := &Catalog{Generated: time.Now().UTC(), Images: []Image{}}
+	for _, certImage := range certImages {
+		if certImage.UncompressedTopLayerID == "" {
+			continue
+		}
+		for _, repo := range certImage.Repositories {
+			if repo.Registry != registry {
+				continue
+			}
+			if len(repositories) > 0 && !slices.Contains(repositories, repo.Repository) {
+				continue
+			}
+			tags := make([]string, 0, len(repo.Tags))
+			for _, tag := range repo.Tags {
+				tags = append(tags, tag.Name)
+			}
+			sort.Strings(tags)
+			catalog.Images = append(catalog.Images, Image{
+				ID:                     certImage.ID,
+				Registry:               repo.Registry,
+				Repository:             repo.Repository,
+				Tags:                   tags,
+				Architecture:           certImage.Architecture,
+				Digest:                 certImage.DockerImageDigest,
+				UncompressedTopLayerID: certImage.UncompressedTopLayerID,
+			})
+		}
+	}
+
+	sort.SliceStable(catalog.Images, func(i, j int) bool {
+		a, b := catalog.Images[i], catalog.Images[j]
+		if a.Repository != b.Repository {
+			return a.Repository < b.Repository
+		}
+		if a.Architecture != b.Architecture {
+			return a.Architecture < b.Architecture
+		}
+		return a.ID < b.ID
+	})
+	return catalog, nil
+}
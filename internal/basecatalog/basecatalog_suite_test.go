@@ -0,0 +1,13 @@
+package basecatalog
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBaseCatalog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Base Catalog Suite")
+}
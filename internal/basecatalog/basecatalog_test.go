@@ -0,0 +1,184 @@
+package basecatalog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
+)
+
+const (
+	ubi9Layer    = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	minimalLayer = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	otherLayer   = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+)
+
+type fakeImageSource struct {
+	images []pyxis.CertImage
+	err    error
+}
+
+func (f *fakeImageSource) CertifiedImagesInRegistry(ctx context.Context, registry string, repositories []string) ([]pyxis.CertImage, error) {
+	return f.images, f.err
+}
+
+var _ = Describe("Base image catalog", func() {
+	Context("When parsing a catalog", func() {
+		It("should read a JSON catalog", func() {
+			catalog, err := Parse([]byte(`{
+				"generated": "2026-10-01T00:00:00Z",
+				"source": "catalog.redhat.com/api/containers",
+				"images": [
+					{"repository": "ubi9/ubi", "tags": ["9.4"], "uncompressed_top_layer_id": "` + ubi9Layer + `"}
+				]
+			}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(catalog.Source).To(Equal("catalog.redhat.com/api/containers"))
+			Expect(catalog.Generated.IsZero()).To(BeFalse())
+			Expect(catalog.Images).To(HaveLen(1))
+			Expect(catalog.Images[0].Tags).To(Equal([]string{"9.4"}))
+		})
+		It("should read a YAML list of images", func() {
+			catalog, err := Parse([]byte(`
+- repository: ubi9/ubi
+  uncompressed_top_layer_id: ` + ubi9Layer + `
+- registry: registry.redhat.io
+  repository: ubi9/ubi-minimal
+  uncompressed_top_layer_id: ` + minimalLayer + `
+`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(catalog.Images).To(HaveLen(2))
+			Expect(catalog.Images[1].Registry).To(Equal("registry.redhat.io"))
+		})
+		It("should reject an invalid layer ID", func() {
+			_, err := Parse([]byte(`[{"repository": "ubi9/ubi", "uncompressed_top_layer_id": "not-a-digest"}]`))
+			Expect(err).To(MatchError(ContainSubstring("registry.access.redhat.com/ubi9/ubi")))
+		})
+		It("should reject malformed contents", func() {
+			_, err := Parse([]byte(`{"images": {`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When looking up layers", func() {
+		var catalog *Catalog
+
+		BeforeEach(func() {
+			catalog = &Catalog{Images: []Image{
+				{ID: "ubi9", Repository: "ubi9/ubi", Tags: []string{"9.4"}, Architecture: "amd64", UncompressedTopLayerID: ubi9Layer},
+				{ID: "minimal", Registry: "registry.redhat.io", Repository: "ubi9/ubi-minimal", UncompressedTopLayerID: minimalLayer},
+			}}
+		})
+
+		It("should return the images whose top layer is among the layers", func() {
+			images, err := catalog.CertifiedImagesContainingLayers(context.TODO(), []cranev1.Hash{
+				{Algorithm: "sha256", Hex: ubi9Layer[7:]},
+				{Algorithm: "sha256", Hex: otherLayer[7:]},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(images).To(HaveLen(1))
+			Expect(images[0].ID).To(Equal("ubi9"))
+			Expect(images[0].UncompressedTopLayerID).To(Equal(ubi9Layer))
+			Expect(images[0].Repositories).To(ConsistOf(pyxis.Repository{
+				Registry:   DefaultRegistry,
+				Repository: "ubi9/ubi",
+				Tags:       []pyxis.Tag{{Name: "9.4"}},
+			}))
+		})
+		It("should return no images if no top layer matches", func() {
+			images, err := catalog.CertifiedImagesContainingLayers(context.TODO(), []cranev1.Hash{
+				{Algorithm: "sha256", Hex: otherLayer[7:]},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(images).To(BeEmpty())
+		})
+	})
+
+	Context("When looking up layers in a catalog file", func() {
+		It("should read the catalog", func() {
+			p := filepath.Join(GinkgoT().TempDir(), "catalog.yaml")
+			Expect((&Catalog{Images: []Image{{Repository: "ubi9/ubi", UncompressedTopLayerID: ubi9Layer}}}).WriteFile(p)).To(Succeed())
+
+			images, err := NewLayerHashChecker(p).CertifiedImagesContainingLayers(context.TODO(), []cranev1.Hash{
+				{Algorithm: "sha256", Hex: ubi9Layer[7:]},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(images).To(HaveLen(1))
+		})
+		It("should return an error if the catalog does not exist", func() {
+			_, err := NewLayerHashChecker(filepath.Join(GinkgoT().TempDir(), "missing.json")).CertifiedImagesContainingLayers(context.TODO(), nil)
+			Expect(err).To(MatchError(os.ErrNotExist))
+		})
+	})
+
+	Context("When exporting a catalog", func() {
+		var source *fakeImageSource
+
+		BeforeEach(func() {
+			source = &fakeImageSource{images: []pyxis.CertImage{
+				{
+					ID:                     "minimal",
+					Architecture:           "arm64",
+					UncompressedTopLayerID: minimalLayer,
+					Repositories: []pyxis.Repository{
+						{Registry: DefaultRegistry, Repository: "ubi9/ubi-minimal", Tags: []pyxis.Tag{{Name: "latest"}, {Name: "9.4"}}},
+						{Registry: "registry.redhat.io", Repository: "ubi9/ubi-minimal"},
+					},
+				},
+				{
+					ID:                     "ubi9",
+					Architecture:           "amd64",
+					DockerImageDigest:      "sha256:abcd",
+					UncompressedTopLayerID: ubi9Layer,
+					Repositories: []pyxis.Repository{
+						{Registry: DefaultRegistry, Repository: "ubi9/ubi"},
+						{Registry: DefaultRegistry, Repository: "ubi9"},
+					},
+				},
+				{ID: "no-layer", Repositories: []pyxis.Repository{{Registry: DefaultRegistry, Repository: "ubi9/ubi"}}},
+			}}
+		})
+
+		It("should list each repository of the registry an image is published to", func() {
+			catalog, err := Export(context.TODO(), source, DefaultRegistry, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(catalog.Generated.IsZero()).To(BeFalse())
+			Expect(catalog.Images).To(Equal([]Image{
+				{ID: "ubi9", Registry: DefaultRegistry, Repository: "ubi9", Tags: []string{}, Architecture: "amd64", Digest: "sha256:abcd", UncompressedTopLayerID: ubi9Layer},
+				{ID: "ubi9", Registry: DefaultRegistry, Repository: "ubi9/ubi", Tags: []string{}, Architecture: "amd64", Digest: "sha256:abcd", UncompressedTopLayerID: ubi9Layer},
+				{ID: "minimal", Registry: DefaultRegistry, Repository: "ubi9/ubi-minimal", Tags: []string{"9.4", "latest"}, Architecture: "arm64", UncompressedTopLayerID: minimalLayer},
+			}))
+		})
+		It("should only list the requested repositories", func() {
+			catalog, err := Export(context.TODO(), source, DefaultRegistry, []string{"ubi9/ubi-minimal"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(catalog.Images).To(HaveLen(1))
+			Expect(catalog.Images[0].ID).To(Equal("minimal"))
+		})
+		It("should round trip through a file", func() {
+			catalog, err := Export(context.TODO(), source, DefaultRegistry, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, name := range []string{"catalog.json", "catalog.yml"} {
+				p := filepath.Join(GinkgoT().TempDir(), name)
+				Expect(catalog.WriteFile(p)).To(Succeed())
+				loaded, err := Load(p)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(loaded.Images).To(HaveLen(len(catalog.Images)))
+				Expect(loaded.Images[2].Tags).To(Equal(catalog.Images[2].Tags))
+				Expect(loaded.Generated.Equal(catalog.Generated)).To(BeTrue())
+			}
+		})
+		It("should return an error if the images cannot be listed", func() {
+			source.err = errors.New("pyxis is unavailable")
+			_, err := Export(context.TODO(), source, DefaultRegistry, nil)
+			Expect(err).To(MatchError(ContainSubstring("pyxis is unavailable")))
+		})
+	})
+})
@@ -0,0 +1,175 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// HashScheme prefixes every certification hash, identifying how it was computed.
+// Hashes without a prefix were computed with the retired md5 scheme, which
+// collapsed files with identical content into a single entry.
+const HashScheme = "sha256-v2"
+
+// ErrHashMismatch is returned by VerifyHash if a bundle does not match the expected hash.
+var ErrHashMismatch = errors.New("bundle does not match the certification hash")
+
+// HashedFile is a file of a bundle and the sha256 of its contents.
+type HashedFile struct {
+	// Path is the slash-separated path of the file, relative to the bundle root.
+	Path   string
+	Digest string
+}
+
+// Hash is the certification hash of a bundle. It is computed over a manifest
+// of every regular file in the bundle, sorted by path, so renaming, adding or
+// removing a file changes the hash even if file contents are duplicated.
+type Hash struct {
+	// Files lists the hashed files, sorted by path.
+	Files []HashedFile
+}
+
+// Manifest returns the canonical manifest the hash is computed over, one
+// "<sha256>  ./<path>" line per file, as written to hashes.txt.
+func (h Hash) Manifest() []byte {
+	var buf bytes.Buffer
+	for _, f := range h.Files {
+		fmt.Fprintf(&buf, "%s  ./%s\n", f.Digest, f.Path)
+	}
+	return buf.Bytes()
+}
+
+// String returns the scheme-prefixed hash, e.g. sha256-v2:3a6eb0...
+func (h Hash) String() string {
+	return fmt.Sprintf("%s:%x", HashScheme, sha256.Sum256(h.Manifest()))
+}
+
+// HashDir computes the certification hash of the bundle extracted to dir.
+func HashDir(dir string) (Hash, error) {
+	fileSystem := os.DirFS(dir)
+
+	var files []HashedFile
+	err := fs.WalkDir(fileSystem, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("could not read bundle directory: %s: %w", p, err)
+		}
+		if !d.Type().IsRegular() || excludedFromHash(p) {
+			return nil
+		}
+		f, err := fileSystem.Open(p)
+		if err != nil {
+			return fmt.Errorf("could not read file: %s: %w", p, err)
+		}
+		defer f.Close()
+
+		digest, err := digestOf(f)
+		if err != nil {
+			return fmt.Errorf("could not read file: %s: %w", p, err)
+		}
+		files = append(files, HashedFile{Path: p, Digest: digest})
+		return nil
+	})
+	if err != nil {
+		return Hash{}, err
+	}
+
+	return newHash(files), nil
+}
+
+// HashTar computes the certification hash of the bundle in the flattened
+// filesystem tarball read from r, e.g. as returned by mutate.Extract. Hard
+// links are hashed as the file they link to, as they are when the bundle is
+// extracted for HashDir.
+func HashTar(r io.Reader) (Hash, error) {
+	var files []HashedFile
+	// digests holds the digest of every regular file, including excluded
+	// ones, so hard links to them can be resolved.
+	digests := map[string]string{}
+	var links []*tar.Header
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Hash{}, fmt.Errorf("could not read bundle archive: %w", err)
+		}
+
+		p := archivePath(header.Name)
+		switch header.Typeflag {
+		case tar.TypeReg:
+			digest, err := digestOf(tr)
+			if err != nil {
+				return Hash{}, fmt.Errorf("could not read file: %s: %w", p, err)
+			}
+			digests[p] = digest
+			if !excludedFromHash(p) {
+				files = append(files, HashedFile{Path: p, Digest: digest})
+			}
+		case tar.TypeLink:
+			links = append(links, header)
+		}
+	}
+
+	// Hard links are created after every other entry is extracted, so they
+	// are resolved in the same order. Links to missing files are not created.
+	for _, link := range links {
+		p := archivePath(link.Name)
+		digest, ok := digests[archivePath(link.Linkname)]
+		if !ok {
+			continue
+		}
+		digests[p] = digest
+		if !excludedFromHash(p) {
+			files = append(files, HashedFile{Path: p, Digest: digest})
+		}
+	}
+
+	return newHash(files), nil
+}
+
+// archivePath returns the path of a tar entry relative to the bundle root.
+func archivePath(name string) string {
+	return path.Clean(strings.TrimPrefix(name, "/"))
+}
+
+// VerifyHash returns nil if h matches expected, ErrHashMismatch if it does
+// not, or an error if expected was not computed with HashScheme.
+func VerifyHash(h Hash, expected string) error {
+	scheme, _, ok := strings.Cut(expected, ":")
+	if !ok || scheme != HashScheme {
+		return fmt.Errorf("unsupported certification hash %q: only %s hashes can be verified", expected, HashScheme)
+	}
+	if actual := h.String(); actual != expected {
+		return fmt.Errorf("%w: expected %s, got %s", ErrHashMismatch, expected, actual)
+	}
+	return nil
+}
+
+func newHash(files []HashedFile) Hash {
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return Hash{Files: files}
+}
+
+// excludedFromHash returns true for files that are not part of the certified
+// bundle content.
+func excludedFromHash(p string) bool {
+	return path.Base(p) == "Dockerfile"
+}
+
+func digestOf(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
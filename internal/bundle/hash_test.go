@@ -0,0 +1,124 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bundle certification hash", func() {
+	var dir string
+
+	writeFile := func(name, contents string) {
+		p := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(p), 0o755)).To(Succeed())
+		Expect(os.WriteFile(p, []byte(contents), 0o644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		writeFile("manifests/a.yaml", "same")
+		writeFile("metadata/annotations.yaml", "annotations")
+		writeFile("Dockerfile", "FROM scratch")
+	})
+
+	It("should list every file, sorted by path, excluding the Dockerfile", func() {
+		hash, err := HashDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hash.Files).To(HaveLen(2))
+		Expect(hash.Files[0].Path).To(Equal("manifests/a.yaml"))
+		Expect(hash.Files[1].Path).To(Equal("metadata/annotations.yaml"))
+		Expect(string(hash.Manifest())).To(HavePrefix(hash.Files[0].Digest + "  ./manifests/a.yaml\n"))
+		Expect(hash.String()).To(HavePrefix(HashScheme + ":"))
+	})
+
+	It("should change when a file with duplicate content is added", func() {
+		before, err := HashDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+
+		writeFile("manifests/b.yaml", "same")
+		after, err := HashDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(after.String()).ToNot(Equal(before.String()))
+	})
+
+	It("should change when a file is renamed", func() {
+		before, err := HashDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(os.Rename(filepath.Join(dir, "manifests", "a.yaml"), filepath.Join(dir, "manifests", "c.yaml"))).To(Succeed())
+		after, err := HashDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(after.String()).ToNot(Equal(before.String()))
+	})
+
+	It("should hash an archive of the bundle the same as the directory", func() {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for name, contents := range map[string]string{
+			"/metadata/annotations.yaml": "annotations",
+			"manifests/a.yaml":           "same",
+			"Dockerfile":                 "FROM ubi",
+		} {
+			Expect(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o644, Size: int64(len(contents))})).To(Succeed())
+			_, err := tw.Write([]byte(contents))
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "manifests/", Mode: 0o755})).To(Succeed())
+		Expect(tw.Close()).To(Succeed())
+
+		fromTar, err := HashTar(&buf)
+		Expect(err).ToNot(HaveOccurred())
+		fromDir, err := HashDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fromTar).To(Equal(fromDir))
+	})
+
+	It("should hash a hard link in an archive as the file it links to", func() {
+		Expect(os.Link(filepath.Join(dir, "manifests", "a.yaml"), filepath.Join(dir, "manifests", "b.yaml"))).To(Succeed())
+
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for name, contents := range map[string]string{
+			"metadata/annotations.yaml": "annotations",
+			"manifests/a.yaml":          "same",
+		} {
+			Expect(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o644, Size: int64(len(contents))})).To(Succeed())
+			_, err := tw.Write([]byte(contents))
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeLink, Name: "manifests/b.yaml", Linkname: "/manifests/a.yaml"})).To(Succeed())
+		Expect(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeLink, Name: "manifests/missing.yaml", Linkname: "manifests/none.yaml"})).To(Succeed())
+		Expect(tw.Close()).To(Succeed())
+
+		fromTar, err := HashTar(&buf)
+		Expect(err).ToNot(HaveOccurred())
+		fromDir, err := HashDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fromDir.Files).To(HaveLen(3))
+		Expect(fromTar).To(Equal(fromDir))
+	})
+
+	Context("verifying a hash", func() {
+		It("should succeed if the hash matches", func() {
+			hash, err := HashDir(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(VerifyHash(hash, hash.String())).To(Succeed())
+		})
+
+		It("should fail if the hash does not match", func() {
+			hash, err := HashDir(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(VerifyHash(hash, HashScheme+":"+strings.Repeat("0", 64))).To(MatchError(ErrHashMismatch))
+		})
+
+		It("should reject hashes of the retired md5 scheme", func() {
+			Expect(VerifyHash(Hash{}, "d41d8cd98f00b204e9800998ecf8427e")).To(MatchError(ContainSubstring("unsupported certification hash")))
+		})
+	})
+})
@@ -17,7 +17,8 @@ const (
 // to use and identify a given check.
 type Check interface {
 	// Validate will test the provided image and determine whether the
-	// image complies with the check's requirements.
+	// image complies with the check's requirements. The specific problems
+	// detected should be reported with AddFinding.
 	Validate(ctx context.Context, imageReference image.ImageReference) (result bool, err error)
 	// Name returns the name of the check.
 	Name() string
@@ -27,6 +28,66 @@ type Check interface {
 	Help() HelpText
 }
 
+// SerialCheck is an optional interface for checks that are not safe to run
+// concurrently with other checks, e.g. because they modify shared cluster state.
+// The engine runs these checks by themselves, after all previously scheduled
+// checks have completed.
+type SerialCheck interface {
+	Check
+	// RequiresSerialExecution returns true if the check must not run
+	// alongside any other check.
+	RequiresSerialExecution() bool
+}
+
+// RequiresSerialExecution returns true if c implements SerialCheck and
+// requests to be run by itself.
+func RequiresSerialExecution(c Check) bool {
+	sc, ok := c.(SerialCheck)
+	return ok && sc.RequiresSerialExecution()
+}
+
+// Requirement is a set of inputs a check needs the engine to provide.
+type Requirement uint
+
+const (
+	// RequiresConfig indicates the check reads the image config.
+	RequiresConfig Requirement = 1 << iota
+	// RequiresManifest indicates the check reads the image manifest or
+	// queries the registry the image was read from.
+	RequiresManifest
+	// RequiresFilesystem indicates the check reads the image's layers or its
+	// extracted filesystem.
+	RequiresFilesystem
+	// RequiresCluster indicates the check needs access to an OpenShift cluster.
+	RequiresCluster
+
+	// RequiresAll includes every input the engine can provide.
+	RequiresAll = RequiresConfig | RequiresManifest | RequiresFilesystem | RequiresCluster
+)
+
+// Has returns true if r includes all of req.
+func (r Requirement) Has(req Requirement) bool {
+	return r&req == req
+}
+
+// RequirementsCheck is an optional interface for checks that declare the inputs
+// they need. The engine only fetches what the checks it runs require, e.g. it does
+// not download or extract the image's layers if no check needs the filesystem.
+type RequirementsCheck interface {
+	Check
+	// Requirements returns the inputs the check reads.
+	Requirements() Requirement
+}
+
+// RequirementsOf returns the inputs c needs. Checks that do not implement
+// RequirementsCheck are assumed to need every input.
+func RequirementsOf(c Check) Requirement {
+	if rc, ok := c.(RequirementsCheck); ok {
+		return rc.Requirements()
+	}
+	return RequiresAll
+}
+
 // Metadata contains useful information regarding the check.
 type Metadata struct {
 	// Description contains a brief text detailing the overall goal of the check.
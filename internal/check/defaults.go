@@ -1,11 +1,13 @@
 package check
 
 var (
-	DefaultCertImageFilename    = "cert-image.json"
-	DefaultRPMManifestFilename  = "rpm-manifest.json"
-	DefaultTestResultsFilename  = "results.json"
-	DefaultArtifactsTarFileName = "artifacts.tar"
-	DefaultPyxisHost            = "catalog.redhat.com/api/containers"
-	DefaultPyxisEnv             = "prod"
-	SystemdDir                  = "/etc/systemd/system"
+	DefaultCertImageFilename       = "cert-image.json"
+	DefaultRPMManifestFilename     = "rpm-manifest.json"
+	DefaultBinaryHardeningFilename = "binary-hardening.json"
+	DefaultFIPSEvidenceFilename    = "fips-evidence.json"
+	DefaultTestResultsFilename     = "results.json"
+	DefaultArtifactsTarFileName    = "artifacts.tar"
+	DefaultPyxisHost               = "catalog.redhat.com/api/containers"
+	DefaultPyxisEnv                = "prod"
+	SystemdDir                     = "/etc/systemd/system"
 )
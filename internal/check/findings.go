@@ -0,0 +1,78 @@
+package check
+
+import (
+	"context"
+	"sync"
+)
+
+// Indicates the possible severities of a Finding.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Finding is a specific problem detected by a check, e.g. a prohibited package or a
+// missing label. Findings are reported in the results alongside the check's outcome,
+// so that users can see what needs fixing without reading the log file. Only
+// Message and Severity are required.
+type Finding struct {
+	// Message describes the problem.
+	Message string `json:"message" xml:"message"`
+	// Severity is one of SeverityError, SeverityWarning or SeverityInfo.
+	Severity string `json:"severity" xml:"severity"`
+	// Path is the absolute path of the file in the image the finding relates to.
+	Path string `json:"path,omitempty" xml:"path,omitempty"`
+	// Package is the name-version-release.arch of the package the finding relates to.
+	Package string `json:"package,omitempty" xml:"package,omitempty"`
+	// Layer is the digest of the image layer the finding relates to.
+	Layer string `json:"layer,omitempty" xml:"layer,omitempty"`
+	// Remediation suggests how to address this specific finding.
+	Remediation string `json:"remediation,omitempty" xml:"remediation,omitempty"`
+}
+
+// Findings collects the findings reported by a check while it runs. It is safe
+// for concurrent use.
+type Findings struct {
+	mu       sync.Mutex
+	findings []Finding
+}
+
+// Add records f.
+func (fs *Findings) Add(f Finding) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.findings = append(fs.findings, f)
+}
+
+// List returns the findings recorded so far, in the order they were added.
+func (fs *Findings) List() []Finding {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if len(fs.findings) == 0 {
+		return nil
+	}
+	return append([]Finding(nil), fs.findings...)
+}
+
+type findingsContextKey struct{}
+
+// ContextWithFindings adds fs to ctx. The engine gives each check a context
+// carrying its own Findings.
+func ContextWithFindings(ctx context.Context, fs *Findings) context.Context {
+	return context.WithValue(ctx, findingsContextKey{}, fs)
+}
+
+// FindingsFromContext returns the Findings in ctx, or nil.
+func FindingsFromContext(ctx context.Context) *Findings {
+	fs, _ := ctx.Value(findingsContextKey{}).(*Findings)
+	return fs
+}
+
+// AddFinding records f for the check running with ctx. It does nothing if ctx
+// carries no Findings, e.g. when a check is called directly.
+func AddFinding(ctx context.Context, f Finding) {
+	if fs := FindingsFromContext(ctx); fs != nil {
+		fs.Add(f)
+	}
+}
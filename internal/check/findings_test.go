@@ -0,0 +1,30 @@
+package check
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Findings", func() {
+	It("should record findings added through the context", func() {
+		fs := &Findings{}
+		ctx := ContextWithFindings(context.TODO(), fs)
+
+		AddFinding(ctx, Finding{Message: "first", Severity: SeverityError})
+		AddFinding(ctx, Finding{Message: "second", Severity: SeverityWarning, Path: "/etc/passwd"})
+
+		Expect(FindingsFromContext(ctx)).To(BeIdenticalTo(fs))
+		Expect(fs.List()).To(Equal([]Finding{
+			{Message: "first", Severity: SeverityError},
+			{Message: "second", Severity: SeverityWarning, Path: "/etc/passwd"},
+		}))
+	})
+
+	It("should ignore findings when the context has no recorder", func() {
+		ctx := context.TODO()
+		AddFinding(ctx, Finding{Message: "dropped", Severity: SeverityInfo})
+		Expect(FindingsFromContext(ctx)).To(BeNil())
+	})
+})
@@ -0,0 +1,162 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Selection narrows the checks of a policy and adjusts their levels. Check names
+// are matched case-insensitively, as configuration keys are not case-preserving.
+// Results of a run with a non-empty Selection do not represent the full policy.
+type Selection struct {
+	// Include lists the only checks to run. If empty, all checks are run.
+	Include []string
+	// Exclude lists checks not to run. It is applied after Include.
+	Exclude []string
+	// Levels maps check names to the level they are run with, overriding
+	// the level in their Metadata.
+	Levels map[string]string
+}
+
+// IsZero returns true if s does not change the checks of a policy.
+func (s Selection) IsZero() bool {
+	return len(s.Include) == 0 && len(s.Exclude) == 0 && len(s.Levels) == 0
+}
+
+// Apply returns the checks selected by s, in their original order, with any level
+// overrides applied. An error is returned if s refers to a check that is not in
+// checks, a level is unknown, or no checks remain.
+func (s Selection) Apply(checks []Check) ([]Check, error) {
+	byName := make(map[string]Check, len(checks))
+	for _, c := range checks {
+		byName[strings.ToLower(c.Name())] = c
+	}
+
+	var unknown []string
+	for _, names := range [][]string{s.Include, s.Exclude} {
+		for _, name := range names {
+			if _, ok := byName[strings.ToLower(name)]; !ok {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	for name, level := range s.Levels {
+		if _, ok := byName[strings.ToLower(name)]; !ok {
+			unknown = append(unknown, name)
+		}
+		if !isLevel(level) {
+			return nil, fmt.Errorf("unknown level %q for check %s: must be one of %s, %s or %s", level, name, LevelBest, LevelWarn, LevelOptional)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown checks: %s", strings.Join(unknown, ", "))
+	}
+
+	included := nameSet(s.Include)
+	excluded := nameSet(s.Exclude)
+	levels := make(map[string]string, len(s.Levels))
+	for name, level := range s.Levels {
+		levels[strings.ToLower(name)] = strings.ToLower(level)
+	}
+
+	selected := make([]Check, 0, len(checks))
+	for _, c := range checks {
+		name := strings.ToLower(c.Name())
+		if len(included) > 0 && !included[name] {
+			continue
+		}
+		if excluded[name] {
+			continue
+		}
+		if level, ok := levels[name]; ok && level != c.Metadata().Level {
+			c = WithLevel(c, level)
+		}
+		selected = append(selected, c)
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.New("no checks were selected")
+	}
+
+	return selected, nil
+}
+
+// WithLevel returns c, reporting level in its Metadata instead of its own. The
+// optional interfaces implemented by c are preserved.
+func WithLevel(c Check, level string) Check {
+	if lc, ok := c.(leveledCheck); ok {
+		c = lc.Check
+	}
+	return leveledCheck{Check: c, level: level}
+}
+
+// LevelOverrides returns the names of the checks whose level was overridden
+// with WithLevel, mapped to their new level.
+func LevelOverrides(checks []Check) map[string]string {
+	overrides := map[string]string{}
+	for _, c := range checks {
+		if lc, ok := c.(leveledCheck); ok {
+			overrides[c.Name()] = lc.level
+		}
+	}
+	return overrides
+}
+
+// Unselected returns the checks in checks whose name is not in selected, in
+// their original order.
+func Unselected(checks, selected []Check) []Check {
+	names := make(map[string]bool, len(selected))
+	for _, c := range selected {
+		names[c.Name()] = true
+	}
+
+	var unselected []Check
+	for _, c := range checks {
+		if !names[c.Name()] {
+			unselected = append(unselected, c)
+		}
+	}
+	return unselected
+}
+
+// leveledCheck overrides the level of a check.
+type leveledCheck struct {
+	Check
+	level string
+}
+
+var (
+	_ SerialCheck       = leveledCheck{}
+	_ RequirementsCheck = leveledCheck{}
+)
+
+func (c leveledCheck) Metadata() Metadata {
+	m := c.Check.Metadata()
+	m.Level = c.level
+	return m
+}
+
+func (c leveledCheck) RequiresSerialExecution() bool {
+	return RequiresSerialExecution(c.Check)
+}
+
+func (c leveledCheck) Requirements() Requirement {
+	return RequirementsOf(c.Check)
+}
+
+func isLevel(level string) bool {
+	switch strings.ToLower(level) {
+	case LevelBest, LevelWarn, LevelOptional:
+		return true
+	}
+	return false
+}
+
+func nameSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[strings.ToLower(name)] = true
+	}
+	return set
+}
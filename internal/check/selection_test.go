@@ -0,0 +1,88 @@
+package check
+
+import (
+	"context"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+)
+
+// serialTestCheck requests to be run by itself, and needs a cluster.
+type serialTestCheck struct {
+	Check
+}
+
+func (serialTestCheck) RequiresSerialExecution() bool { return true }
+
+func (serialTestCheck) Requirements() Requirement { return RequiresCluster }
+
+var _ = Describe("Check selection", func() {
+	var checks []Check
+
+	newCheck := func(name, level string) Check {
+		return NewGenericCheck(
+			name,
+			func(context.Context, image.ImageReference) (bool, error) { return true, nil },
+			Metadata{Level: level},
+			HelpText{},
+		)
+	}
+
+	names := func(checks []Check) []string {
+		n := make([]string, 0, len(checks))
+		for _, c := range checks {
+			n = append(n, c.Name())
+		}
+		return n
+	}
+
+	BeforeEach(func() {
+		checks = []Check{
+			newCheck("First", LevelBest),
+			newCheck("Second", LevelBest),
+			serialTestCheck{newCheck("Third", LevelBest)},
+		}
+	})
+
+	It("should not change checks when empty", func() {
+		s := Selection{}
+		Expect(s.IsZero()).To(BeTrue())
+		selected, err := s.Apply(checks)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(selected).To(Equal(checks))
+	})
+
+	It("should include and exclude checks, ignoring case", func() {
+		selected, err := Selection{Include: []string{"third", "First", "Second"}, Exclude: []string{"SECOND"}}.Apply(checks)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(names(selected)).To(Equal([]string{"First", "Third"}))
+	})
+
+	It("should override levels and preserve optional interfaces", func() {
+		selected, err := Selection{Levels: map[string]string{"third": "warn", "first": "best"}}.Apply(checks)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(selected[0]).To(Equal(checks[0]))
+		Expect(selected[2].Metadata().Level).To(Equal(LevelWarn))
+		Expect(RequiresSerialExecution(selected[2])).To(BeTrue())
+		Expect(RequirementsOf(selected[2])).To(Equal(RequiresCluster))
+		Expect(RequirementsOf(WithLevel(checks[0], LevelWarn))).To(Equal(RequiresAll))
+		Expect(LevelOverrides(selected)).To(Equal(map[string]string{"Third": LevelWarn}))
+	})
+
+	It("should reject unknown checks", func() {
+		_, err := Selection{Exclude: []string{"Fourth"}}.Apply(checks)
+		Expect(err).To(MatchError(ContainSubstring("Fourth")))
+	})
+
+	It("should reject unknown levels", func() {
+		_, err := Selection{Levels: map[string]string{"First": "fatal"}}.Apply(checks)
+		Expect(err).To(MatchError(ContainSubstring("fatal")))
+	})
+
+	It("should reject an empty selection", func() {
+		_, err := Selection{Exclude: []string{"First", "Second", "Third"}}.Apply(checks)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,33 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MissingPrerequisiteError is returned by Validate when the image lacks something
+// the check needs in order to apply, e.g. a registry to list tags from. The engine
+// records the check as skipped rather than errored.
+type MissingPrerequisiteError struct {
+	// Reason describes what is missing.
+	Reason string
+}
+
+func (e *MissingPrerequisiteError) Error() string {
+	return fmt.Sprintf("check not applicable: %s", e.Reason)
+}
+
+// MissingPrerequisite returns a *MissingPrerequisiteError for reason.
+func MissingPrerequisite(reason string) error {
+	return &MissingPrerequisiteError{Reason: reason}
+}
+
+// IsMissingPrerequisite returns the reason a check was not applicable, and true,
+// if err is or wraps a *MissingPrerequisiteError.
+func IsMissingPrerequisite(err error) (string, bool) {
+	var mpe *MissingPrerequisiteError
+	if errors.As(err, &mpe) {
+		return mpe.Reason, true
+	}
+	return "", false
+}
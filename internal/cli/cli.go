@@ -11,6 +11,7 @@ import (
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
@@ -77,6 +78,11 @@ func RunPreflight(
 	}
 
 	if cfg.SubmitResults {
+		// Only results of the full policy can be submitted.
+		if results.Partial {
+			return fmt.Errorf("%w: run all checks of the policy at their default levels", preflighterr.ErrPartialResultsNotSubmittable)
+		}
+
 		if err := rs.Submit(ctx); err != nil {
 			return err
 		}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
@@ -136,6 +137,22 @@ var _ = Describe("CLI Library function", func() {
 					Expect(string(contents)).To(ContainSubstring("Results are not being sent for submission"))
 				})
 
+				It("Should refuse to submit partial results", func() {
+					c := CheckConfig{
+						SubmitResults: true,
+					}
+
+					err := RunPreflight(testcontext, func(ctx context.Context) (certification.Results, error) {
+						return certification.Results{
+							TestedImage:   "testSubmission",
+							PassedOverall: true,
+							Checks:        []string{"testSubmission"},
+							Partial:       true,
+						}, nil
+					}, c, testFormatter, &runtime.ResultWriterFile{}, &badResultSubmitter{"should not be called"})
+					Expect(err).To(MatchError(preflighterr.ErrPartialResultsNotSubmittable))
+				})
+
 				It("Should return an error if the submitter is unable to submit", func() {
 					c := CheckConfig{
 						SubmitResults: true,
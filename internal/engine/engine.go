@@ -4,27 +4,32 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
-	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"net/http"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"regexp"
-	"sort"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/events"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/basecatalog"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layercache"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/openshift"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/operatorsdk"
@@ -33,14 +38,13 @@ import (
 	containerpol "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy/container"
 	operatorpol "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy/operator"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
-	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/sbom"
 
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
 	cranev1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/cache"
-	"github.com/google/go-containerregistry/pkg/v1/mutate"
 )
 
 // New creates a new CraneEngine from the passed params
@@ -49,6 +53,11 @@ func New(ctx context.Context,
 	kubeconfig []byte,
 	cfg runtime.Config,
 ) (craneEngine, error) {
+	sbomFormats, err := sbom.ParseFormats(cfg.SBOMFormats)
+	if err != nil {
+		return craneEngine{}, err
+	}
+
 	return craneEngine{
 		kubeconfig:         kubeconfig,
 		dockerConfig:       cfg.DockerConfig,
@@ -59,6 +68,12 @@ func New(ctx context.Context,
 		platform:           cfg.Platform,
 		insecure:           cfg.Insecure,
 		manifestListDigest: cfg.ManifestListDigest,
+		parallelism:        cfg.Parallelism,
+		cacheDir:           cfg.CacheDir,
+		cacheMaxSize:       cfg.CacheMaxSize,
+		extractionLimits:   cfg.ExtractionLimits.WithDefaults(),
+		checkTimeouts:      cfg.CheckTimeouts,
+		sbomFormats:        sbomFormats,
 	}, nil
 }
 
@@ -92,16 +107,38 @@ type craneEngine struct {
 	// ManifestListDigest is the sha256 digest for the manifest list
 	manifestListDigest string
 
+	// Parallelism is the maximum number of checks to run concurrently.
+	parallelism int
+
+	// CacheDir is the persistent layer cache to use. If empty, layers are
+	// cached in a temporary directory for the duration of the run.
+	cacheDir string
+
+	// CacheMaxSize is the size, in bytes, the persistent layer cache is
+	// pruned to after the run. Zero leaves the cache unbounded.
+	cacheMaxSize int64
+
+	// ExtractionLimits bounds how much of the image is extracted to disk.
+	// Zero values use runtime.DefaultExtractionLimits, so a limit cannot be
+	// disabled.
+	extractionLimits runtime.ExtractionLimits
+
+	// CheckTimeouts bounds how long each check may run.
+	checkTimeouts runtime.CheckTimeouts
+
+	// TimedOutCheckGracePeriod is how long a check that timed out is waited
+	// for to return before the run is aborted. If zero,
+	// defaultTimedOutCheckGracePeriod is used.
+	timedOutCheckGracePeriod time.Duration
+
+	// SBOMFormats are the formats the image's SBOM is written in. If empty,
+	// no SBOM is written.
+	sbomFormats []sbom.Format
+
 	imageRef image.ImageReference
 	results  certification.Results
 }
 
-func export(img cranev1.Image, w io.Writer) error {
-	fs := mutate.Extract(img)
-	_, err := io.Copy(w, fs)
-	return err
-}
-
 func (c *craneEngine) CranePlatform() string {
 	return c.platform
 }
@@ -119,66 +156,111 @@ var _ option.CraneConfig = &craneEngine{}
 func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 	logger := logr.FromContextOrDiscard(ctx)
 	logger.Info("target image", "image", c.image)
-
-	// pull the image and save to fs
-	logger.V(log.DBG).Info("pulling image from target registry")
-	options := option.GenerateCraneOptions(ctx, c)
-	img, err := crane.Pull(c.image, options...)
-	if err != nil {
-		return fmt.Errorf("failed to pull remote container: %v", err)
-	}
-
-	// create tmpdir to receive extracted fs
-	tmpdir, err := os.MkdirTemp(os.TempDir(), "preflight-*")
-	if err != nil {
-		return fmt.Errorf("failed to create temporary directory: %v", err)
-	}
-	logger.V(log.DBG).Info("created temporary directory", "path", tmpdir)
-	defer func() {
-		if err := os.RemoveAll(tmpdir); err != nil {
-			logger.Error(err, "unable to clean up tmpdir", "tempDir", tmpdir)
+	ctx = events.ContextWithArtifactEvents(ctx)
+
+	events.Emit(ctx, events.PullStarted{Image: c.image})
+	var img cranev1.Image
+	var localSource *image.LocalSource
+	var err error
+	if image.IsLocalSource(c.image) {
+		// read the image from the local filesystem
+		logger.V(log.DBG).Info("reading image from local source")
+		localSource, err = image.OpenLocalSource(c.image)
+		if err != nil {
+			return fmt.Errorf("failed to open local image source: %v", err)
 		}
-	}()
+		defer func() {
+			if err := localSource.Close(); err != nil {
+				logger.Error(err, "unable to clean up local image source")
+			}
+		}()
 
-	imageTarPath := path.Join(tmpdir, "cache")
-	if err := os.Mkdir(imageTarPath, 0o755); err != nil {
-		return fmt.Errorf("failed to create cache directory: %s: %v", imageTarPath, err)
+		img, err = localSource.Image(c.platform)
+		if err != nil {
+			return fmt.Errorf("failed to load local container: %v", err)
+		}
+	} else {
+		// pull the image and save to fs
+		logger.V(log.DBG).Info("pulling image from target registry")
+		options := option.GenerateCraneOptions(ctx, c)
+		img, err = crane.Pull(c.image, options...)
+		if err != nil {
+			return fmt.Errorf("failed to pull remote container: %v", err)
+		}
 	}
+	events.Emit(ctx, pullFinished(c.image, img))
+
+	// Only download and extract the image's layers if a check reads them.
+	requirements := c.requirements()
+	var containerFSPath string
+	var files *image.FileIndex
+	if requirements.Has(check.RequiresFilesystem) {
+		// create tmpdir to receive extracted fs
+		tmpdir, err := os.MkdirTemp(os.TempDir(), "preflight-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %v", err)
+		}
+		logger.V(log.DBG).Info("created temporary directory", "path", tmpdir)
+		defer func() {
+			if err := os.RemoveAll(tmpdir); err != nil {
+				logger.Error(err, "unable to clean up tmpdir", "tempDir", tmpdir)
+			}
+		}()
 
-	img = cache.Image(img, cache.NewFilesystemCache(imageTarPath))
-
-	containerFSPath := path.Join(tmpdir, "fs")
-	if err := os.Mkdir(containerFSPath, 0o755); err != nil {
-		return fmt.Errorf("failed to create container expansion directory: %s: %v", containerFSPath, err)
-	}
+		if c.cacheDir != "" {
+			// share layers with other runs through the persistent cache
+			logger.V(log.DBG).Info("using persistent layer cache", "path", c.cacheDir)
+			layerCache, err := layercache.Open(c.cacheDir, layercache.WithMaxSize(c.cacheMaxSize))
+			if err != nil {
+				return fmt.Errorf("failed to open layer cache: %v", err)
+			}
+			defer func() {
+				if err := layerCache.Close(); err != nil {
+					logger.Error(err, "unable to prune layer cache", "path", c.cacheDir)
+				}
+			}()
 
-	// export/flatten, and extract
-	logger.V(log.DBG).Info("exporting and flattening image")
-	r, w := io.Pipe()
-	go func() {
-		logger.V(log.DBG).Info("writing container filesystem", "outputDirectory", containerFSPath)
+			img = cache.Image(img, layerCache)
+		} else {
+			imageTarPath := path.Join(tmpdir, "cache")
+			if err := os.Mkdir(imageTarPath, 0o755); err != nil {
+				return fmt.Errorf("failed to create cache directory: %s: %v", imageTarPath, err)
+			}
 
-		// Close the writer with any errors encountered during
-		// extraction. These errors will be returned by the reader end
-		// on subsequent reads. If err == nil, the reader will return
-		// EOF.
-		w.CloseWithError(export(img, w))
-	}()
+			img = cache.Image(img, cache.NewFilesystemCache(imageTarPath))
+		}
 
-	logger.V(log.DBG).Info("extracting container filesystem", "path", containerFSPath)
-	if err := untar(ctx, containerFSPath, r); err != nil {
-		return fmt.Errorf("failed to extract tarball: %v", err)
-	}
+		containerFSPath = path.Join(tmpdir, "fs")
+		if err := os.Mkdir(containerFSPath, 0o755); err != nil {
+			return fmt.Errorf("failed to create container expansion directory: %s: %v", containerFSPath, err)
+		}
 
-	// explicitly discarding from the reader for cases where there is data in the reader after it sends an EOF
-	_, err = io.Copy(io.Discard, r)
-	if err != nil {
-		return fmt.Errorf("failed to drain io reader: %v", err)
-	}
+		// export/flatten, and extract
+		logger.V(log.DBG).Info("exporting and flattening image")
+		r, w := io.Pipe()
+		go func() {
+			logger.V(log.DBG).Info("writing container filesystem", "outputDirectory", containerFSPath)
+
+			// Close the writer with any errors encountered during
+			// extraction. These errors will be returned by the reader end
+			// on subsequent reads. If err == nil, the reader will return
+			// EOF.
+			w.CloseWithError(export(img, w))
+		}()
+
+		logger.V(log.DBG).Info("extracting container filesystem", "path", containerFSPath)
+		files = image.NewFileIndex()
+		if err := untar(ctx, containerFSPath, r, c.extractionLimits, files); err != nil {
+			return fmt.Errorf("failed to extract tarball: %w", err)
+		}
 
-	reference, err := name.ParseReference(c.image)
-	if err != nil {
-		return fmt.Errorf("image uri could not be parsed: %v", err)
+		// explicitly discarding from the reader for cases where there is data in the reader after it sends an EOF
+		_, err = io.Copy(io.Discard, r)
+		if err != nil {
+			return fmt.Errorf("failed to drain io reader: %v", err)
+		}
+	} else {
+		logger.V(log.DBG).Info("no check requires the image filesystem, skipping layer download and extraction")
 	}
 
 	// store the image internals in the engine image reference to pass to validations.
@@ -186,23 +268,47 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 		ImageURI:           c.image,
 		ImageFSPath:        containerFSPath,
 		ImageInfo:          img,
-		ImageRegistry:      reference.Context().RegistryStr(),
-		ImageRepository:    reference.Context().RepositoryStr(),
-		ImageTagOrSha:      reference.Identifier(),
 		ManifestListDigest: c.manifestListDigest,
+		Files:              files,
+		Facts:              image.NewFacts(img, containerFSPath),
 	}
 
-	if err := writeCertImage(ctx, c.imageRef); err != nil {
-		return fmt.Errorf("could not write cert image: %v", err)
+	if localSource != nil {
+		if err := describeLocalSource(&c.imageRef, localSource); err != nil {
+			return err
+		}
+	} else {
+		reference, err := name.ParseReference(c.image)
+		if err != nil {
+			return fmt.Errorf("image uri could not be parsed: %v", err)
+		}
+		c.imageRef.ImageRegistry = reference.Context().RegistryStr()
+		c.imageRef.ImageRepository = reference.Context().RepositoryStr()
+		c.imageRef.ImageTagOrSha = reference.Identifier()
 	}
 
-	if !c.isScratch {
-		if err := writeRPMManifest(ctx, containerFSPath); err != nil {
-			return fmt.Errorf("could not write rpm manifest: %v", err)
+	// These artifacts are derived from the image's layers.
+	if requirements.Has(check.RequiresFilesystem) {
+		if err := writeCertImage(ctx, c.imageRef); err != nil {
+			return fmt.Errorf("could not write cert image: %v", err)
+		}
+
+		if !c.isScratch {
+			if err := writeRPMManifest(ctx, c.imageRef.ImageFacts()); err != nil {
+				return fmt.Errorf("could not write rpm manifest: %v", err)
+			}
+		} else {
+			logger.Info("skipping rpm manifest: scratch images are not expected to have an rpm database")
+		}
+
+		if !c.isBundle && len(c.sbomFormats) > 0 {
+			if err := writeSBOM(ctx, c.imageRef, c.sbomFormats); err != nil {
+				return fmt.Errorf("could not write sbom: %v", err)
+			}
 		}
 	}
 
-	if c.isBundle {
+	if c.isBundle && requirements.Has(check.RequiresCluster) {
 		// Record test cluster version
 		version, err := openshift.GetOpenshiftClusterVersion(ctx, c.kubeconfig)
 		if err != nil {
@@ -210,64 +316,40 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 		}
 		c.results.TestedOn = version
 	} else {
-		logger.V(log.DBG).Info("Container checks do not require a cluster. skipping cluster version check.")
+		logger.V(log.DBG).Info("Checks do not require a cluster. skipping cluster version check.")
 		c.results.TestedOn = runtime.UnknownOpenshiftClusterVersion()
 	}
 
 	// execute checks
-	logger.V(log.DBG).Info("executing checks")
-	for _, executedCheck := range c.checks {
-		logger := logger.WithValues("check", executedCheck.Name())
-		ctx := logr.NewContext(ctx, logger)
-		c.results.TestedImage = c.image
-
-		logger.V(log.DBG).Info("running check")
-		if executedCheck.Metadata().Level == check.LevelOptional || executedCheck.Metadata().Level == check.LevelWarn {
-			logger.Info(fmt.Sprintf("Check %s is not currently being enforced.", executedCheck.Name()))
-		}
-
-		// run the validation
-		checkStartTime := time.Now()
-		checkPassed, err := executedCheck.Validate(ctx, c.imageRef)
-		checkElapsedTime := time.Since(checkStartTime)
-
-		if err != nil {
-			logger.WithValues("result", "ERROR", "err", err.Error()).Info("check completed")
-			result := certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime}
-			c.results.Errors = appendUnlessOptional(c.results.Errors, *result.WithError(err))
-			continue
-		}
-
-		if !checkPassed {
-			// if a test doesn't pass but is of level warn include it in warning results, instead of failed results
-			if executedCheck.Metadata().Level == check.LevelWarn {
-				logger.WithValues("result", "WARNING").Info("check completed")
-				c.results.Warned = appendUnlessOptional(c.results.Warned, certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime})
-				continue
-			}
-			logger.WithValues("result", "FAILED").Info("check completed")
-			c.results.Failed = appendUnlessOptional(c.results.Failed, certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime})
-			continue
-		}
-
-		logger.WithValues("result", "PASSED").Info("check completed")
-		c.results.Passed = appendUnlessOptional(c.results.Passed, certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime})
+	logger.V(log.DBG).Info("executing checks", "parallelism", c.workers())
+	c.results.TestedImage = c.image
+	c.results.Checks = make([]string, 0, len(c.checks))
+	for _, chk := range c.checks {
+		c.results.Checks = append(c.results.Checks, chk.Name())
+	}
+	if overrides := check.LevelOverrides(c.checks); len(overrides) > 0 {
+		c.results.LevelOverrides = overrides
+	}
+	if err := c.runChecks(ctx); err != nil {
+		return err
 	}
 
-	if len(c.results.Errors) > 0 || len(c.results.Failed) > 0 {
+	if len(c.results.Errors) > 0 || len(c.results.Failed) > 0 || len(c.results.TimedOut) > 0 {
 		c.results.PassedOverall = false
 	} else {
 		c.results.PassedOverall = true
 	}
 
 	if c.isBundle { // for operators:
-		// hash the contents of the bundle.
-		md5sum, err := generateBundleHash(ctx, c.imageRef.ImageFSPath)
-		if err != nil {
-			logger.Error(err, "could not generate bundle hash")
+		// hash the contents of the bundle, if it was extracted.
+		if containerFSPath != "" {
+			hash, err := generateBundleHash(ctx, c.imageRef.ImageFSPath)
+			if err != nil {
+				logger.Error(err, "could not generate bundle hash")
+			}
+			c.results.CertificationHash = hash
 		}
-		c.results.CertificationHash = md5sum
-	} else { // for containers:
+	} else if !c.imageRef.Local { // for containers:
 		// Inform the user about the sha/tag binding.
 
 		// By this point, we should have already resolved the digest so
@@ -286,11 +368,314 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 	return nil
 }
 
-func appendUnlessOptional(results []certification.Result, result certification.Result) []certification.Result {
-	if result.Check.Metadata().Level == "optional" {
-		return results
+// pullFinished describes img for the PullFinished event. Errors are ignored, as
+// they are reported when the image is read later.
+func pullFinished(imageURI string, img cranev1.Image) events.PullFinished {
+	event := events.PullFinished{Image: imageURI}
+	if digest, err := img.Digest(); err == nil {
+		event.Digest = digest.String()
+	}
+	if manifest, err := img.Manifest(); err == nil {
+		for _, layer := range manifest.Layers {
+			event.Size += layer.Size
+		}
+	}
+	return event
+}
+
+// checkOutcome is the result of running a single check, prior to it being
+// sorted into certification.Results.
+type checkOutcome struct {
+	result certification.Result
+	status string
+}
+
+// Possible values for checkOutcome.status.
+const (
+	statusPassed  = "PASSED"
+	statusFailed  = "FAILED"
+	statusWarning = "WARNING"
+	statusError   = "ERROR"
+	statusTimeout = "TIMED OUT"
+	statusSkipped = "SKIPPED"
+)
+
+// errCheckTimedOut is the cause of the cancellation of a check's context when
+// its timeout expires.
+var errCheckTimedOut = errors.New("check timed out")
+
+// errCheckStillRunning is returned when a check that timed out does not return
+// within the grace period, and the run cannot safely continue.
+var errCheckStillRunning = errors.New("check still running after timing out")
+
+// defaultTimedOutCheckGracePeriod is how long a check that timed out is waited
+// for to return before the run is aborted.
+const defaultTimedOutCheckGracePeriod = 30 * time.Second
+
+// requirements returns the inputs needed by all checks to be run.
+func (c *craneEngine) requirements() check.Requirement {
+	var requirements check.Requirement
+	for _, chk := range c.checks {
+		requirements |= check.RequirementsOf(chk)
+	}
+	return requirements
+}
+
+// workers returns the number of checks that may run concurrently.
+func (c *craneEngine) workers() int {
+	if c.parallelism < 1 {
+		return 1
 	}
-	return append(results, result)
+	return c.parallelism
+}
+
+// runChecks executes all checks, running up to c.workers() checks at a time.
+// Checks that require serial execution wait for all previously scheduled checks
+// to complete, and run by themselves. Outcomes are recorded in the order the checks
+// were provided, regardless of the order in which they complete.
+//
+// A check that times out without honoring cancellation keeps its worker until it
+// returns. Serial checks are only started once every previous check has returned,
+// and the next check only once the serial check has returned. If a timed out
+// check does not return within the grace period, the run fails, as running other
+// checks alongside it could leave the cluster in an unknown state.
+func (c *craneEngine) runChecks(ctx context.Context) error {
+	outcomes := make([]checkOutcome, len(c.checks))
+	returned := make([]chan struct{}, len(c.checks))
+	sem := make(chan struct{}, c.workers())
+	var wg sync.WaitGroup
+
+	// stillRunning records the first check that did not return within the
+	// grace period after timing out, which aborts the run.
+	var mu sync.Mutex
+	var stillRunning error
+	abort := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if stillRunning == nil {
+			stillRunning = err
+		}
+	}
+	aborted := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return stillRunning
+	}
+
+	for i, executedCheck := range c.checks {
+		returned[i] = make(chan struct{})
+		if aborted() != nil {
+			break
+		}
+
+		if check.RequiresSerialExecution(executedCheck) {
+			wg.Wait()
+			if err := aborted(); err != nil {
+				return err
+			}
+			outcomes[i] = c.runCheck(ctx, executedCheck, returned[i])
+			if err := c.awaitReturned(c.checks[i:i+1], returned[i:i+1]); err != nil {
+				return err
+			}
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			outcomes[i] = c.runCheck(ctx, executedCheck, returned[i])
+			// Keep the slot until the check returns, even after it timed out,
+			// but no longer than the grace period.
+			if err := c.awaitReturned(c.checks[i:i+1], returned[i:i+1]); err != nil {
+				abort(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := aborted(); err != nil {
+		return err
+	}
+
+	for _, outcome := range outcomes {
+		switch outcome.status {
+		case statusError:
+			c.results.Errors = append(c.results.Errors, outcome.result)
+		case statusTimeout:
+			c.results.TimedOut = append(c.results.TimedOut, outcome.result)
+		case statusWarning:
+			c.results.Warned = append(c.results.Warned, outcome.result)
+		case statusFailed:
+			c.results.Failed = append(c.results.Failed, outcome.result)
+		case statusSkipped:
+			c.results.Skipped = append(c.results.Skipped, outcome.result)
+		default:
+			c.results.Passed = append(c.results.Passed, outcome.result)
+		}
+	}
+	return nil
+}
+
+// gracePeriod returns how long a check that timed out is waited for to return
+// before its slot is given to another check, or a serial check may run.
+func (c *craneEngine) gracePeriod() time.Duration {
+	if c.timedOutCheckGracePeriod <= 0 {
+		return defaultTimedOutCheckGracePeriod
+	}
+	return c.timedOutCheckGracePeriod
+}
+
+// awaitReturned waits for the Validate method of each of checks to return, as
+// signaled by closing the matching channel of returned. Checks that did not
+// time out have already returned. An error is returned if any is still running
+// after the grace period.
+func (c *craneEngine) awaitReturned(checks []check.Check, returned []chan struct{}) error {
+	timer := time.NewTimer(c.gracePeriod())
+	defer timer.Stop()
+	for i, r := range returned {
+		select {
+		case <-r:
+		case <-timer.C:
+			return fmt.Errorf("%w: %s did not return within %s of timing out", errCheckStillRunning, checks[i].Name(), c.gracePeriod())
+		}
+	}
+	return nil
+}
+
+// runCheck validates the image against executedCheck, emitting CheckStarted
+// and CheckFinished events. The outcome of optional checks is recorded as
+// skipped, as it is not enforced.
+func (c *craneEngine) runCheck(ctx context.Context, executedCheck check.Check, returned chan<- struct{}) checkOutcome {
+	events.Emit(ctx, events.CheckStarted{Name: executedCheck.Name()})
+
+	outcome := c.evaluateCheck(ctx, executedCheck, returned)
+	if outcome.status != statusSkipped && executedCheck.Metadata().Level == check.LevelOptional {
+		outcome.result.SkipReason = certification.SkipReasonOptionalLevel
+		outcome.result.SkipMessage = fmt.Sprintf("optional checks are not enforced (outcome: %s)", outcome.status)
+		outcome.status = statusSkipped
+	}
+
+	events.Emit(ctx, events.CheckFinished{Name: executedCheck.Name(), Status: outcome.status, Result: outcome.result})
+	return outcome
+}
+
+// evaluateCheck validates the image against executedCheck. The check receives a
+// logger in its context that attributes its log lines to the check. returned is
+// closed once the check's Validate method returns.
+func (c *craneEngine) evaluateCheck(ctx context.Context, executedCheck check.Check, returned chan<- struct{}) checkOutcome {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("check", executedCheck.Name())
+	ctx = logr.NewContext(ctx, logger)
+
+	logger.V(log.DBG).Info("running check")
+	if executedCheck.Metadata().Level == check.LevelOptional || executedCheck.Metadata().Level == check.LevelWarn {
+		logger.Info(fmt.Sprintf("Check %s is not currently being enforced.", executedCheck.Name()))
+	}
+
+	timeout := c.checkTimeouts.For(executedCheck.Name())
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, errCheckTimedOut)
+		defer cancel()
+	}
+
+	findings := &check.Findings{}
+	ctx = check.ContextWithFindings(ctx, findings)
+
+	// run the validation
+	checkStartTime := time.Now()
+	checkPassed, err := validate(ctx, executedCheck, c.imageRef, returned)
+	checkElapsedTime := time.Since(checkStartTime)
+
+	result := certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime, Findings: findings.List()}
+
+	if errors.Is(err, errCheckTimedOut) {
+		logger.WithValues("result", statusTimeout, "timeout", timeout.String()).Info("check completed")
+		return checkOutcome{result: *result.WithError(fmt.Errorf("%w after %s", errCheckTimedOut, timeout)), status: statusTimeout}
+	}
+
+	if reason, ok := check.IsMissingPrerequisite(err); ok {
+		logger.WithValues("result", statusSkipped, "reason", reason).Info("check completed")
+		result.SkipReason = certification.SkipReasonMissingPrerequisite
+		result.SkipMessage = reason
+		return checkOutcome{result: result, status: statusSkipped}
+	}
+
+	if err != nil {
+		logger.WithValues("result", statusError, "err", err.Error()).Info("check completed")
+		return checkOutcome{result: *result.WithError(err), status: statusError}
+	}
+
+	if !checkPassed {
+		// if a test doesn't pass but is of level warn include it in warning results, instead of failed results
+		if executedCheck.Metadata().Level == check.LevelWarn {
+			logger.WithValues("result", statusWarning).Info("check completed")
+			return checkOutcome{result: result, status: statusWarning}
+		}
+		logger.WithValues("result", statusFailed).Info("check completed")
+		return checkOutcome{result: result, status: statusFailed}
+	}
+
+	logger.WithValues("result", statusPassed).Info("check completed")
+	return checkOutcome{result: result, status: statusPassed}
+}
+
+// validate runs executedCheck. If ctx is cancelled because the check timed out,
+// errCheckTimedOut is returned without waiting for the check to return, so that
+// a check that does not honor cancellation cannot stall the run. It is left to
+// finish in the background, and its outcome is discarded. returned is closed
+// once the check returns, so that the caller can wait for it.
+func validate(ctx context.Context, executedCheck check.Check, imageRef image.ImageReference, returned chan<- struct{}) (bool, error) {
+	type validation struct {
+		passed bool
+		err    error
+	}
+
+	done := make(chan validation, 1)
+	go func() {
+		defer close(returned)
+		passed, err := executedCheck.Validate(ctx, imageRef)
+		done <- validation{passed: passed, err: err}
+	}()
+
+	select {
+	case v := <-done:
+		if v.err != nil && errors.Is(context.Cause(ctx), errCheckTimedOut) {
+			return false, errCheckTimedOut
+		}
+		return v.passed, v.err
+	case <-ctx.Done():
+		if errors.Is(context.Cause(ctx), errCheckTimedOut) {
+			return false, errCheckTimedOut
+		}
+		// Cancellation of the run is left to the check to handle.
+		v := <-done
+		return v.passed, v.err
+	}
+}
+
+// describeLocalSource populates the registry-related fields of imageRef for an
+// image read from src. Local sources only carry a registry and repository if
+// they were given a fully qualified reference. Otherwise, the tag (or the
+// image digest if no tag was given) is used to identify the image.
+func describeLocalSource(imageRef *image.ImageReference, src *image.LocalSource) error {
+	imageRef.Local = true
+
+	if reference, ok := src.NamedReference(); ok {
+		imageRef.ImageRegistry = reference.Context().RegistryStr()
+		imageRef.ImageRepository = reference.Context().RepositoryStr()
+	}
+
+	imageRef.ImageTagOrSha = src.Tag()
+	if imageRef.ImageTagOrSha == "" {
+		digest, err := imageRef.ImageInfo.Digest()
+		if err != nil {
+			return fmt.Errorf("could not get image digest: %v", err)
+		}
+		imageRef.ImageTagOrSha = digest.String()
+	}
+
+	return nil
 }
 
 // tagDigestBindingInfo emits a log line describing tag and digest binding semantics.
@@ -318,51 +703,23 @@ func tagDigestBindingInfo(providedIdentifier string, resolvedDigest string) (msg
 
 func generateBundleHash(ctx context.Context, bundlePath string) (string, error) {
 	logger := logr.FromContextOrDiscard(ctx)
-	files := make(map[string]string)
-	fileSystem := os.DirFS(bundlePath)
-
-	hashBuffer := bytes.Buffer{}
-
-	_ = fs.WalkDir(fileSystem, ".", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return fmt.Errorf("could not read bundle directory: %s: %w", path, err)
-		}
-		if d.Name() == "Dockerfile" {
-			return nil
-		}
-		if d.IsDir() {
-			return nil
-		}
-		filebytes, err := fs.ReadFile(fileSystem, path)
-		if err != nil {
-			return fmt.Errorf("could not read file: %s: %w", path, err)
-		}
-		md5sum := fmt.Sprintf("%x", md5.Sum(filebytes))
-		files[md5sum] = fmt.Sprintf("./%s", path)
-		return nil
-	})
 
-	keys := make([]string, 0, len(files))
-	for k := range files {
-		keys = append(keys, k)
-	}
-
-	sort.Strings(keys)
-	for _, k := range keys {
-		hashBuffer.WriteString(fmt.Sprintf("%s  %s\n", k, files[k]))
+	hash, err := bundle.HashDir(bundlePath)
+	if err != nil {
+		return "", err
 	}
 
 	artifactsWriter := artifacts.WriterFromContext(ctx)
 	if artifactsWriter != nil {
-		_, err := artifactsWriter.WriteFile("hashes.txt", &hashBuffer)
+		_, err := artifactsWriter.WriteFile("hashes.txt", bytes.NewReader(hash.Manifest()))
 		if err != nil {
 			return "", fmt.Errorf("could not write hash file to artifacts dir: %w", err)
 		}
 	}
 
-	sum := fmt.Sprintf("%x", md5.Sum(hashBuffer.Bytes()))
+	sum := hash.String()
 
-	logger.V(log.DBG).Info("md5 sum", "md5sum", sum)
+	logger.V(log.DBG).Info("bundle hash", "hash", sum)
 
 	return sum, nil
 }
@@ -372,18 +729,39 @@ func (c *craneEngine) Results(ctx context.Context) certification.Results {
 	return c.results
 }
 
-// Untar takes a destination path and a reader; a tar reader loops over the tarfile
-// creating the file structure at 'dst' along the way, and writing any files
-func untar(ctx context.Context, dst string, r io.Reader) error {
+// extractionProgressInterval is the number of archive entries between
+// ExtractionProgress events.
+const extractionProgressInterval = 1000
+
+// untar extracts the tarball in r into dst, recording the metadata of every
+// entry in files. Entries that would be written outside of dst are skipped.
+// An *errors.ExtractionLimitError is returned if the archive exceeds any of
+// the provided limits.
+//
+// Files are written with the permission bits from the archive, but ownership,
+// setuid/setgid/sticky bits and extended attributes are only recorded in files,
+// so that extraction does not require privileges. Device nodes and FIFOs are
+// recorded but not created.
+func untar(ctx context.Context, dst string, r io.Reader, limits runtime.ExtractionLimits, files *image.FileIndex) error {
 	logger := logr.FromContextOrDiscard(ctx)
 	tr := tar.NewReader(r)
 
+	var entries int
+	var extracted int64
+	// Hard links are created once all other entries are extracted, since
+	// their targets may come from a lower layer later in the stream.
+	var hardLinks []*tar.Header
+
 	for {
 		header, err := tr.Next()
 
 		switch {
-		// if no more files are found return
+		// if no more files are found, create any deferred hard links and return
 		case err == io.EOF:
+			for _, header := range hardLinks {
+				linkHardLink(ctx, dst, header)
+			}
+			events.Emit(ctx, events.ExtractionProgress{Entries: entries, Bytes: extracted, Done: true})
 			return nil
 
 		// return any other error
@@ -395,12 +773,29 @@ func untar(ctx context.Context, dst string, r io.Reader) error {
 			continue
 		}
 
+		entries++
+		if limits.MaxEntries > 0 && entries > limits.MaxEntries {
+			return &preflighterr.ExtractionLimitError{Limit: "entry count", Max: int64(limits.MaxEntries), Entry: header.Name}
+		}
+		if entries%extractionProgressInterval == 0 {
+			events.Emit(ctx, events.ExtractionProgress{Entries: entries, Bytes: extracted})
+		}
+
 		// the target location where the dir/file should be created
-		target := filepath.Join(dst, header.Name)
+		target, ok := pathWithin(dst, header.Name)
+		if !ok {
+			logger.V(log.DBG).Info("Error processing archive entry. Entry would be written outside of the image archive. Skipping this entry", "name", header.Name)
+			continue
+		}
 
-		// the following switch could also be done using fi.Mode(), not sure if there
-		// a benefit of using one vs. the other.
-		// fi := header.FileInfo()
+		if header.Typeflag == tar.TypeLink {
+			if _, ok := pathWithin(dst, header.Linkname); !ok {
+				logger.V(log.DBG).Info("Error processing hard link. Hard link would reach outside of the image archive. Skipping this link", "link", header.Name, "linkedTo", header.Linkname)
+				continue
+			}
+		}
+
+		files.Add(image.FileInfoFromHeader(header, layerFromHeader(header)))
 
 		// check the file type
 		switch header.Typeflag {
@@ -414,6 +809,14 @@ func untar(ctx context.Context, dst string, r io.Reader) error {
 
 		// if it's a file create it
 		case tar.TypeReg:
+			if limits.MaxFileSize > 0 && header.Size > limits.MaxFileSize {
+				return &preflighterr.ExtractionLimitError{Limit: "file size", Max: limits.MaxFileSize, Entry: header.Name}
+			}
+			extracted += header.Size
+			if limits.MaxSize > 0 && extracted > limits.MaxSize {
+				return &preflighterr.ExtractionLimitError{Limit: "total size", Max: limits.MaxSize, Entry: header.Name}
+			}
+
 			// If the file's parent dir doesn't exist, create it.
 			dirname := filepath.Dir(target)
 			if _, err := os.Stat(dirname); err != nil {
@@ -421,12 +824,13 @@ func untar(ctx context.Context, dst string, r io.Reader) error {
 					return err
 				}
 			}
-			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode).Perm())
 			if err != nil {
 				return err
 			}
 
-			// copy over contents
+			// copy over contents. The tar reader never returns more than
+			// header.Size bytes for an entry.
 			if _, err := io.Copy(f, tr); err != nil {
 				f.Close()
 				return err
@@ -443,7 +847,7 @@ func untar(ctx context.Context, dst string, r io.Reader) error {
 			fullName := filepath.Join(dst, nobaseName)
 			// Safeguard for cases where we're trying to link to something
 			// outside of our base fs.
-			if !strings.HasPrefix(fullLinkname, dst) {
+			if !isWithin(dst, fullLinkname) {
 				logger.V(log.DBG).Info("Error processing symlink. Symlink would reach outside of the image archive. Skipping this link", "link", header.Name, "linkedTo", header.Linkname, "resolvedTo", fullLinkname)
 				continue
 			}
@@ -460,22 +864,43 @@ func untar(ctx context.Context, dst string, r io.Reader) error {
 				continue
 			}
 		case tar.TypeLink:
-			// We assume hard links will not contain relative pathing in the archive.
-			original := filepath.Join(dst, header.Linkname)
-			// Create the new link's directory if it doesn't exist.
-			dirname := filepath.Dir(target)
-			if _, err := os.Stat(dirname); err != nil {
-				if err := os.MkdirAll(dirname, 0o755); err != nil {
-					return err
-				}
-			}
-			err := os.Link(original, target)
-			if err != nil {
-				logger.V(log.DBG).Info(fmt.Sprintf("Error creating hard link: %s. Ignoring.", header.Name), "link", target, "linkedTo", original, "reason", err)
-				continue
-			}
+			hardLinks = append(hardLinks, header)
+		}
+	}
+}
+
+// linkHardLink creates the hard link described by header. Hard link targets
+// are relative to the root of the archive, so absolute targets are treated as
+// relative to dst. Targets are validated by untar before this is called.
+func linkHardLink(ctx context.Context, dst string, header *tar.Header) {
+	logger := logr.FromContextOrDiscard(ctx)
+	target := filepath.Join(dst, header.Name)
+	original := filepath.Join(dst, header.Linkname)
+
+	// Create the new link's directory if it doesn't exist.
+	dirname := filepath.Dir(target)
+	if _, err := os.Stat(dirname); err != nil {
+		if err := os.MkdirAll(dirname, 0o755); err != nil {
+			logger.V(log.DBG).Info(fmt.Sprintf("Error creating hard link: %s. Ignoring.", header.Name), "link", target, "linkedTo", original, "reason", err)
+			return
 		}
 	}
+	if err := os.Link(original, target); err != nil {
+		logger.V(log.DBG).Info(fmt.Sprintf("Error creating hard link: %s. Ignoring.", header.Name), "link", target, "linkedTo", original, "reason", err)
+	}
+}
+
+// pathWithin joins name onto dst, treating absolute names as relative to dst.
+// The boolean is false if the result would be outside of dst.
+func pathWithin(dst, name string) (string, bool) {
+	target := filepath.Join(dst, name)
+	return target, isWithin(dst, target)
+}
+
+// isWithin returns true if path is dst or a path beneath it.
+func isWithin(dst, path string) bool {
+	rel, err := filepath.Rel(dst, path)
+	return err == nil && (rel == "." || filepath.IsLocal(rel))
 }
 
 // resolveLinkPaths determines if oldname is an absolute path or a relative
@@ -505,7 +930,9 @@ func resolveLinkPaths(oldname, newname string) (string, string) {
 func writeCertImage(ctx context.Context, imageRef image.ImageReference) error {
 	logger := logr.FromContextOrDiscard(ctx)
 
-	config, err := imageRef.ImageInfo.ConfigFile()
+	facts := imageRef.ImageFacts()
+
+	config, err := facts.Config()
 	if err != nil {
 		return fmt.Errorf("failed to get image config file: %w", err)
 	}
@@ -530,28 +957,18 @@ func writeCertImage(ctx context.Context, imageRef image.ImageReference) error {
 		return fmt.Errorf("failed to get image size: %w", err)
 	}
 
-	labels := convertLabels(config.Config.Labels)
-	layerSizes := make([]pyxis.Layer, 0, len(config.RootFS.DiffIDs))
-	for _, diffid := range config.RootFS.DiffIDs {
-		layer, err := imageRef.ImageInfo.LayerByDiffID(diffid)
-		if err != nil {
-			return fmt.Errorf("could not get layer by diff id: %w", err)
-		}
-
-		uncompressed, err := layer.Uncompressed()
-		if err != nil {
-			return fmt.Errorf("could not get uncompressed layer: %w", err)
-		}
-		written, err := io.Copy(io.Discard, uncompressed)
-		if err != nil {
-			return fmt.Errorf("could not copy from layer: %w", err)
-		}
+	layers, err := facts.Layers()
+	if err != nil {
+		return fmt.Errorf("could not read image layers: %w", err)
+	}
 
-		pyxisLayer := pyxis.Layer{
-			LayerID: diffid.String(),
-			Size:    written,
-		}
-		layerSizes = append(layerSizes, pyxisLayer)
+	labels := convertLabels(config.Config.Labels)
+	layerSizes := make([]pyxis.Layer, 0, len(layers))
+	for _, layer := range layers {
+		layerSizes = append(layerSizes, pyxis.Layer{
+			LayerID: layer.DiffID.String(),
+			Size:    layer.Size,
+		})
 	}
 
 	manifestLayers := make([]string, 0, len(manifest.Layers))
@@ -627,9 +1044,9 @@ func getBgName(srcrpm string) string {
 	return strings.Join(parts[0:len(parts)-2], "-")
 }
 
-func writeRPMManifest(ctx context.Context, containerFSPath string) error {
+func writeRPMManifest(ctx context.Context, facts *image.Facts) error {
 	logger := logr.FromContextOrDiscard(ctx)
-	pkgList, err := rpm.GetPackageList(ctx, containerFSPath)
+	pkgList, err := facts.Packages(ctx)
 	if err != nil {
 		logger.Error(err, "could not get rpm list, continuing without it")
 	}
@@ -694,6 +1111,37 @@ func writeRPMManifest(ctx context.Context, containerFSPath string) error {
 	return nil
 }
 
+// writeSBOM writes the software bill of materials of the image in imgRef to
+// the artifacts, once for each of formats.
+func writeSBOM(ctx context.Context, imgRef image.ImageReference, formats []sbom.Format) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	artifactWriter := artifacts.WriterFromContext(ctx)
+	if artifactWriter == nil {
+		return nil
+	}
+
+	s, err := sbom.Collect(ctx, imgRef)
+	if err != nil {
+		return err
+	}
+
+	for _, format := range formats {
+		contents, err := s.Marshal(format)
+		if err != nil {
+			return fmt.Errorf("could not marshal %s sbom: %w", format, err)
+		}
+		fileName, err := artifactWriter.WriteFile(format.Filename(), bytes.NewReader(contents))
+		if err != nil {
+			return fmt.Errorf("failed to save file to artifacts directory: %w", err)
+		}
+
+		logger.V(log.TRC).Info("sbom written to disk", "format", format, "filename", fileName, "components", len(s.Components))
+	}
+
+	return nil
+}
+
 func sumLayerSizeBytes(layers []pyxis.Layer) int64 {
 	var sum int64
 	for _, layer := range layers {
@@ -754,58 +1202,197 @@ func InitializeOperatorChecks(ctx context.Context, p policy.Policy, cfg Operator
 // ContainerCheckConfig contains configuration relevant to an individual check's execution.
 type ContainerCheckConfig struct {
 	DockerConfig, PyxisAPIToken, CertificationProjectID, PyxisHost string
+	// RequiredArchitectures must be present in the manifest list of the image.
+	// Setting them enables the HasConsistentArchitectures check.
+	RequiredArchitectures []string
+	// ManifestListPlatforms are the platforms of the image's manifest list
+	// checked in the same run, each with its own checks. The manifest list is
+	// compared by the HasConsistentArchitectures check of the first of them.
+	ManifestListPlatforms []string
+	// AllowedCapabilities are the file capabilities executables may carry.
+	// Setting them enables the HasNoUnexpectedFileCapabilities check. If
+	// empty, containerpol.DefaultAllowedCapabilities are allowed.
+	AllowedCapabilities []string
+	// EnforceSecretsCheck fails the certification if secrets are found in the
+	// image, instead of reporting them as a warning. Setting it enables the
+	// HasNoEmbeddedSecrets check.
+	EnforceSecretsCheck bool
+	// AdvisoryDB is the OSV or CSAF file, or directory of them, the image's
+	// packages are matched against. Setting it enables the
+	// HasNoKnownVulnerabilities check.
+	AdvisoryDB string
+	// HardeningMinimum are the hardening properties binaries added to the
+	// image must have. Setting them enables the HasHardenedBinaries check. If
+	// empty, containerpol.DefaultHardeningMinimum is required.
+	HardeningMinimum []string
+	// BaseImageCatalog is the catalog of approved base images the BasedOnUbi
+	// check looks the image's layers up in. If empty, they are looked up in
+	// Pyxis.
+	BaseImageCatalog string
+	// Insecure allows reading the manifest list of the image from a registry
+	// without a trusted certificate.
+	Insecure bool
+	// CacheDir is the persistent layer cache checks reading layers of other
+	// images go through. If empty, they are not cached.
+	CacheDir string
+	// MetadataOnly leaves out the parts of checks that download layers.
+	MetadataOnly bool
+	// FIPSCheck adds the SupportsFIPSMode check to the policy, to back a
+	// claim that the image is FIPS compliant.
+	FIPSCheck bool
+	// EnabledChecks are the names of the OptionalContainerChecks to add to
+	// the policy. Names are not case-sensitive.
+	EnabledChecks []string
+}
+
+// OptionalContainerChecks are the checks that are only part of a container
+// policy when enabled. They report on properties of the image that
+// certification does not require, and an error in any of them would still
+// fail the run.
+var OptionalContainerChecks = []string{
+	"HasConsistentArchitectures",
+	"HasNoUnexpectedSetuidFiles",
+	"HasNoUnexpectedFileCapabilities",
+	"HasNoEmbeddedSecrets",
+	"SupportsArbitraryUID",
+	"HasResolvableEntrypoint",
+	"HasNoKnownVulnerabilities",
+	"HasHardenedBinaries",
+}
+
+// enabledChecks returns the lowercased names of the optional checks in names,
+// or an error if any is not one of OptionalContainerChecks.
+func enabledChecks(names []string) (map[string]bool, error) {
+	enabled := map[string]bool{}
+	for _, name := range names {
+		if !slices.ContainsFunc(OptionalContainerChecks, func(optional string) bool { return strings.EqualFold(optional, name) }) {
+			return nil, fmt.Errorf("unknown optional check %q: must be one of %s", name, strings.Join(OptionalContainerChecks, ", "))
+		}
+		enabled[strings.ToLower(name)] = true
+	}
+	return enabled, nil
+}
+
+// baseImageLayerChecker returns the catalog the BasedOnUbi check looks the
+// image's layers up in, a local file if cfg has one, or Pyxis otherwise.
+func baseImageLayerChecker(cfg ContainerCheckConfig) containerpol.LayerHashChecker {
+	if cfg.BaseImageCatalog != "" {
+		return basecatalog.NewLayerHashChecker(cfg.BaseImageCatalog)
+	}
+	return pyxis.NewPyxisClient(
+		cfg.PyxisHost,
+		cfg.PyxisAPIToken,
+		cfg.CertificationProjectID,
+		&http.Client{Timeout: 60 * time.Second})
 }
 
 // InitializeContainerChecks returns the appropriate checks for policy p given cfg.
 func InitializeContainerChecks(ctx context.Context, p policy.Policy, cfg ContainerCheckConfig) ([]check.Check, error) {
+	hardenedBinariesCheck, err := containerpol.NewHasHardenedBinariesCheck(cfg.HardeningMinimum)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := enabledChecks(cfg.EnabledChecks)
+	if err != nil {
+		return nil, err
+	}
+	// Configuring an optional check enables it.
+	for name, configured := range map[string]bool{
+		"hasconsistentarchitectures":      len(cfg.RequiredArchitectures) > 0,
+		"hasnounexpectedfilecapabilities": len(cfg.AllowedCapabilities) > 0,
+		"hasnoembeddedsecrets":            cfg.EnforceSecretsCheck,
+		"hasnoknownvulnerabilities":       cfg.AdvisoryDB != "",
+		"hashardenedbinaries":             len(cfg.HardeningMinimum) > 0,
+	} {
+		if configured {
+			enabled[name] = true
+		}
+	}
+	// optional returns chk if it is enabled, or nil to leave it out.
+	optional := func(chk check.Check) check.Check {
+		if !enabled[strings.ToLower(chk.Name())] {
+			return nil
+		}
+		return chk
+	}
+
+	var checks []check.Check
 	switch p {
 	case policy.PolicyContainer:
-		return []check.Check{
+		checks = []check.Check{
 			&containerpol.HasLicenseCheck{},
 			containerpol.NewHasUniqueTagCheck(cfg.DockerConfig),
 			&containerpol.MaxLayersCheck{},
 			&containerpol.HasNoProhibitedPackagesCheck{},
 			&containerpol.HasRequiredLabelsCheck{},
+			optional(containerpol.NewHasConsistentArchitecturesCheck(cfg.DockerConfig, cfg.Insecure, cfg.RequiredArchitectures, cfg.ManifestListPlatforms, cfg.CacheDir, !cfg.MetadataOnly)),
 			&containerpol.RunAsNonRootCheck{},
+			optional(&containerpol.SupportsArbitraryUIDCheck{}),
 			&containerpol.HasModifiedFilesCheck{},
-			containerpol.NewBasedOnUbiCheck(pyxis.NewPyxisClient(
-				cfg.PyxisHost,
-				cfg.PyxisAPIToken,
-				cfg.CertificationProjectID,
-				&http.Client{Timeout: 60 * time.Second})),
-		}, nil
+			optional(&containerpol.HasNoUnexpectedSetuidFilesCheck{}),
+			optional(containerpol.NewHasNoUnexpectedFileCapabilitiesCheck(cfg.AllowedCapabilities)),
+			optional(containerpol.NewHasNoEmbeddedSecretsCheck(cfg.EnforceSecretsCheck)),
+			optional(&containerpol.HasResolvableEntrypointCheck{}),
+			optional(containerpol.NewHasNoKnownVulnerabilitiesCheck(cfg.AdvisoryDB)),
+			optional(hardenedBinariesCheck),
+			containerpol.NewBasedOnUbiCheck(baseImageLayerChecker(cfg)),
+		}
 	case policy.PolicyRoot:
-		return []check.Check{
+		checks = []check.Check{
 			&containerpol.HasLicenseCheck{},
 			containerpol.NewHasUniqueTagCheck(cfg.DockerConfig),
 			&containerpol.MaxLayersCheck{},
 			&containerpol.HasNoProhibitedPackagesCheck{},
 			&containerpol.HasRequiredLabelsCheck{},
+			optional(containerpol.NewHasConsistentArchitecturesCheck(cfg.DockerConfig, cfg.Insecure, cfg.RequiredArchitectures, cfg.ManifestListPlatforms, cfg.CacheDir, !cfg.MetadataOnly)),
 			&containerpol.HasModifiedFilesCheck{},
-			containerpol.NewBasedOnUbiCheck(pyxis.NewPyxisClient(
-				cfg.PyxisHost,
-				cfg.PyxisAPIToken,
-				cfg.CertificationProjectID,
-				&http.Client{Timeout: 60 * time.Second})),
-		}, nil
+			optional(&containerpol.HasNoUnexpectedSetuidFilesCheck{}),
+			optional(containerpol.NewHasNoUnexpectedFileCapabilitiesCheck(cfg.AllowedCapabilities)),
+			optional(containerpol.NewHasNoEmbeddedSecretsCheck(cfg.EnforceSecretsCheck)),
+			optional(&containerpol.HasResolvableEntrypointCheck{}),
+			optional(containerpol.NewHasNoKnownVulnerabilitiesCheck(cfg.AdvisoryDB)),
+			optional(hardenedBinariesCheck),
+			containerpol.NewBasedOnUbiCheck(baseImageLayerChecker(cfg)),
+		}
 	case policy.PolicyScratchNonRoot:
-		return []check.Check{
+		checks = []check.Check{
 			&containerpol.HasLicenseCheck{},
 			containerpol.NewHasUniqueTagCheck(cfg.DockerConfig),
 			&containerpol.MaxLayersCheck{},
 			&containerpol.HasRequiredLabelsCheck{},
+			optional(containerpol.NewHasConsistentArchitecturesCheck(cfg.DockerConfig, cfg.Insecure, cfg.RequiredArchitectures, cfg.ManifestListPlatforms, cfg.CacheDir, !cfg.MetadataOnly)),
 			&containerpol.RunAsNonRootCheck{},
-		}, nil
+			optional(&containerpol.SupportsArbitraryUIDCheck{}),
+			optional(&containerpol.HasNoUnexpectedSetuidFilesCheck{}),
+			optional(containerpol.NewHasNoUnexpectedFileCapabilitiesCheck(cfg.AllowedCapabilities)),
+			optional(containerpol.NewHasNoEmbeddedSecretsCheck(cfg.EnforceSecretsCheck)),
+			optional(&containerpol.HasResolvableEntrypointCheck{}),
+			optional(containerpol.NewHasNoKnownVulnerabilitiesCheck(cfg.AdvisoryDB)),
+			optional(hardenedBinariesCheck),
+		}
 	case policy.PolicyScratchRoot:
-		return []check.Check{
+		checks = []check.Check{
 			&containerpol.HasLicenseCheck{},
 			containerpol.NewHasUniqueTagCheck(cfg.DockerConfig),
 			&containerpol.MaxLayersCheck{},
 			&containerpol.HasRequiredLabelsCheck{},
-		}, nil
+			optional(containerpol.NewHasConsistentArchitecturesCheck(cfg.DockerConfig, cfg.Insecure, cfg.RequiredArchitectures, cfg.ManifestListPlatforms, cfg.CacheDir, !cfg.MetadataOnly)),
+			optional(&containerpol.HasNoUnexpectedSetuidFilesCheck{}),
+			optional(containerpol.NewHasNoUnexpectedFileCapabilitiesCheck(cfg.AllowedCapabilities)),
+			optional(containerpol.NewHasNoEmbeddedSecretsCheck(cfg.EnforceSecretsCheck)),
+			optional(&containerpol.HasResolvableEntrypointCheck{}),
+			optional(containerpol.NewHasNoKnownVulnerabilitiesCheck(cfg.AdvisoryDB)),
+			optional(hardenedBinariesCheck),
+		}
+	default:
+		return nil, fmt.Errorf("provided container policy %s is unknown", p)
+	}
+
+	if cfg.FIPSCheck {
+		checks = append(checks, &containerpol.SupportsFIPSModeCheck{})
 	}
 
-	return nil, fmt.Errorf("provided container policy %s is unknown", p)
+	return slices.DeleteFunc(checks, func(chk check.Check) bool { return chk == nil }), nil
 }
 
 // makeCheckList returns a list of check names.
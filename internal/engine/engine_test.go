@@ -7,23 +7,38 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/events"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/basecatalog"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layercache"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
+	containerpol "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy/container"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/sbom"
 
 	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -35,6 +50,7 @@ var _ = Describe("Execute Checks tests", func() {
 	var testcontext context.Context
 	var s *httptest.Server
 	var u *url.URL
+	var artifactsDir string
 	BeforeEach(func() {
 		// Set up a fake registry.
 		registryLogger := log.New(io.Discard, "", log.Ldate)
@@ -59,6 +75,7 @@ var _ = Describe("Execute Checks tests", func() {
 		tmpDir, err := os.MkdirTemp("", "preflight-engine-test-*")
 		Expect(err).ToNot(HaveOccurred())
 		DeferCleanup(os.RemoveAll, tmpDir)
+		artifactsDir = tmpDir
 		aw, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(tmpDir))
 		Expect(err).ToNot(HaveOccurred())
 		testcontext = artifacts.ContextWithWriter(context.Background(), aw)
@@ -153,6 +170,33 @@ var _ = Describe("Execute Checks tests", func() {
 			Expect(engine.results.Warned).To(HaveLen(1))
 			Expect(engine.results.CertificationHash).To(BeEmpty())
 		})
+		It("should record optional checks as skipped", func() {
+			err := engine.ExecuteChecks(testcontext)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(engine.results.Skipped).To(HaveLen(2))
+			for _, skipped := range engine.results.Skipped {
+				Expect(skipped.SkipReason).To(Equal(certification.SkipReasonOptionalLevel))
+			}
+			Expect(engine.results.Skipped[0].Name()).To(Equal("optionalCheckPassing"))
+			Expect(engine.results.Skipped[1].SkipMessage).To(ContainSubstring("ERROR"))
+		})
+		It("should record checks missing a prerequisite as skipped", func() {
+			engine.checks = []check.Check{check.NewGenericCheck(
+				"notApplicable",
+				func(context.Context, image.ImageReference) (bool, error) {
+					return false, check.MissingPrerequisite("no registry to query")
+				},
+				check.Metadata{},
+				check.HelpText{},
+			)}
+			err := engine.ExecuteChecks(testcontext)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(engine.results.PassedOverall).To(BeTrue())
+			Expect(engine.results.Errors).To(BeEmpty())
+			Expect(engine.results.Skipped).To(HaveLen(1))
+			Expect(engine.results.Skipped[0].SkipReason).To(Equal(certification.SkipReasonMissingPrerequisite))
+			Expect(engine.results.Skipped[0].SkipMessage).To(Equal("no registry to query"))
+		})
 		Context("it is a bundle", func() {
 			It("should succeed and generate a bundle hash", func() {
 				engine.isBundle = true
@@ -168,6 +212,373 @@ var _ = Describe("Execute Checks tests", func() {
 				Expect(err).To(HaveOccurred())
 			})
 		})
+		Context("the image is read from an OCI layout", func() {
+			BeforeEach(func() {
+				img, err := random.Image(1024, 2)
+				Expect(err).ToNot(HaveOccurred())
+
+				layoutDir := GinkgoT().TempDir()
+				p, err := layout.Write(layoutDir, empty.Index)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.AppendImage(img)).To(Succeed())
+
+				engine.image = "oci:" + layoutDir
+			})
+			It("should succeed and mark the image as local", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.Passed).To(HaveLen(2))
+				Expect(engine.imageRef.Local).To(BeTrue())
+				Expect(engine.imageRef.ImageRegistry).To(BeEmpty())
+				Expect(engine.imageRef.ImageTagOrSha).To(HavePrefix("sha256:"))
+			})
+		})
+		Context("the image is read from a docker archive", func() {
+			BeforeEach(func() {
+				img, err := random.Image(1024, 2)
+				Expect(err).ToNot(HaveOccurred())
+				tag, err := name.NewTag("quay.io/foo/bar:v1")
+				Expect(err).ToNot(HaveOccurred())
+
+				archive := filepath.Join(GinkgoT().TempDir(), "image.tar")
+				Expect(tarball.WriteToFile(archive, tag, img)).To(Succeed())
+
+				engine.image = "docker-archive:" + archive + ":quay.io/foo/bar:v1"
+			})
+			It("should succeed and use the archive's reference", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.imageRef.Local).To(BeTrue())
+				Expect(engine.imageRef.ImageRegistry).To(Equal("quay.io"))
+				Expect(engine.imageRef.ImageRepository).To(Equal("foo/bar"))
+				Expect(engine.imageRef.ImageTagOrSha).To(Equal("v1"))
+			})
+		})
+		Context("the local image source does not exist", func() {
+			It("should return an error", func() {
+				engine.image = "oci:/does/not/exist"
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("a persistent layer cache is configured", func() {
+			It("should populate the cache and reuse it on later runs", func() {
+				engine.cacheDir = GinkgoT().TempDir()
+				engine.cacheMaxSize = layercache.DefaultMaxSize
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+
+				stats, err := layercache.ReadStats(engine.cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stats.Entries).To(Equal(5))
+
+				engine.results = certification.Results{}
+				err = engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.Passed).To(HaveLen(2))
+
+				stats, err = layercache.ReadStats(engine.cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stats.Entries).To(Equal(5))
+			})
+		})
+		Context("an SBOM is requested", func() {
+			BeforeEach(func() {
+				engine.sbomFormats = []sbom.Format{sbom.FormatSPDX, sbom.FormatCycloneDX}
+			})
+			It("should write the SBOM in each format", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filepath.Join(artifactsDir, "sbom.spdx.json")).To(BeARegularFile())
+				Expect(filepath.Join(artifactsDir, "sbom.cdx.json")).To(BeARegularFile())
+			})
+			It("should not write an SBOM for bundles", func() {
+				engine.isBundle = true
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(filepath.Join(artifactsDir, "sbom.spdx.json")).ToNot(BeAnExistingFile())
+			})
+		})
+		Context("no check requires the image filesystem", func() {
+			var seen image.ImageReference
+			BeforeEach(func() {
+				engine.checks = []check.Check{requirementsCheck{check.NewGenericCheck(
+					"metadataOnly",
+					func(_ context.Context, imgRef image.ImageReference) (bool, error) {
+						seen = imgRef
+						config, err := imgRef.ImageFacts().Config()
+						return err == nil && len(config.RootFS.DiffIDs) == 5, err
+					},
+					check.Metadata{},
+					check.HelpText{},
+				), check.RequiresConfig}}
+			})
+			It("should not download or extract the layers", func() {
+				engine.cacheDir = GinkgoT().TempDir()
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.Passed).To(HaveLen(1))
+				Expect(seen.ImageFSPath).To(BeEmpty())
+				Expect(seen.Files.Len()).To(BeZero())
+
+				stats, err := layercache.ReadStats(engine.cacheDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stats.Entries).To(BeZero())
+
+				Expect(filepath.Join(artifactsDir, check.DefaultCertImageFilename)).ToNot(BeAnExistingFile())
+			})
+			It("should not query the cluster for bundles", func() {
+				engine.isBundle = true
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.TestedOn).To(Equal(runtime.UnknownOpenshiftClusterVersion()))
+				Expect(engine.results.CertificationHash).To(BeEmpty())
+			})
+		})
+		Context("checks are run concurrently", func() {
+			var running, maxRunning, runningDuringSerial atomic.Int32
+
+			trackedCheck := func(name string) check.Check {
+				return check.NewGenericCheck(
+					name,
+					func(context.Context, image.ImageReference) (bool, error) {
+						n := running.Add(1)
+						defer running.Add(-1)
+						for {
+							m := maxRunning.Load()
+							if n <= m || maxRunning.CompareAndSwap(m, n) {
+								break
+							}
+						}
+						time.Sleep(50 * time.Millisecond)
+						return true, nil
+					},
+					check.Metadata{},
+					check.HelpText{},
+				)
+			}
+
+			BeforeEach(func() {
+				running.Store(0)
+				maxRunning.Store(0)
+				runningDuringSerial.Store(0)
+
+				serial := serialCheck{check.NewGenericCheck(
+					"serial",
+					func(context.Context, image.ImageReference) (bool, error) {
+						runningDuringSerial.Store(running.Load())
+						return true, nil
+					},
+					check.Metadata{},
+					check.HelpText{},
+				)}
+
+				engine.checks = []check.Check{
+					trackedCheck("first"),
+					trackedCheck("second"),
+					serial,
+					trackedCheck("third"),
+					trackedCheck("fourth"),
+				}
+			})
+			checkNames := func(results []certification.Result) []string {
+				names := make([]string, 0, len(results))
+				for _, r := range results {
+					names = append(names, r.Name())
+				}
+				return names
+			}
+			It("should run checks in parallel and record results in order", func() {
+				engine.parallelism = 4
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(maxRunning.Load()).To(BeNumerically(">", 1))
+				Expect(runningDuringSerial.Load()).To(BeZero())
+				Expect(checkNames(engine.results.Passed)).To(Equal([]string{"first", "second", "serial", "third", "fourth"}))
+			})
+			It("should run one check at a time by default", func() {
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(maxRunning.Load()).To(BeNumerically("==", 1))
+				Expect(checkNames(engine.results.Passed)).To(Equal([]string{"first", "second", "serial", "third", "fourth"}))
+			})
+		})
+		Context("an event handler is in the context", func() {
+			It("should report the progress of the run", func() {
+				var received []events.Event
+				ctx := events.ContextWithHandler(testcontext, func(e events.Event) {
+					received = append(received, e)
+				})
+				engine.checks = engine.checks[:2]
+				err := engine.ExecuteChecks(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(received[0]).To(Equal(events.PullStarted{Image: src}))
+				Expect(received[1]).To(BeAssignableToTypeOf(events.PullFinished{}))
+				Expect(received[1].(events.PullFinished).Size).To(BeNumerically(">", 0))
+				Expect(received).To(ContainElement(BeAssignableToTypeOf(events.ArtifactWritten{})))
+				Expect(received).To(ContainElement(events.ExtractionProgress{Entries: 5, Bytes: 5 * 1024, Done: true}))
+				Expect(received).To(ContainElement(events.CheckStarted{Name: "testcheck"}))
+				Expect(received).To(ContainElement(SatisfyAll(
+					BeAssignableToTypeOf(events.CheckFinished{}),
+					HaveField("Name", "errorCheck"),
+					HaveField("Status", statusError),
+				)))
+			})
+		})
+		Context("checks report findings", func() {
+			It("should record the findings of each check in its result", func() {
+				engine.checks = []check.Check{check.NewGenericCheck(
+					"withFindings",
+					func(ctx context.Context, _ image.ImageReference) (bool, error) {
+						check.AddFinding(ctx, check.Finding{Message: "label is missing", Severity: check.SeverityError})
+						return false, nil
+					},
+					check.Metadata{},
+					check.HelpText{},
+				)}
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.Failed).To(HaveLen(1))
+				Expect(engine.results.Failed[0].Findings).To(Equal([]check.Finding{{Message: "label is missing", Severity: check.SeverityError}}))
+			})
+		})
+		Context("checks run for longer than their timeout", func() {
+			var release chan struct{}
+			BeforeEach(func() {
+				release = make(chan struct{})
+				DeferCleanup(func() { close(release) })
+
+				engine.checks = []check.Check{
+					check.NewGenericCheck(
+						"honorsCancellation",
+						func(ctx context.Context, _ image.ImageReference) (bool, error) {
+							<-ctx.Done()
+							return false, ctx.Err()
+						},
+						check.Metadata{},
+						check.HelpText{},
+					),
+					check.NewGenericCheck(
+						"ignoresCancellation",
+						func(context.Context, image.ImageReference) (bool, error) {
+							<-release
+							return true, nil
+						},
+						check.Metadata{},
+						check.HelpText{},
+					),
+					check.NewGenericCheck(
+						"slowButAllowed",
+						func(context.Context, image.ImageReference) (bool, error) {
+							time.Sleep(100 * time.Millisecond)
+							return true, nil
+						},
+						check.Metadata{},
+						check.HelpText{},
+					),
+				}
+				engine.checkTimeouts = runtime.CheckTimeouts{
+					Default:   50 * time.Millisecond,
+					Overrides: map[string]time.Duration{"slowbutallowed": 0},
+				}
+			})
+			It("should record them as timed out and keep running the other checks", func() {
+				// A check ignoring cancellation keeps its worker until it
+				// returns, within the grace period.
+				engine.parallelism = 1
+				timer := time.AfterFunc(150*time.Millisecond, func() { release <- struct{}{} })
+				DeferCleanup(timer.Stop)
+				err := engine.ExecuteChecks(testcontext)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(engine.results.PassedOverall).To(BeFalse())
+				Expect(engine.results.Errors).To(BeEmpty())
+				Expect(engine.results.TimedOut).To(HaveLen(2))
+				Expect(engine.results.TimedOut[0].Name()).To(Equal("honorsCancellation"))
+				Expect(engine.results.TimedOut[1].Name()).To(Equal("ignoresCancellation"))
+				Expect(engine.results.TimedOut[1].Error()).To(MatchError(ContainSubstring("timed out after 50ms")))
+				Expect(engine.results.Passed).To(HaveLen(1))
+			})
+			Context("and other checks follow a check that ignores cancellation", func() {
+				var stillRunning atomic.Bool
+				BeforeEach(func() {
+					stillRunning.Store(true)
+					engine.checks = []check.Check{
+						check.NewGenericCheck(
+							"ignoresCancellation",
+							func(context.Context, image.ImageReference) (bool, error) {
+								defer stillRunning.Store(false)
+								<-release
+								return true, nil
+							},
+							check.Metadata{},
+							check.HelpText{},
+						),
+						check.NewGenericCheck(
+							"next",
+							func(context.Context, image.ImageReference) (bool, error) {
+								return !stillRunning.Load(), nil
+							},
+							check.Metadata{},
+							check.HelpText{},
+						),
+					}
+					engine.checkTimeouts = runtime.CheckTimeouts{Default: 50 * time.Millisecond}
+					engine.timedOutCheckGracePeriod = time.Second
+				})
+				releaseAfter := func(d time.Duration) {
+					timer := time.AfterFunc(d, func() { release <- struct{}{} })
+					DeferCleanup(timer.Stop)
+				}
+				It("should keep the worker of the timed out check until it returns", func() {
+					releaseAfter(150 * time.Millisecond)
+					err := engine.ExecuteChecks(testcontext)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(engine.results.TimedOut).To(HaveLen(1))
+					Expect(engine.results.Passed).To(HaveLen(1))
+				})
+				It("should give the worker to the next check at parallelism 1 once the timed out check returns", func() {
+					engine.parallelism = 1
+					releaseAfter(150 * time.Millisecond)
+					err := engine.ExecuteChecks(testcontext)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(engine.results.TimedOut).To(HaveLen(1))
+					Expect(engine.results.Passed).To(HaveLen(1))
+				})
+				It("should fail the run at parallelism 1 if the timed out check does not return within the grace period", func() {
+					engine.parallelism = 1
+					engine.timedOutCheckGracePeriod = 50 * time.Millisecond
+					err := engine.ExecuteChecks(testcontext)
+					Expect(err).To(MatchError(errCheckStillRunning))
+					Expect(err).To(MatchError(ContainSubstring("ignoresCancellation")))
+				})
+				It("should only run a serial check once the timed out check returns", func() {
+					engine.parallelism = 4
+					engine.checks[1] = serialCheck{engine.checks[1]}
+					releaseAfter(150 * time.Millisecond)
+					err := engine.ExecuteChecks(testcontext)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(engine.results.Passed).To(HaveLen(1))
+				})
+				It("should fail the run if the timed out check does not return within the grace period", func() {
+					engine.parallelism = 4
+					engine.checks[1] = serialCheck{engine.checks[1]}
+					engine.timedOutCheckGracePeriod = 50 * time.Millisecond
+					err := engine.ExecuteChecks(testcontext)
+					Expect(err).To(MatchError(errCheckStillRunning))
+					Expect(err).To(MatchError(ContainSubstring("ignoresCancellation")))
+				})
+				It("should wait for a timed out serial check before running the next check", func() {
+					engine.parallelism = 4
+					engine.checks[0] = serialCheck{engine.checks[0]}
+					releaseAfter(150 * time.Millisecond)
+					err := engine.ExecuteChecks(testcontext)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(engine.results.TimedOut).To(HaveLen(1))
+					Expect(engine.results.Passed).To(HaveLen(1))
+				})
+			})
+		})
 		Context("it is a bundle made with GNU tar layer", func() {
 			BeforeEach(func() {
 				var buf bytes.Buffer
@@ -240,6 +651,46 @@ var _ = Describe("Execute Checks tests", func() {
 	})
 })
 
+// serialCheck wraps a check.Check, requesting that it is run by itself.
+type serialCheck struct {
+	check.Check
+}
+
+func (serialCheck) RequiresSerialExecution() bool {
+	return true
+}
+
+// requirementsCheck wraps a check.Check, declaring the inputs it needs.
+type requirementsCheck struct {
+	check.Check
+	requirements check.Requirement
+}
+
+func (c requirementsCheck) Requirements() check.Requirement {
+	return c.requirements
+}
+
+var _ = Describe("Engine creation", func() {
+	It("should parse the SBOM formats", func() {
+		eng, err := New(context.TODO(), nil, nil, runtime.Config{SBOMFormats: []string{"cyclonedx"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(eng.sbomFormats).To(Equal([]sbom.Format{sbom.FormatCycloneDX}))
+	})
+	It("should use the default extraction limits for unset limits", func() {
+		eng, err := New(context.TODO(), nil, nil, runtime.Config{ExtractionLimits: runtime.ExtractionLimits{MaxEntries: 10}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(eng.extractionLimits).To(Equal(runtime.ExtractionLimits{
+			MaxSize:     runtime.DefaultExtractionLimits.MaxSize,
+			MaxFileSize: runtime.DefaultExtractionLimits.MaxFileSize,
+			MaxEntries:  10,
+		}))
+	})
+	It("should reject an unknown SBOM format", func() {
+		_, err := New(context.TODO(), nil, nil, runtime.Config{SBOMFormats: []string{"swid"}})
+		Expect(err).To(MatchError(ContainSubstring("swid")))
+	})
+})
+
 var _ = Describe("Source RPM name function", func() {
 	Context("With a source rpm name", func() {
 		Context("And a normal source rpm name", func() {
@@ -315,6 +766,65 @@ var _ = Describe("Check Initialization", func() {
 			_, err := InitializeContainerChecks(context.TODO(), policy.Policy("foo"), ContainerCheckConfig{})
 			Expect(err).To(HaveOccurred())
 		})
+		It("should throw an error if the hardening minimum names an unknown property", func() {
+			_, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{HardeningMinimum: []string{"aslr"}})
+			Expect(err).To(MatchError(ContainSubstring(`unknown hardening property "aslr"`)))
+		})
+		It("should only add the optional checks that are enabled", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).ToNot(ContainElements(OptionalContainerChecks))
+
+			checks, err = InitializeContainerChecks(context.TODO(), policy.PolicyScratchRoot, ContainerCheckConfig{EnabledChecks: []string{"hasconsistentarchitectures"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElement("HasConsistentArchitectures"))
+			Expect(checks).ToNot(ContainElement(BeNil()))
+		})
+		It("should add the consistent architectures check if architectures are required", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{RequiredArchitectures: []string{"s390x"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElement("HasConsistentArchitectures"))
+		})
+		It("should add the file capabilities check if capabilities are allowed", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{AllowedCapabilities: []string{"cap_net_raw"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElement("HasNoUnexpectedFileCapabilities"))
+		})
+		It("should add the secrets check if it is enforced", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{EnforceSecretsCheck: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElement("HasNoEmbeddedSecrets"))
+		})
+		It("should add the vulnerabilities check if an advisory database is configured", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{AdvisoryDB: "/tmp/advisories"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElement("HasNoKnownVulnerabilities"))
+		})
+		It("should add the hardened binaries check if a hardening minimum is configured", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{HardeningMinimum: []string{"none"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElement("HasHardenedBinaries"))
+		})
+		It("should throw an error if an unknown optional check is enabled", func() {
+			_, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{EnabledChecks: []string{"HasLicense"}})
+			Expect(err).To(MatchError(ContainSubstring(`unknown optional check "HasLicense"`)))
+		})
+		It("should only add the FIPS check if it is enabled", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).ToNot(ContainElement("SupportsFIPSMode"))
+
+			checks, err = InitializeContainerChecks(context.TODO(), policy.PolicyScratchNonRoot, ContainerCheckConfig{FIPSCheck: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElement("SupportsFIPSMode"))
+		})
+		It("should look base image layers up in the catalog if one is configured", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{BaseImageCatalog: "/tmp/base-catalog.json"})
+			Expect(err).ToNot(HaveOccurred())
+			basedOnUBI, ok := checks[len(checks)-1].(*containerpol.BasedOnUBICheck)
+			Expect(ok).To(BeTrue())
+			Expect(basedOnUBI.LayerHashCheckEngine).To(Equal(basecatalog.NewLayerHashChecker("/tmp/base-catalog.json")))
+		})
 	})
 
 	When("initializing operator checks", func() {
@@ -404,6 +914,196 @@ var _ = Describe("Link Path Resolution", func() {
 	)
 })
 
+var _ = Describe("Image extraction", func() {
+	var dst, outside string
+	var files *image.FileIndex
+
+	BeforeEach(func() {
+		parent := GinkgoT().TempDir()
+		dst = filepath.Join(parent, "fs")
+		outside = filepath.Join(parent, "fs2")
+		Expect(os.Mkdir(dst, 0o755)).To(Succeed())
+		files = image.NewFileIndex()
+	})
+
+	extract := func(limits runtime.ExtractionLimits, headers ...*tar.Header) error {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, h := range headers {
+			Expect(tw.WriteHeader(h)).To(Succeed())
+			if h.Typeflag == tar.TypeReg {
+				_, err := tw.Write(make([]byte, h.Size))
+				Expect(err).ToNot(HaveOccurred())
+			}
+		}
+		Expect(tw.Close()).To(Succeed())
+		return untar(context.TODO(), dst, &buf, limits, files)
+	}
+
+	Context("with entries escaping the destination", func() {
+		It("should skip them", func() {
+			err := extract(runtime.DefaultExtractionLimits,
+				&tar.Header{Typeflag: tar.TypeReg, Name: "../fs2/escaped", Size: 1, Mode: 0o644},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "../../escaped-dir", Mode: 0o755},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "/etc/passwd", Size: 1, Mode: 0o644},
+				&tar.Header{Typeflag: tar.TypeLink, Name: "hardlink", Linkname: "../fs2/target"},
+				&tar.Header{Typeflag: tar.TypeSymlink, Name: "symlink", Linkname: "/../fs2/target"},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(outside).ToNot(BeADirectory())
+			Expect(filepath.Join(dst, "etc", "passwd")).To(BeARegularFile())
+			Expect(filepath.Join(dst, "hardlink")).ToNot(BeAnExistingFile())
+			_, err = os.Lstat(filepath.Join(dst, "symlink"))
+			Expect(err).To(MatchError(os.ErrNotExist))
+		})
+	})
+
+	Context("with an absolute hard link target", func() {
+		It("should link relative to the destination", func() {
+			err := extract(runtime.DefaultExtractionLimits,
+				&tar.Header{Typeflag: tar.TypeReg, Name: "etc/original", Size: 1, Mode: 0o644},
+				&tar.Header{Typeflag: tar.TypeLink, Name: "hardlink", Linkname: "/etc/original"},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filepath.Join(dst, "hardlink")).To(BeARegularFile())
+		})
+	})
+
+	Context("with file metadata", func() {
+		It("should record ownership, special modes, xattrs and device nodes", func() {
+			err := extract(runtime.DefaultExtractionLimits,
+				&tar.Header{
+					Typeflag: tar.TypeReg, Name: "usr/bin/sudo", Size: 8, Mode: 0o4755, Uid: 0, Gid: 0,
+					PAXRecords: map[string]string{paxLayerRecord: "2"},
+				},
+				&tar.Header{
+					Typeflag: tar.TypeReg, Name: "usr/bin/ping", Size: 8, Mode: 0o755, Uid: 1001, Gid: 1002, Uname: "app", Gname: "app",
+					PAXRecords: map[string]string{"SCHILY.xattr.security.capability": "\x01\x00", paxLayerRecord: "1"},
+				},
+				&tar.Header{Typeflag: tar.TypeChar, Name: "dev/null", Mode: 0o666, Devmajor: 1, Devminor: 3},
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			sudo, ok := files.Lookup("/usr/bin/sudo")
+			Expect(ok).To(BeTrue())
+			Expect(sudo.Mode & fs.ModeSetuid).ToNot(BeZero())
+			Expect(sudo.Mode.Perm()).To(Equal(fs.FileMode(0o755)))
+			Expect(sudo.Layer).To(Equal(2))
+
+			ping, ok := files.Lookup("usr/bin/ping")
+			Expect(ok).To(BeTrue())
+			Expect(ping.UID).To(Equal(1001))
+			Expect(ping.GID).To(Equal(1002))
+			Expect(ping.Uname).To(Equal("app"))
+			Expect(ping.Capability()).To(Equal([]byte{0x01, 0x00}))
+			Expect(ping.Layer).To(Equal(1))
+
+			null, ok := files.Lookup("/dev/null")
+			Expect(ok).To(BeTrue())
+			Expect(null.Mode & fs.ModeCharDevice).ToNot(BeZero())
+			Expect(null.Devminor).To(BeEquivalentTo(3))
+			Expect(filepath.Join(dst, "dev", "null")).ToNot(BeAnExistingFile())
+
+			info, err := os.Stat(filepath.Join(dst, "usr", "bin", "sudo"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode() & fs.ModeSetuid).To(BeZero())
+		})
+	})
+
+	Context("with a hard link to a file later in the stream", func() {
+		It("should create the link once the target exists", func() {
+			err := extract(runtime.DefaultExtractionLimits,
+				&tar.Header{Typeflag: tar.TypeLink, Name: "usr/bin/link", Linkname: "usr/bin/original"},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "usr/bin/original", Size: 1, Mode: 0o755},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filepath.Join(dst, "usr", "bin", "link")).To(BeARegularFile())
+
+			link, ok := files.Lookup("/usr/bin/link")
+			Expect(ok).To(BeTrue())
+			Expect(link.HardLink).To(BeTrue())
+			Expect(link.Linkname).To(Equal("/usr/bin/original"))
+		})
+	})
+
+	DescribeTable("with extraction limits",
+		func(limits runtime.ExtractionLimits, limit string) {
+			err := extract(limits,
+				&tar.Header{Typeflag: tar.TypeDir, Name: "dir", Mode: 0o755},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "dir/a", Size: 64, Mode: 0o644},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "dir/b", Size: 64, Mode: 0o644},
+			)
+			Expect(err).To(MatchError(preflighterr.ErrExtractionLimitExceeded))
+			var limitErr *preflighterr.ExtractionLimitError
+			Expect(errors.As(err, &limitErr)).To(BeTrue())
+			Expect(limitErr.Limit).To(Equal(limit))
+		},
+		Entry("too many entries", runtime.ExtractionLimits{MaxEntries: 2}, "entry count"),
+		Entry("a file that is too large", runtime.ExtractionLimits{MaxFileSize: 32}, "file size"),
+		Entry("too much data in total", runtime.ExtractionLimits{MaxSize: 100}, "total size"),
+	)
+})
+
+var _ = Describe("Image flattening", func() {
+	layerOf := func(headers ...*tar.Header) cranev1.Layer {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, h := range headers {
+			Expect(tw.WriteHeader(h)).To(Succeed())
+			if h.Size > 0 {
+				_, err := tw.Write(make([]byte, h.Size))
+				Expect(err).ToNot(HaveOccurred())
+			}
+		}
+		Expect(tw.Close()).To(Succeed())
+		return static.NewLayer(buf.Bytes(), types.DockerUncompressedLayer)
+	}
+
+	It("should apply whiteouts and record the layer that last wrote each file", func() {
+		img, err := mutate.AppendLayers(empty.Image,
+			layerOf(
+				&tar.Header{Typeflag: tar.TypeReg, Name: "etc/config", Size: 1, Mode: 0o644},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "etc/removed", Size: 1, Mode: 0o644},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "opt/app", Mode: 0o755},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "opt/app/old", Size: 1, Mode: 0o644},
+			),
+			layerOf(
+				&tar.Header{Typeflag: tar.TypeReg, Name: "etc/config", Size: 2, Mode: 0o600},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "etc/.wh.removed", Mode: 0o644},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "opt/app/.wh..wh..opq", Mode: 0o644},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "opt/app/new", Size: 1, Mode: 0o644},
+			),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		r, w := io.Pipe()
+		go func() {
+			w.CloseWithError(export(img, w))
+		}()
+		dst := GinkgoT().TempDir()
+		files := image.NewFileIndex()
+		Expect(untar(context.TODO(), dst, r, runtime.DefaultExtractionLimits, files)).To(Succeed())
+
+		config, ok := files.Lookup("/etc/config")
+		Expect(ok).To(BeTrue())
+		Expect(config.Layer).To(Equal(1))
+		Expect(config.Size).To(BeEquivalentTo(2))
+
+		_, ok = files.Lookup("/etc/removed")
+		Expect(ok).To(BeFalse())
+		Expect(filepath.Join(dst, "etc", "removed")).ToNot(BeAnExistingFile())
+
+		_, ok = files.Lookup("/opt/app/old")
+		Expect(ok).To(BeFalse())
+		appDir, ok := files.Lookup("/opt/app")
+		Expect(ok).To(BeTrue())
+		Expect(appDir.Layer).To(Equal(0))
+		newFile, ok := files.Lookup("/opt/app/new")
+		Expect(ok).To(BeTrue())
+		Expect(newFile.Layer).To(Equal(1))
+	})
+})
+
 // writeTarball writes a tar archive to out with filename containing contents at the base path
 // with extra bytes written at the end of length extraBytes.
 // note: this should only be used as a helper function in tests
@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	// whiteoutPrefix marks a file as deleted in an image layer.
+	whiteoutPrefix = ".wh."
+	// opaqueWhiteout marks a directory's contents in lower layers as deleted.
+	opaqueWhiteout = ".wh..wh..opq"
+	// paxLayerRecord is the PAX record export uses to tell untar which layer
+	// an entry came from.
+	paxLayerRecord = "PREFLIGHT.layer"
+)
+
+// export flattens img's layers into a single tar stream written to w. It behaves
+// like mutate.Extract, additionally honoring opaque whiteouts and recording the
+// index of the layer each entry came from in the paxLayerRecord PAX record.
+func export(img cranev1.Image, w io.Writer) error {
+	tarWriter := tar.NewWriter(w)
+	defer tarWriter.Close()
+
+	layers, err := img.Layers()
+	if err != nil {
+		return fmt.Errorf("retrieving image layers: %w", err)
+	}
+
+	// fileMap tracks the paths that have been handled by a higher layer. A
+	// value of true means lower layers may not write beneath the path either.
+	fileMap := map[string]bool{}
+	// opaqueDirs tracks directories whose lower layer contents were deleted,
+	// and the layer that deleted them.
+	opaqueDirs := map[string]int{}
+
+	// Layers are processed top-down, so the first entry seen for a path is
+	// the one that ends up in the image.
+	for i := len(layers) - 1; i >= 0; i-- {
+		if err := exportLayer(tarWriter, layers[i], i, fileMap, opaqueDirs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportLayer(tarWriter *tar.Writer, layer cranev1.Layer, index int, fileMap map[string]bool, opaqueDirs map[string]int) error {
+	layerReader, err := layer.Uncompressed()
+	if err != nil {
+		return fmt.Errorf("reading layer contents: %w", err)
+	}
+	defer layerReader.Close()
+
+	tarReader := tar.NewReader(layerReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading tar: %w", err)
+		}
+
+		// Some tools prepend everything with "./", so clean the name to avoid
+		// duplicate entries.
+		header.Name = filepath.Clean(header.Name)
+		header.Format = tar.FormatPAX
+
+		basename := filepath.Base(header.Name)
+		dirname := filepath.Dir(header.Name)
+
+		if basename == opaqueWhiteout {
+			if _, ok := opaqueDirs[dirname]; !ok {
+				opaqueDirs[dirname] = index
+			}
+			continue
+		}
+
+		tombstone := strings.HasPrefix(basename, whiteoutPrefix)
+		if tombstone {
+			basename = basename[len(whiteoutPrefix):]
+		}
+
+		var name string
+		if header.Typeflag == tar.TypeDir {
+			name = header.Name
+		} else {
+			name = filepath.Join(dirname, basename)
+		}
+
+		if _, ok := fileMap[name]; ok {
+			continue
+		}
+		if inWhiteoutDir(fileMap, name) || inOpaqueDir(opaqueDirs, name, index) {
+			continue
+		}
+
+		// Non-directories implicitly tombstone any entries beneath them.
+		fileMap[name] = tombstone || header.Typeflag != tar.TypeDir
+		if tombstone {
+			continue
+		}
+
+		if header.PAXRecords == nil {
+			header.PAXRecords = map[string]string{}
+		}
+		header.PAXRecords[paxLayerRecord] = strconv.Itoa(index)
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if header.Size > 0 {
+			if _, err := io.CopyN(tarWriter, tarReader, header.Size); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// inWhiteoutDir returns true if any parent directory of file was deleted by
+// a higher layer.
+func inWhiteoutDir(fileMap map[string]bool, file string) bool {
+	for {
+		dirname := filepath.Dir(file)
+		if file == "" || file == dirname {
+			return false
+		}
+		if deleted, ok := fileMap[dirname]; ok && deleted {
+			return true
+		}
+		file = dirname
+	}
+}
+
+// inOpaqueDir returns true if a layer above index made a parent directory
+// of file opaque.
+func inOpaqueDir(opaqueDirs map[string]int, file string, index int) bool {
+	for {
+		dirname := filepath.Dir(file)
+		if file == "" || file == dirname {
+			return false
+		}
+		if layer, ok := opaqueDirs[dirname]; ok && layer > index {
+			return true
+		}
+		file = dirname
+	}
+}
+
+// layerFromHeader returns the layer index export recorded for header.
+func layerFromHeader(header *tar.Header) int {
+	layer, err := strconv.Atoi(header.PAXRecords[paxLayerRecord])
+	if err != nil {
+		return 0
+	}
+	return layer
+}
@@ -166,3 +166,146 @@ func TestGenericXMLFormatter(t *testing.T) {
 		}
 	}
 }
+
+func TestPartialResults(t *testing.T) {
+	jsonMarshalIndent = json.MarshalIndent
+	xmlMarshalIndent = xml.MarshalIndent
+
+	results := certification.Results{
+		TestedImage:    "image1",
+		PassedOverall:  true,
+		Checks:         []string{"passed1", "passed2"},
+		LevelOverrides: map[string]string{"passed2": check.LevelWarn, "passed1": check.LevelOptional},
+		Partial:        true,
+	}
+
+	formats := []struct {
+		format    func(context.Context, certification.Results) ([]byte, error)
+		unmarshal func([]byte, any) error
+	}{
+		{genericJSONFormatter, json.Unmarshal},
+		{genericXMLFormatter, xml.Unmarshal},
+	}
+
+	for _, f := range formats {
+		out, err := f.format(context.TODO(), results)
+		assert.NilError(t, err)
+
+		var response UserResponse
+		assert.NilError(t, f.unmarshal(out, &response))
+		assert.Equal(t, true, response.Partial)
+		assert.DeepEqual(t, []string{"passed1", "passed2"}, response.Selection.Checks)
+		assert.DeepEqual(t, []levelOverride{
+			{Name: "passed1", Level: check.LevelOptional},
+			{Name: "passed2", Level: check.LevelWarn},
+		}, response.Selection.LevelOverrides)
+	}
+
+	// Full runs do not describe the selection.
+	results.Partial = false
+	out, err := genericJSONFormatter(context.TODO(), results)
+	assert.NilError(t, err)
+	assert.Equal(t, false, strings.Contains(string(out), "selection"))
+}
+
+func TestTimedOutResults(t *testing.T) {
+	jsonMarshalIndent = json.MarshalIndent
+	xmlMarshalIndent = xml.MarshalIndent
+
+	timedOut := certification.Result{
+		Check:       check.NewGenericCheck("timedout1", nil, check.Metadata{Description: "description"}, check.HelpText{Message: "help"}),
+		ElapsedTime: 2 * time.Second,
+	}
+	results := certification.Results{
+		TestedImage: "image1",
+		TimedOut:    []certification.Result{timedOut},
+	}
+
+	formats := []struct {
+		format    func(context.Context, certification.Results) ([]byte, error)
+		unmarshal func([]byte, any) error
+	}{
+		{genericJSONFormatter, json.Unmarshal},
+		{genericXMLFormatter, xml.Unmarshal},
+	}
+
+	for _, f := range formats {
+		out, err := f.format(context.TODO(), results)
+		assert.NilError(t, err)
+
+		var response UserResponse
+		assert.NilError(t, f.unmarshal(out, &response))
+		assert.Equal(t, 1, len(response.Results.TimedOut))
+		assert.Equal(t, "timedout1", response.Results.TimedOut[0].Name)
+		assert.Equal(t, float64(2000), response.Results.TimedOut[0].ElapsedTime)
+	}
+}
+
+func TestFindings(t *testing.T) {
+	jsonMarshalIndent = json.MarshalIndent
+	xmlMarshalIndent = xml.MarshalIndent
+
+	findings := []check.Finding{
+		{Message: "label is missing", Severity: check.SeverityError, Remediation: "add the label"},
+		{Message: "file was modified", Severity: check.SeverityWarning, Path: "/etc/passwd", Package: "setup-2.13.7-9.el9.noarch", Layer: "sha256:abc"},
+	}
+	results := certification.Results{
+		TestedImage: "image1",
+		Failed: []certification.Result{
+			{Check: check.NewGenericCheck("failed1", nil, check.Metadata{}, check.HelpText{}), Findings: findings},
+		},
+	}
+
+	formats := []struct {
+		format    func(context.Context, certification.Results) ([]byte, error)
+		unmarshal func([]byte, any) error
+	}{
+		{genericJSONFormatter, json.Unmarshal},
+		{genericXMLFormatter, xml.Unmarshal},
+	}
+
+	for _, f := range formats {
+		out, err := f.format(context.TODO(), results)
+		assert.NilError(t, err)
+
+		var response UserResponse
+		assert.NilError(t, f.unmarshal(out, &response))
+		assert.DeepEqual(t, findings, response.Results.Failed[0].Findings)
+	}
+}
+
+func TestSkippedResults(t *testing.T) {
+	jsonMarshalIndent = json.MarshalIndent
+	xmlMarshalIndent = xml.MarshalIndent
+
+	results := certification.Results{
+		TestedImage: "image1",
+		Skipped: []certification.Result{
+			{
+				Check:       check.NewGenericCheck("skipped1", nil, check.Metadata{}, check.HelpText{}),
+				SkipReason:  certification.SkipReasonPolicyException,
+				SkipMessage: "not part of the scratch-nonroot policy",
+			},
+		},
+	}
+
+	formats := []struct {
+		format    func(context.Context, certification.Results) ([]byte, error)
+		unmarshal func([]byte, any) error
+	}{
+		{genericJSONFormatter, json.Unmarshal},
+		{genericXMLFormatter, xml.Unmarshal},
+	}
+
+	for _, f := range formats {
+		out, err := f.format(context.TODO(), results)
+		assert.NilError(t, err)
+
+		var response UserResponse
+		assert.NilError(t, f.unmarshal(out, &response))
+		assert.Equal(t, 1, len(response.Results.Skipped))
+		assert.Equal(t, "skipped1", response.Results.Skipped[0].Name)
+		assert.Equal(t, certification.SkipReasonPolicyException, response.Results.Skipped[0].SkipReason)
+		assert.Equal(t, "not part of the scratch-nonroot policy", response.Results.Skipped[0].SkipMessage)
+	}
+}
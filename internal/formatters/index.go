@@ -0,0 +1,73 @@
+package formatters
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+)
+
+// ResultsIndexFilename is the name of the artifact summarizing the results of
+// every platform of a manifest list.
+const ResultsIndexFilename = "results-index.json"
+
+// ResultsIndex summarizes the results of checking every platform of a
+// manifest list.
+type ResultsIndex struct {
+	Image              string `json:"image"`
+	ManifestListDigest string `json:"manifest_list_digest,omitempty"`
+	// PassedOverall is true if the checks passed for every platform.
+	PassedOverall bool             `json:"passed_overall"`
+	Platforms     []PlatformResult `json:"platforms"`
+}
+
+// PlatformResult summarizes the results of checking one platform.
+type PlatformResult struct {
+	Platform string `json:"platform"`
+	Passed   bool   `json:"passed"`
+	// Results is the path of the platform's results file, relative to the
+	// artifacts directory.
+	Results string `json:"results,omitempty"`
+	// Error is set if the checks could not be run for the platform.
+	Error string `json:"error,omitempty"`
+}
+
+// NewResultsIndex returns an empty index for the manifest list image.
+func NewResultsIndex(image, manifestListDigest string) *ResultsIndex {
+	return &ResultsIndex{
+		Image:              image,
+		ManifestListDigest: manifestListDigest,
+		Platforms:          []PlatformResult{},
+	}
+}
+
+// Add records the outcome of checking platform. Either results or err is
+// expected to be set. Platforms are kept sorted, regardless of the order in
+// which they complete.
+func (idx *ResultsIndex) Add(platform, resultsPath string, results certification.Results, err error) {
+	pr := PlatformResult{
+		Platform: platform,
+		Passed:   err == nil && results.PassedOverall,
+		Results:  resultsPath,
+	}
+	if err != nil {
+		pr.Error = err.Error()
+	}
+
+	idx.Platforms = append(idx.Platforms, pr)
+	sort.Slice(idx.Platforms, func(i, j int) bool { return idx.Platforms[i].Platform < idx.Platforms[j].Platform })
+
+	idx.PassedOverall = true
+	for _, p := range idx.Platforms {
+		idx.PassedOverall = idx.PassedOverall && p.Passed
+	}
+}
+
+// FormatResultsIndex formats idx as JSON.
+func FormatResultsIndex(idx *ResultsIndex) ([]byte, error) {
+	out, err := jsonMarshalIndent(idx, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("error formatting results index: %w", err)
+	}
+	return out, nil
+}
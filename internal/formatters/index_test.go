@@ -0,0 +1,39 @@
+package formatters
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestResultsIndex(t *testing.T) {
+	jsonMarshalIndent = json.MarshalIndent
+
+	index := NewResultsIndex("quay.io/example/image:latest", "sha256:abc")
+	index.Add("s390x", "s390x/results.json", certification.Results{PassedOverall: true}, nil)
+	index.Add("amd64", "amd64/results.json", certification.Results{PassedOverall: true}, nil)
+	assert.Assert(t, index.PassedOverall)
+
+	index.Add("arm64", "arm64/results.json", certification.Results{}, errors.New("pull failed"))
+	assert.Assert(t, !index.PassedOverall)
+
+	out, err := FormatResultsIndex(index)
+	assert.NilError(t, err)
+
+	var formatted ResultsIndex
+	assert.NilError(t, json.Unmarshal(out, &formatted))
+	assert.DeepEqual(t, formatted, ResultsIndex{
+		Image:              "quay.io/example/image:latest",
+		ManifestListDigest: "sha256:abc",
+		PassedOverall:      false,
+		Platforms: []PlatformResult{
+			{Platform: "amd64", Passed: true, Results: "amd64/results.json"},
+			{Platform: "arm64", Passed: false, Results: "arm64/results.json", Error: "pull failed"},
+			{Platform: "s390x", Passed: true, Results: "s390x/results.json"},
+		},
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/xml"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 )
 
 type JUnitTestSuites struct {
@@ -19,6 +21,7 @@ type JUnitTestSuite struct {
 	Tests      int             `xml:"tests,attr"`
 	Failures   int             `xml:"failures,attr"`
 	Warnings   int             `xml:"warnings,attr"`
+	Skipped    int             `xml:"skipped,attr"`
 	Time       string          `xml:"time,attr"`
 	Name       string          `xml:"name,attr"`
 	Properties []JUnitProperty `xml:"properties>property,omitempty"`
@@ -56,9 +59,10 @@ func junitXMLFormatter(_ context.Context, r certification.Results) ([]byte, erro
 	response := getResponse(r)
 	suites := JUnitTestSuites{}
 	testsuite := JUnitTestSuite{
-		Tests:      len(r.Errors) + len(r.Failed) + len(r.Passed) + len(r.Warned),
-		Failures:   len(r.Errors) + len(r.Failed),
+		Tests:      len(r.Errors) + len(r.Failed) + len(r.Passed) + len(r.Warned) + len(r.TimedOut) + len(r.Skipped),
+		Failures:   len(r.Errors) + len(r.Failed) + len(r.TimedOut),
 		Warnings:   len(r.Warned),
+		Skipped:    len(r.Skipped),
 		Time:       "0s",
 		Name:       "Red Hat Certification",
 		Properties: []JUnitProperty{},
@@ -86,7 +90,22 @@ func junitXMLFormatter(_ context.Context, r certification.Results) ([]byte, erro
 			Failure: &JUnitMessage{
 				Message:  "Failed",
 				Type:     "",
-				Contents: fmt.Sprintf("%s: Suggested Fix: %s", result.Help().Message, result.Help().Suggestion),
+				Contents: fmt.Sprintf("%s: Suggested Fix: %s", result.Help().Message, result.Help().Suggestion) + describeFindings(result.Findings),
+			},
+		}
+		testsuite.TestCases = append(testsuite.TestCases, testCase)
+		totalDuration += result.ElapsedTime
+	}
+
+	for _, result := range r.TimedOut {
+		testCase := JUnitTestCase{
+			Classname: response.Image,
+			Name:      result.Name(),
+			Time:      result.ElapsedTime.String(),
+			Failure: &JUnitMessage{
+				Message:  "Timed out",
+				Type:     "",
+				Contents: fmt.Sprintf("%s: %s", result.Help().Message, result.Error()) + describeFindings(result.Findings),
 			},
 		}
 		testsuite.TestCases = append(testsuite.TestCases, testCase)
@@ -101,7 +120,20 @@ func junitXMLFormatter(_ context.Context, r certification.Results) ([]byte, erro
 			Warning: &JUnitMessage{
 				Message:  "Warn",
 				Type:     "",
-				Contents: fmt.Sprintf("%s: Suggested Fix: %s", result.Help().Message, result.Help().Suggestion),
+				Contents: fmt.Sprintf("%s: Suggested Fix: %s", result.Help().Message, result.Help().Suggestion) + describeFindings(result.Findings),
+			},
+		}
+		testsuite.TestCases = append(testsuite.TestCases, testCase)
+		totalDuration += result.ElapsedTime
+	}
+
+	for _, result := range r.Skipped {
+		testCase := JUnitTestCase{
+			Classname: response.Image,
+			Name:      result.Name(),
+			Time:      result.ElapsedTime.String(),
+			SkipMessage: &JUnitSkipMessage{
+				Message: fmt.Sprintf("%s: %s", result.SkipReason, result.SkipMessage),
 			},
 		}
 		testsuite.TestCases = append(testsuite.TestCases, testCase)
@@ -123,3 +155,23 @@ func junitXMLFormatter(_ context.Context, r certification.Results) ([]byte, erro
 
 	return bytes, nil
 }
+
+// describeFindings renders findings as one line each, to be appended to the
+// contents of a test case's failure or warning.
+func describeFindings(findings []check.Finding) string {
+	var b strings.Builder
+	for _, f := range findings {
+		fmt.Fprintf(&b, "\n[%s] %s", f.Severity, f.Message)
+		for _, detail := range []struct{ name, value string }{
+			{"path", f.Path},
+			{"package", f.Package},
+			{"layer", f.Layer},
+			{"remediation", f.Remediation},
+		} {
+			if detail.value != "" {
+				fmt.Fprintf(&b, "; %s: %s", detail.name, detail.value)
+			}
+		}
+	}
+	return b.String()
+}
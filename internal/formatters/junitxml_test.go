@@ -57,6 +57,9 @@ var _ = Describe("JUnitXML Formatter", func() {
 								Suggestion: "suggestion",
 							}),
 						ElapsedTime: 0,
+						Findings: []check.Finding{
+							{Message: "prohibited package found", Severity: check.SeverityError, Package: "kernel-5.14.0-1.el9.x86_64"},
+						},
 					},
 				},
 				Errors: []certification.Result{
@@ -78,6 +81,31 @@ var _ = Describe("JUnitXML Formatter", func() {
 						ElapsedTime: 0,
 					},
 				},
+				Skipped: []certification.Result{
+					{
+						Check: check.NewGenericCheck(
+							"SkippedCheck",
+							func(ctx context.Context, ir image.ImageReference) (bool, error) { return true, nil },
+							check.Metadata{},
+							check.HelpText{}),
+						SkipReason:  certification.SkipReasonUserExclusion,
+						SkipMessage: "excluded by the check selection",
+					},
+				},
+				TimedOut: []certification.Result{
+					*(&certification.Result{
+						Check: check.NewGenericCheck(
+							"TimedOutCheck",
+							func(ctx context.Context, ir image.ImageReference) (bool, error) { return false, nil },
+							check.Metadata{
+								Description: "description",
+							},
+							check.HelpText{
+								Message: "helptext",
+							}),
+						ElapsedTime: 0,
+					}).WithError(errors.New("check timed out after 1s")),
+				},
 				Warned: []certification.Result{
 					{
 						Check: check.NewGenericCheck(
@@ -118,6 +146,11 @@ var _ = Describe("JUnitXML Formatter", func() {
 			Expect(string(out)).To(ContainSubstring("PassedCheck"))
 			Expect(string(out)).To(ContainSubstring("FailedCheck"))
 			Expect(string(out)).To(ContainSubstring("ErroredCheck"))
+			Expect(string(out)).To(ContainSubstring("check timed out after 1s"))
+			Expect(string(out)).To(ContainSubstring(`failures="3"`))
+			Expect(string(out)).To(ContainSubstring(`skipped="1"`))
+			Expect(string(out)).To(ContainSubstring(`<skipped message="user exclusion: excluded by the check selection">`))
+			Expect(string(out)).To(ContainSubstring("[error] prohibited package found; package: kernel-5.14.0-1.el9.x86_64"))
 		})
 	})
 })
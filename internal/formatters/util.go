@@ -1,7 +1,10 @@
 package formatters
 
 import (
+	"sort"
+
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
 )
 
@@ -12,6 +15,8 @@ func getResponse(r certification.Results) UserResponse {
 	failedChecks := make([]checkExecutionInfo, 0, len(r.Failed))
 	erroredChecks := make([]checkExecutionInfo, 0, len(r.Errors))
 	warnedChecks := make([]checkExecutionInfo, 0, len(r.Warned))
+	timedOutChecks := make([]checkExecutionInfo, 0, len(r.TimedOut))
+	skippedChecks := make([]checkExecutionInfo, 0, len(r.Skipped))
 
 	if len(r.Passed) > 0 {
 		for _, check := range r.Passed {
@@ -19,6 +24,7 @@ func getResponse(r certification.Results) UserResponse {
 				Name:        check.Name(),
 				ElapsedTime: float64(check.ElapsedTime.Milliseconds()),
 				Description: check.Metadata().Description,
+				Findings:    check.Findings,
 			})
 		}
 	}
@@ -33,6 +39,7 @@ func getResponse(r certification.Results) UserResponse {
 				Suggestion:       check.Help().Suggestion,
 				KnowledgeBaseURL: check.Metadata().KnowledgeBaseURL,
 				CheckURL:         check.Metadata().CheckURL,
+				Findings:         check.Findings,
 			})
 		}
 	}
@@ -44,6 +51,7 @@ func getResponse(r certification.Results) UserResponse {
 				ElapsedTime: float64(check.ElapsedTime.Milliseconds()),
 				Description: check.Metadata().Description,
 				Help:        check.Help().Message,
+				Findings:    check.Findings,
 			})
 		}
 	}
@@ -58,6 +66,32 @@ func getResponse(r certification.Results) UserResponse {
 				Suggestion:       check.Help().Suggestion,
 				KnowledgeBaseURL: check.Metadata().KnowledgeBaseURL,
 				CheckURL:         check.Metadata().CheckURL,
+				Findings:         check.Findings,
+			})
+		}
+	}
+
+	if len(r.TimedOut) > 0 {
+		for _, check := range r.TimedOut {
+			timedOutChecks = append(timedOutChecks, checkExecutionInfo{
+				Name:        check.Name(),
+				ElapsedTime: float64(check.ElapsedTime.Milliseconds()),
+				Description: check.Metadata().Description,
+				Help:        check.Help().Message,
+				Findings:    check.Findings,
+			})
+		}
+	}
+
+	if len(r.Skipped) > 0 {
+		for _, check := range r.Skipped {
+			skippedChecks = append(skippedChecks, checkExecutionInfo{
+				Name:        check.Name(),
+				ElapsedTime: float64(check.ElapsedTime.Milliseconds()),
+				Description: check.Metadata().Description,
+				SkipReason:  check.SkipReason,
+				SkipMessage: check.SkipMessage,
+				Findings:    check.Findings,
 			})
 		}
 	}
@@ -67,32 +101,73 @@ func getResponse(r certification.Results) UserResponse {
 		Passed:            r.PassedOverall,
 		LibraryInfo:       version.Version,
 		CertificationHash: r.CertificationHash,
+		Partial:           r.Partial,
 		Results: resultsText{
 			Passed:   passedChecks,
 			Failed:   failedChecks,
 			Errors:   erroredChecks,
 			Warnings: warnedChecks,
+			TimedOut: timedOutChecks,
+			Skipped:  skippedChecks,
 		},
 	}
 
+	// Record what was run when it differs from the policy.
+	if r.Partial {
+		response.Selection = getSelection(r)
+	}
+
 	return response
 }
 
+// getSelection describes the checks run and any level overrides.
+func getSelection(r certification.Results) *selectionText {
+	overrides := make([]levelOverride, 0, len(r.LevelOverrides))
+	for name, level := range r.LevelOverrides {
+		overrides = append(overrides, levelOverride{Name: name, Level: level})
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].Name < overrides[j].Name
+	})
+
+	return &selectionText{
+		Checks:         r.Checks,
+		LevelOverrides: overrides,
+	}
+}
+
 // UserResponse is the standard user-facing response.
 type UserResponse struct {
 	Image             string                 `json:"image" xml:"image"`
 	Passed            bool                   `json:"passed" xml:"passed"`
 	CertificationHash string                 `json:"certification_hash,omitempty" xml:"certification_hash,omitempty"`
 	LibraryInfo       version.VersionContext `json:"test_library" xml:"test_library"`
+	Partial           bool                   `json:"partial,omitempty" xml:"partial,omitempty"`
+	Selection         *selectionText         `json:"selection,omitempty" xml:"selection,omitempty"`
 	Results           resultsText            `json:"results" xml:"results"`
 }
 
+// selectionText records the checks run when they differ from the policy's.
+// Such results are marked as partial, and cannot be submitted.
+type selectionText struct {
+	Checks         []string        `json:"checks" xml:"checks>check"`
+	LevelOverrides []levelOverride `json:"level_overrides,omitempty" xml:"level_overrides>override,omitempty"`
+}
+
+// levelOverride is a check whose level was changed by configuration.
+type levelOverride struct {
+	Name  string `json:"name" xml:"name"`
+	Level string `json:"level" xml:"level"`
+}
+
 // resultsText represents the results of check execution against the asset.
 type resultsText struct {
 	Passed   []checkExecutionInfo `json:"passed" xml:"passed"`
 	Failed   []checkExecutionInfo `json:"failed" xml:"failed"`
 	Errors   []checkExecutionInfo `json:"errors" xml:"errors"`
 	Warnings []checkExecutionInfo `json:"warning,omitempty" xml:"warning,omitempty"`
+	TimedOut []checkExecutionInfo `json:"timed_out,omitempty" xml:"timed_out,omitempty"`
+	Skipped  []checkExecutionInfo `json:"skipped,omitempty" xml:"skipped,omitempty"`
 }
 
 // checkExecutionInfo contains all possible output fields that a user might see in their result.
@@ -105,4 +180,9 @@ type checkExecutionInfo struct {
 	Suggestion       string  `json:"suggestion,omitempty" xml:"suggestion,omitempty"`
 	KnowledgeBaseURL string  `json:"knowledgebase_url,omitempty" xml:"knowledgebase_url,omitempty"`
 	CheckURL         string  `json:"check_url,omitempty" xml:"check_url,omitempty"`
+	// SkipReason and SkipMessage explain why a skipped check was not run, or not enforced.
+	SkipReason  string `json:"skip_reason,omitempty" xml:"skip_reason,omitempty"`
+	SkipMessage string `json:"skip_message,omitempty" xml:"skip_message,omitempty"`
+	// Findings details what the check detected, e.g. the files or packages to fix.
+	Findings []check.Finding `json:"findings,omitempty" xml:"findings>finding,omitempty"`
 }
@@ -0,0 +1,189 @@
+package image
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Revisions of the security.capability extended attribute, stored in the high
+// byte of its first field. See capabilities(7).
+const (
+	vfsCapRevision1 = 0x01000000
+	vfsCapRevision2 = 0x02000000
+	vfsCapRevision3 = 0x03000000
+
+	vfsCapRevisionMask   = 0xff000000
+	vfsCapFlagsEffective = 0x000001
+)
+
+// capabilityNames maps capability numbers to their names, as shown by getcap.
+var capabilityNames = []string{
+	"cap_chown",
+	"cap_dac_override",
+	"cap_dac_read_search",
+	"cap_fowner",
+	"cap_fsetid",
+	"cap_kill",
+	"cap_setgid",
+	"cap_setuid",
+	"cap_setpcap",
+	"cap_linux_immutable",
+	"cap_net_bind_service",
+	"cap_net_broadcast",
+	"cap_net_admin",
+	"cap_net_raw",
+	"cap_ipc_lock",
+	"cap_ipc_owner",
+	"cap_sys_module",
+	"cap_sys_rawio",
+	"cap_sys_chroot",
+	"cap_sys_ptrace",
+	"cap_sys_pacct",
+	"cap_sys_admin",
+	"cap_sys_boot",
+	"cap_sys_nice",
+	"cap_sys_resource",
+	"cap_sys_time",
+	"cap_sys_tty_config",
+	"cap_mknod",
+	"cap_lease",
+	"cap_audit_write",
+	"cap_audit_control",
+	"cap_setfcap",
+	"cap_mac_override",
+	"cap_mac_admin",
+	"cap_syslog",
+	"cap_wake_alarm",
+	"cap_block_suspend",
+	"cap_audit_read",
+	"cap_perfmon",
+	"cap_bpf",
+	"cap_checkpoint_restore",
+}
+
+// FileCapabilities are the capability sets granted to an executable by its
+// security.capability extended attribute.
+type FileCapabilities struct {
+	// Permitted and Inheritable contain the names of the capabilities in
+	// each set, e.g. cap_net_raw, in ascending order of their numbers.
+	Permitted   []string `json:"permitted,omitempty"`
+	Inheritable []string `json:"inheritable,omitempty"`
+	// Effective is true if the permitted capabilities are raised in the
+	// effective set when the file is executed.
+	Effective bool `json:"effective"`
+	// RootID is the user id of root in the user namespace the capabilities
+	// were set in. It is only recorded by revision 3 of the attribute.
+	RootID uint32 `json:"rootID,omitempty"`
+}
+
+// Names returns the capabilities in the permitted and inheritable sets,
+// without duplicates.
+func (c FileCapabilities) Names() []string {
+	names := append([]string(nil), c.Permitted...)
+	for _, name := range c.Inheritable {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// String formats c like getcap, e.g. cap_net_raw,cap_net_admin=ep.
+func (c FileCapabilities) String() string {
+	var clauses []string
+	flags := func(inheritable bool) string {
+		f := ""
+		if c.Effective {
+			f += "e"
+		}
+		if inheritable {
+			f += "i"
+		}
+		return f + "p"
+	}
+	var permittedOnly, both []string
+	for _, name := range c.Permitted {
+		if slices.Contains(c.Inheritable, name) {
+			both = append(both, name)
+		} else {
+			permittedOnly = append(permittedOnly, name)
+		}
+	}
+	if len(permittedOnly) > 0 {
+		clauses = append(clauses, strings.Join(permittedOnly, ",")+"="+flags(false))
+	}
+	if len(both) > 0 {
+		clauses = append(clauses, strings.Join(both, ",")+"="+flags(true))
+	}
+	var inheritableOnly []string
+	for _, name := range c.Inheritable {
+		if !slices.Contains(c.Permitted, name) {
+			inheritableOnly = append(inheritableOnly, name)
+		}
+	}
+	if len(inheritableOnly) > 0 {
+		clauses = append(clauses, strings.Join(inheritableOnly, ",")+"=i")
+	}
+	return strings.Join(clauses, " ")
+}
+
+// ParseFileCapabilities decodes the raw value of a security.capability
+// extended attribute, in any of the revisions supported by the kernel.
+func ParseFileCapabilities(raw []byte) (FileCapabilities, error) {
+	if len(raw) < 4 {
+		return FileCapabilities{}, fmt.Errorf("capability attribute is too short: %d bytes", len(raw))
+	}
+
+	magic := binary.LittleEndian.Uint32(raw)
+	var words, size int
+	switch magic & vfsCapRevisionMask {
+	case vfsCapRevision1:
+		words, size = 1, 12
+	case vfsCapRevision2:
+		words, size = 2, 20
+	case vfsCapRevision3:
+		words, size = 2, 24
+	default:
+		return FileCapabilities{}, fmt.Errorf("unknown capability attribute revision %#x", magic&vfsCapRevisionMask)
+	}
+	if len(raw) != size {
+		return FileCapabilities{}, fmt.Errorf("capability attribute has %d bytes, expected %d", len(raw), size)
+	}
+
+	// Each word holds a permitted and an inheritable mask of 32 capabilities.
+	var permitted, inheritable uint64
+	for i := 0; i < words; i++ {
+		offset := 4 + i*8
+		permitted |= uint64(binary.LittleEndian.Uint32(raw[offset:])) << (32 * i)
+		inheritable |= uint64(binary.LittleEndian.Uint32(raw[offset+4:])) << (32 * i)
+	}
+
+	caps := FileCapabilities{
+		Permitted:   capabilitySet(permitted),
+		Inheritable: capabilitySet(inheritable),
+		Effective:   magic&vfsCapFlagsEffective != 0,
+	}
+	if magic&vfsCapRevisionMask == vfsCapRevision3 {
+		caps.RootID = binary.LittleEndian.Uint32(raw[20:])
+	}
+	return caps, nil
+}
+
+// capabilitySet returns the names of the capabilities in mask. Capabilities
+// unknown to this version of preflight are named by number, e.g. cap_41.
+func capabilitySet(mask uint64) []string {
+	var names []string
+	for i := 0; i < 64; i++ {
+		if mask&(1<<i) == 0 {
+			continue
+		}
+		if i < len(capabilityNames) {
+			names = append(names, capabilityNames[i])
+		} else {
+			names = append(names, fmt.Sprintf("cap_%d", i))
+		}
+	}
+	return names
+}
@@ -0,0 +1,51 @@
+package image
+
+import (
+	"encoding/binary"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// capabilityXattr encodes a security.capability attribute of the given
+// revision, with fields in the order they are stored.
+func capabilityXattr(fields ...uint32) []byte {
+	raw := make([]byte, 0, len(fields)*4)
+	for _, f := range fields {
+		raw = binary.LittleEndian.AppendUint32(raw, f)
+	}
+	return raw
+}
+
+var _ = Describe("File capabilities", func() {
+	DescribeTable("should decode each revision of the attribute",
+		func(raw []byte, expected FileCapabilities, formatted string) {
+			caps, err := ParseFileCapabilities(raw)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(caps).To(Equal(expected))
+			Expect(caps.String()).To(Equal(formatted))
+		},
+		Entry("revision 1", capabilityXattr(vfsCapRevision1|vfsCapFlagsEffective, 1<<13, 0),
+			FileCapabilities{Permitted: []string{"cap_net_raw"}, Effective: true}, "cap_net_raw=ep"),
+		Entry("revision 2", capabilityXattr(vfsCapRevision2, 1<<10|1<<12, 1<<12, 1<<(38-32), 0),
+			FileCapabilities{Permitted: []string{"cap_net_bind_service", "cap_net_admin", "cap_perfmon"}, Inheritable: []string{"cap_net_admin"}},
+			"cap_net_bind_service,cap_perfmon=p cap_net_admin=ip"),
+		Entry("revision 3", capabilityXattr(vfsCapRevision3|vfsCapFlagsEffective, 1<<10, 0, 0, 0, 100000),
+			FileCapabilities{Permitted: []string{"cap_net_bind_service"}, Effective: true, RootID: 100000}, "cap_net_bind_service=ep"),
+	)
+
+	It("should name capabilities it does not know by number", func() {
+		caps, err := ParseFileCapabilities(capabilityXattr(vfsCapRevision2, 0, 0, 1<<(50-32), 0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(caps.Names()).To(Equal([]string{"cap_50"}))
+	})
+
+	It("should reject attributes of an unknown revision or size", func() {
+		_, err := ParseFileCapabilities(capabilityXattr(0x04000000, 0, 0))
+		Expect(err).To(HaveOccurred())
+		_, err = ParseFileCapabilities(capabilityXattr(vfsCapRevision2, 0, 0))
+		Expect(err).To(HaveOccurred())
+		_, err = ParseFileCapabilities([]byte{1})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,230 @@
+package image
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+)
+
+// Facts provides data about an image that is expensive to compute and needed by
+// more than one check or artifact writer. Each fact is computed the first time it
+// is requested and the result, including any error, is shared by all later callers.
+// Facts computed under a context are computed again if they failed once the
+// context ended, so one caller's timeout does not fail the others. Facts is safe
+// for concurrent use.
+type Facts struct {
+	img    v1.Image
+	fsPath string
+
+	config    memo[*v1.ConfigFile]
+	packages  memo[[]*rpmdb.PackageInfo]
+	layers    memo[[]Layer]
+	osRelease memo[map[string]string]
+	sbom      memo[any]
+}
+
+// Layer describes a single layer of an image.
+type Layer struct {
+	// Digest is the digest of the compressed layer.
+	Digest v1.Hash
+	// DiffID is the digest of the uncompressed layer.
+	DiffID v1.Hash
+	// Size is the size of the uncompressed layer, in bytes.
+	Size int64
+	// Files contains every entry in the layer's tar archive, in archive order,
+	// including whiteouts. Paths are absolute, e.g. /etc/.wh.passwd.
+	Files []FileInfo
+}
+
+// NewFacts returns a Facts for img, whose filesystem has been extracted to fsPath.
+func NewFacts(img v1.Image, fsPath string) *Facts {
+	return &Facts{img: img, fsPath: fsPath}
+}
+
+// Config returns the image's config file.
+func (f *Facts) Config() (*v1.ConfigFile, error) {
+	return f.config.get(func() (*v1.ConfigFile, error) {
+		if f.img == nil {
+			return nil, errors.New("image reference invalid")
+		}
+		return f.img.ConfigFile()
+	})
+}
+
+// Packages returns the packages in the rpm database of the extracted filesystem.
+// If the image has no rpm database, the error wraps os.ErrNotExist.
+func (f *Facts) Packages(ctx context.Context) ([]*rpmdb.PackageInfo, error) {
+	return f.packages.getContext(ctx, func() ([]*rpmdb.PackageInfo, error) {
+		return rpm.GetPackageList(ctx, f.fsPath)
+	})
+}
+
+// SBOM returns the image's software bill of materials, calling collect to
+// build it on first use. The SBOM is built by package sbom, which depends on
+// this package, so its type is left to the caller. collect is bound to ctx.
+func (f *Facts) SBOM(ctx context.Context, collect func() (any, error)) (any, error) {
+	return f.sbom.getContext(ctx, collect)
+}
+
+// Layers returns the image's layers from the base layer up, along with their
+// uncompressed sizes and file lists. All layers are read in a single pass.
+func (f *Facts) Layers() ([]Layer, error) {
+	return f.layers.get(func() ([]Layer, error) {
+		if f.img == nil {
+			return nil, errors.New("image reference invalid")
+		}
+		layers, err := f.img.Layers()
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]Layer, 0, len(layers))
+		for idx, layer := range layers {
+			l, err := readLayer(layer, idx)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, l)
+		}
+		return result, nil
+	})
+}
+
+// OSRelease returns the fields of the os-release file in the extracted filesystem,
+// with any quotes removed from the values. /etc/os-release is preferred over
+// /usr/lib/os-release.
+func (f *Facts) OSRelease() (map[string]string, error) {
+	return f.osRelease.get(func() (map[string]string, error) {
+		file, err := os.Open(filepath.Join(f.fsPath, "etc", "os-release"))
+		if errors.Is(err, os.ErrNotExist) {
+			file, err = os.Open(filepath.Join(f.fsPath, "usr", "lib", "os-release"))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not open os-release: %w", err)
+		}
+		defer file.Close()
+
+		return parseOSRelease(file)
+	})
+}
+
+// readLayer reads the uncompressed contents of layer, the index-th layer of the image.
+func readLayer(layer v1.Layer, index int) (Layer, error) {
+	digest, err := layer.Digest()
+	if err != nil {
+		return Layer{}, fmt.Errorf("unable to retrieve digest for layer: %w", err)
+	}
+	diffID, err := layer.DiffID()
+	if err != nil {
+		return Layer{}, fmt.Errorf("unable to retrieve diff id for layer: %w", err)
+	}
+
+	layerReader, err := layer.Uncompressed()
+	if err != nil {
+		return Layer{}, fmt.Errorf("reading layer contents: %w", err)
+	}
+	defer layerReader.Close()
+
+	counter := &countingReader{r: layerReader}
+	tarReader := tar.NewReader(counter)
+
+	var files []FileInfo
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Layer{}, fmt.Errorf("reading tar: %w", err)
+		}
+		files = append(files, FileInfoFromHeader(header, index))
+	}
+
+	// tar.Reader stops at the end-of-archive marker, so count any padding too.
+	if _, err := io.Copy(io.Discard, counter); err != nil {
+		return Layer{}, fmt.Errorf("could not copy from layer: %w", err)
+	}
+
+	return Layer{
+		Digest: digest,
+		DiffID: diffID,
+		Size:   counter.n,
+		Files:  files,
+	}, nil
+}
+
+// parseOSRelease parses the KEY=value lines of an os-release file.
+func parseOSRelease(r io.Reader) (map[string]string, error) {
+	fields := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		fields[key] = strings.Trim(value, `"'`)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error while scanning os-release: %w", err)
+	}
+	return fields, nil
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// memo holds a lazily computed value.
+type memo[T any] struct {
+	mu    sync.Mutex
+	done  bool
+	value T
+	err   error
+}
+
+// get returns the memoized value, calling fn to compute it on first use.
+func (m *memo[T]) get(fn func() (T, error)) (T, error) {
+	return m.getContext(context.Background(), fn)
+}
+
+// getContext is get for an fn bound to ctx. If fn fails after ctx has ended,
+// the error is returned without being memoized, and the next caller computes
+// the value again.
+func (m *memo[T]) getContext(ctx context.Context, fn func() (T, error)) (T, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.done {
+		return m.value, m.err
+	}
+
+	value, err := fn()
+	if err != nil && ctx.Err() != nil {
+		return value, err
+	}
+	m.value, m.err, m.done = value, err, true
+	return m.value, m.err
+}
@@ -0,0 +1,177 @@
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// countingImage counts the calls to ConfigFile.
+type countingImage struct {
+	v1.Image
+	mu          sync.Mutex
+	configCalls int
+}
+
+func (c *countingImage) ConfigFile() (*v1.ConfigFile, error) {
+	c.mu.Lock()
+	c.configCalls++
+	c.mu.Unlock()
+	return c.Image.ConfigFile()
+}
+
+func layerWithFiles(files map[string]string) v1.Layer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, contents := range files {
+		Expect(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o644, Size: int64(len(contents))})).To(Succeed())
+		_, err := tw.Write([]byte(contents))
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+
+	raw := buf.Bytes()
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(raw)), nil
+	})
+	Expect(err).ToNot(HaveOccurred())
+	return layer
+}
+
+var _ = Describe("Image facts", func() {
+	var (
+		img    *countingImage
+		fsPath string
+		facts  *Facts
+	)
+
+	BeforeEach(func() {
+		base, err := mutate.AppendLayers(empty.Image,
+			layerWithFiles(map[string]string{"etc/os-release": "ID=rhel\n"}),
+			layerWithFiles(map[string]string{"etc/.wh.os-release": "", "app/bin": "binary"}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		img = &countingImage{Image: base}
+
+		fsPath = GinkgoT().TempDir()
+		facts = NewFacts(img, fsPath)
+	})
+
+	It("should compute the config once for concurrent callers", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := facts.Config()
+				Expect(err).ToNot(HaveOccurred())
+			}()
+		}
+		wg.Wait()
+		Expect(img.configCalls).To(Equal(1))
+	})
+
+	It("should list the files and uncompressed size of each layer", func() {
+		layers, err := facts.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(layers).To(HaveLen(2))
+
+		Expect(layers[0].Files).To(HaveLen(1))
+		Expect(layers[0].Files[0].Path).To(Equal("/etc/os-release"))
+		Expect(layers[0].Files[0].Layer).To(Equal(0))
+
+		var paths []string
+		for _, f := range layers[1].Files {
+			paths = append(paths, f.Path)
+			Expect(f.Layer).To(Equal(1))
+		}
+		Expect(paths).To(ConsistOf("/etc/.wh.os-release", "/app/bin"))
+
+		config, err := facts.Config()
+		Expect(err).ToNot(HaveOccurred())
+		for i, layer := range layers {
+			Expect(layer.DiffID).To(Equal(config.RootFS.DiffIDs[i]))
+			// tar archives are padded to a multiple of the record size
+			Expect(layer.Size % 512).To(BeZero())
+			Expect(layer.Size).To(BeNumerically(">", 0))
+		}
+	})
+
+	It("should parse os-release, falling back to /usr/lib/os-release", func() {
+		Expect(os.MkdirAll(filepath.Join(fsPath, "usr", "lib"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(fsPath, "usr", "lib", "os-release"), []byte(
+			"# comment\nNAME=\"Red Hat Enterprise Linux\"\nPLATFORM_ID=\"platform:el9\"\nID='rhel'\n"), 0o644)).To(Succeed())
+
+		osRelease, err := facts.OSRelease()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(osRelease).To(HaveKeyWithValue("NAME", "Red Hat Enterprise Linux"))
+		Expect(osRelease).To(HaveKeyWithValue("PLATFORM_ID", "platform:el9"))
+		Expect(osRelease).To(HaveKeyWithValue("ID", "rhel"))
+	})
+
+	It("should remember errors", func() {
+		_, err := facts.OSRelease()
+		Expect(err).To(MatchError(os.ErrNotExist))
+
+		// Creating the file afterwards does not change the fact.
+		Expect(os.MkdirAll(filepath.Join(fsPath, "etc"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(fsPath, "etc", "os-release"), []byte("ID=rhel\n"), 0o644)).To(Succeed())
+		_, err = facts.OSRelease()
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+
+	It("should build the SBOM once", func() {
+		calls := 0
+		collect := func() (any, error) {
+			calls++
+			return "sbom", nil
+		}
+		for i := 0; i < 2; i++ {
+			s, err := facts.SBOM(context.TODO(), collect)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s).To(Equal("sbom"))
+		}
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should not remember errors of a caller whose context ended", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		_, err := facts.SBOM(ctx, func() (any, error) { return nil, ctx.Err() })
+		Expect(err).To(MatchError(context.Canceled))
+
+		s, err := facts.SBOM(context.TODO(), func() (any, error) { return "sbom", nil })
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s).To(Equal("sbom"))
+	})
+
+	It("should report a missing rpm database", func() {
+		_, err := facts.Packages(context.TODO())
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+
+	When("no facts were provided", func() {
+		It("should create unshared facts for the image reference", func() {
+			ref := ImageReference{ImageInfo: img, ImageFSPath: fsPath}
+			Expect(ref.ImageFacts()).ToNot(BeNil())
+			Expect(ref.ImageFacts()).ToNot(BeIdenticalTo(ref.ImageFacts()))
+
+			ref.Facts = facts
+			Expect(ref.ImageFacts()).To(BeIdenticalTo(facts))
+		})
+	})
+})
@@ -0,0 +1,206 @@
+package image
+
+import (
+	"archive/tar"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// XattrCapability is the extended attribute holding a file's capabilities.
+const XattrCapability = "security.capability"
+
+// paxXattrPrefix is the PAX record prefix used by tar to store extended attributes.
+const paxXattrPrefix = "SCHILY.xattr."
+
+// FileInfo describes a file in the image's filesystem as recorded in the image
+// layers. Unlike the extracted filesystem on disk, it retains ownership, the
+// setuid, setgid and sticky bits, extended attributes, and entries such as
+// device nodes that cannot be created without privileges.
+type FileInfo struct {
+	// Path is the absolute path of the file in the image, e.g. /usr/bin/sudo.
+	Path string `json:"path"`
+	// Mode contains the file's type and permission bits, including
+	// fs.ModeSetuid, fs.ModeSetgid and fs.ModeSticky.
+	Mode fs.FileMode `json:"mode"`
+	// UID is the numeric user id of the file's owner.
+	UID int `json:"uid"`
+	// GID is the numeric group id of the file's group.
+	GID int `json:"gid"`
+	// Uname is the user name of the file's owner, if recorded in the layer.
+	Uname string `json:"uname,omitempty"`
+	// Gname is the group name of the file's group, if recorded in the layer.
+	Gname string `json:"gname,omitempty"`
+	// Size is the size of a regular file, in bytes.
+	Size int64 `json:"size"`
+	// Linkname is the target of a symbolic or hard link. Hard link targets
+	// are absolute paths in the image.
+	Linkname string `json:"linkname,omitempty"`
+	// HardLink is true if the file is a hard link to Linkname.
+	HardLink bool `json:"hardLink,omitempty"`
+	// Devmajor and Devminor identify a character or block device.
+	Devmajor int64 `json:"devmajor,omitempty"`
+	Devminor int64 `json:"devminor,omitempty"`
+	// Xattrs contains the file's extended attributes, e.g. security.capability.
+	Xattrs map[string][]byte `json:"xattrs,omitempty"`
+	// Layer is the index of the layer that last wrote the file, where
+	// 0 is the base layer.
+	Layer int `json:"layer"`
+}
+
+// Capability returns the raw security.capability extended attribute, or nil
+// if the file has no capabilities.
+func (f FileInfo) Capability() []byte {
+	return f.Xattrs[XattrCapability]
+}
+
+// FileInfoFromHeader converts a tar header in a flattened image filesystem to
+// a FileInfo, recording that it was written by layer.
+func FileInfoFromHeader(header *tar.Header, layer int) FileInfo {
+	info := FileInfo{
+		Path:     CleanPath(header.Name),
+		Mode:     header.FileInfo().Mode(),
+		UID:      header.Uid,
+		GID:      header.Gid,
+		Uname:    header.Uname,
+		Gname:    header.Gname,
+		Devmajor: header.Devmajor,
+		Devminor: header.Devminor,
+		Layer:    layer,
+	}
+
+	switch header.Typeflag {
+	case tar.TypeReg:
+		info.Size = header.Size
+	case tar.TypeSymlink:
+		info.Linkname = header.Linkname
+	case tar.TypeLink:
+		info.Linkname = CleanPath(header.Linkname)
+		info.HardLink = true
+	}
+
+	for k, v := range header.PAXRecords {
+		if name, ok := strings.CutPrefix(k, paxXattrPrefix); ok {
+			if info.Xattrs == nil {
+				info.Xattrs = map[string][]byte{}
+			}
+			info.Xattrs[name] = []byte(v)
+		}
+	}
+
+	return info
+}
+
+// CleanPath returns name as an absolute, cleaned path in the image.
+func CleanPath(name string) string {
+	return path.Clean("/" + name)
+}
+
+// FileIndex holds the metadata of every file in an image's filesystem. It is
+// populated during extraction and is safe for concurrent reads afterwards.
+type FileIndex struct {
+	files map[string]FileInfo
+}
+
+// NewFileIndex returns an empty FileIndex.
+func NewFileIndex() *FileIndex {
+	return &FileIndex{files: map[string]FileInfo{}}
+}
+
+// Add records info, replacing any existing entry for the same path.
+func (i *FileIndex) Add(info FileInfo) {
+	i.files[CleanPath(info.Path)] = info
+}
+
+// Lookup returns the metadata recorded for path.
+func (i *FileIndex) Lookup(path string) (FileInfo, bool) {
+	if i == nil {
+		return FileInfo{}, false
+	}
+	info, ok := i.files[CleanPath(path)]
+	return info, ok
+}
+
+// Len returns the number of files in the index.
+func (i *FileIndex) Len() int {
+	if i == nil {
+		return 0
+	}
+	return len(i.files)
+}
+
+// Walk calls fn for every file in the index, in lexical order by path. If fn
+// returns an error, walking stops and the error is returned.
+func (i *FileIndex) Walk(fn func(FileInfo) error) error {
+	if i == nil {
+		return nil
+	}
+	paths := make([]string, 0, len(i.files))
+	for p := range i.files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		if err := fn(i.files[p]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxSymlinkHops bounds how many symbolic links Resolve follows.
+const maxSymlinkHops = 40
+
+// Resolve returns the file at path, following symbolic links in every
+// component of the path without leaving the image's filesystem, as the kernel
+// would within the container. Intermediate directories missing from the index
+// are assumed to exist, as layers need not record them. Resolve returns an
+// error wrapping fs.ErrNotExist if the file does not exist.
+func (i *FileIndex) Resolve(p string) (FileInfo, error) {
+	remaining := strings.Split(strings.TrimPrefix(CleanPath(p), "/"), "/")
+	current := "/"
+	hops := 0
+	for len(remaining) > 0 {
+		component := remaining[0]
+		remaining = remaining[1:]
+		switch component {
+		case "", ".":
+			continue
+		case "..":
+			current = path.Dir(current)
+			continue
+		}
+
+		next := path.Join(current, component)
+		info, ok := i.Lookup(next)
+		if !ok {
+			if len(remaining) == 0 {
+				return FileInfo{}, fmt.Errorf("%s: %w", p, fs.ErrNotExist)
+			}
+			current = next
+			continue
+		}
+		if info.Mode&fs.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+
+		hops++
+		if hops > maxSymlinkHops {
+			return FileInfo{}, fmt.Errorf("%s: too many levels of symbolic links", p)
+		}
+		if path.IsAbs(info.Linkname) {
+			current = "/"
+		}
+		remaining = append(strings.Split(info.Linkname, "/"), remaining...)
+	}
+
+	info, ok := i.Lookup(current)
+	if !ok {
+		return FileInfo{}, fmt.Errorf("%s: %w", p, fs.ErrNotExist)
+	}
+	return info, nil
+}
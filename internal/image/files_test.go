@@ -0,0 +1,84 @@
+package image
+
+import (
+	"archive/tar"
+	"io/fs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File index", func() {
+	var index *FileIndex
+
+	BeforeEach(func() {
+		index = NewFileIndex()
+		index.Add(FileInfoFromHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "./usr/bin/b", Mode: 0o2755, Size: 3}, 1))
+		index.Add(FileInfoFromHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "usr/bin/a", Linkname: "b"}, 0))
+		index.Add(FileInfoFromHeader(&tar.Header{Typeflag: tar.TypeLink, Name: "usr/bin/c", Linkname: "usr/bin/b"}, 0))
+	})
+
+	It("should normalize paths on lookup", func() {
+		b, ok := index.Lookup("usr/bin/../bin/b")
+		Expect(ok).To(BeTrue())
+		Expect(b.Path).To(Equal("/usr/bin/b"))
+		Expect(b.Mode & fs.ModeSetgid).ToNot(BeZero())
+		Expect(b.Size).To(BeEquivalentTo(3))
+	})
+
+	It("should record link targets", func() {
+		a, _ := index.Lookup("/usr/bin/a")
+		Expect(a.Mode & fs.ModeSymlink).ToNot(BeZero())
+		Expect(a.Linkname).To(Equal("b"))
+
+		c, _ := index.Lookup("/usr/bin/c")
+		Expect(c.HardLink).To(BeTrue())
+		Expect(c.Linkname).To(Equal("/usr/bin/b"))
+	})
+
+	It("should walk files in order", func() {
+		var paths []string
+		Expect(index.Walk(func(f FileInfo) error {
+			paths = append(paths, f.Path)
+			return nil
+		})).To(Succeed())
+		Expect(paths).To(Equal([]string{"/usr/bin/a", "/usr/bin/b", "/usr/bin/c"}))
+		Expect(index.Len()).To(Equal(3))
+	})
+
+	It("should treat a nil index as empty", func() {
+		var empty *FileIndex
+		_, ok := empty.Lookup("/usr/bin/a")
+		Expect(ok).To(BeFalse())
+		Expect(empty.Len()).To(BeZero())
+	})
+
+	Describe("resolving paths", func() {
+		BeforeEach(func() {
+			index.Add(FileInfoFromHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "bin", Linkname: "usr/bin"}, 0))
+			index.Add(FileInfoFromHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "usr/local/bin/d", Linkname: "../../bin/a"}, 0))
+			index.Add(FileInfoFromHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "usr/bin/loop", Linkname: "/usr/bin/loop"}, 0))
+		})
+
+		It("should follow symbolic links in every component", func() {
+			b, err := index.Resolve("/bin/a")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.Path).To(Equal("/usr/bin/b"))
+
+			d, err := index.Resolve("/usr/local/bin/d")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(d.Path).To(Equal("/usr/bin/b"))
+		})
+
+		It("should report missing files", func() {
+			_, err := index.Resolve("/bin/missing")
+			Expect(err).To(MatchError(fs.ErrNotExist))
+		})
+
+		It("should stop following symbolic link loops", func() {
+			_, err := index.Resolve("/usr/bin/loop")
+			Expect(err).To(HaveOccurred())
+			Expect(err).ToNot(MatchError(fs.ErrNotExist))
+		})
+	})
+})
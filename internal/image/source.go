@@ -0,0 +1,333 @@
+package image
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// Transports that identify an image reference as a local source, as opposed
+// to an image in a container registry. These follow the same naming used by
+// skopeo and podman.
+const (
+	TransportOCILayout     = "oci"
+	TransportOCIArchive    = "oci-archive"
+	TransportDockerArchive = "docker-archive"
+)
+
+// ociRefNameAnnotation is the annotation used in an OCI layout's index.json to
+// name a manifest.
+const ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// LocalSource is an image that is read from the local filesystem instead of
+// being pulled from a registry. References take the form transport:path[:reference],
+// e.g. oci:/path/to/layout:v1.0 or docker-archive:/path/to/image.tar.
+type LocalSource struct {
+	// Transport is one of the Transport* constants.
+	Transport string
+	// Path is the location of the layout directory or archive on disk.
+	Path string
+	// Reference optionally selects an image within the source. For OCI
+	// layouts, this is matched against the manifest's ref.name annotation.
+	// For docker archives, this is a tag in the archive's manifest.
+	Reference string
+
+	// layoutPath is where the OCI layout can be read from. For oci-archive
+	// sources, this is a temporary directory.
+	layoutPath string
+	// tmpdir is removed on Close.
+	tmpdir string
+}
+
+// IsLocalSource returns true if ref uses a local transport prefix.
+func IsLocalSource(ref string) bool {
+	_, ok := ParseLocalSource(ref)
+	return ok
+}
+
+// ParseLocalSource splits ref into its transport, path, and optional reference.
+// The boolean is false if ref does not use a local transport.
+func ParseLocalSource(ref string) (LocalSource, bool) {
+	transport, rest, found := strings.Cut(ref, ":")
+	if !found {
+		return LocalSource{}, false
+	}
+
+	switch transport {
+	case TransportOCILayout, TransportOCIArchive, TransportDockerArchive:
+	default:
+		return LocalSource{}, false
+	}
+
+	path, reference, _ := strings.Cut(rest, ":")
+	return LocalSource{
+		Transport: transport,
+		Path:      path,
+		Reference: reference,
+	}, true
+}
+
+// OpenLocalSource parses ref and prepares the source for reading. Callers
+// must call Close when the images read from the source are no longer needed.
+func OpenLocalSource(ref string) (*LocalSource, error) {
+	src, ok := ParseLocalSource(ref)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a local image reference", ref)
+	}
+	if src.Path == "" {
+		return nil, fmt.Errorf("local image reference %s has no path", ref)
+	}
+	if _, err := os.Stat(src.Path); err != nil {
+		return nil, fmt.Errorf("could not read local image source: %w", err)
+	}
+
+	switch src.Transport {
+	case TransportOCILayout:
+		src.layoutPath = src.Path
+	case TransportOCIArchive:
+		tmpdir, err := os.MkdirTemp(os.TempDir(), "preflight-oci-archive-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary directory: %v", err)
+		}
+		src.tmpdir = tmpdir
+		src.layoutPath = tmpdir
+		if err := extractOCIArchive(src.Path, tmpdir); err != nil {
+			_ = src.Close()
+			return nil, fmt.Errorf("could not extract oci archive %s: %w", src.Path, err)
+		}
+	}
+
+	return &src, nil
+}
+
+// Close releases any temporary resources held by the source.
+func (s *LocalSource) Close() error {
+	if s.tmpdir == "" {
+		return nil
+	}
+	return os.RemoveAll(s.tmpdir)
+}
+
+// Index returns the image index the source refers to. If the source refers
+// to a single image, the returned index is nil.
+func (s *LocalSource) Index() (v1.ImageIndex, error) {
+	if s.Transport == TransportDockerArchive {
+		return nil, nil
+	}
+
+	idx, desc, err := s.selectDescriptor()
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MediaType.IsIndex() {
+		return nil, nil
+	}
+
+	return idx.ImageIndex(desc.Digest)
+}
+
+// Image returns the image for platform. If the source refers to an image
+// index, the manifest matching linux/platform is selected.
+func (s *LocalSource) Image(platform string) (v1.Image, error) {
+	if s.Transport == TransportDockerArchive {
+		var tag *name.Tag
+		if s.Reference != "" {
+			t, err := s.dockerArchiveTag()
+			if err != nil {
+				return nil, err
+			}
+			tag = t
+		}
+		return tarball.ImageFromPath(s.Path, tag)
+	}
+
+	idx, desc, err := s.selectDescriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	if desc.MediaType.IsImage() {
+		return idx.Image(desc.Digest)
+	}
+
+	child, err := idx.ImageIndex(desc.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("could not read image index %s: %w", desc.Digest, err)
+	}
+	manifest, err := child.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("could not read image index manifest: %w", err)
+	}
+	for _, m := range manifest.Manifests {
+		if m.Platform == nil || !m.MediaType.IsImage() {
+			continue
+		}
+		if m.Platform.OS == "linux" && m.Platform.Architecture == platform {
+			return child.Image(m.Digest)
+		}
+	}
+
+	return nil, fmt.Errorf("no image found for platform linux/%s in %s", platform, s.Path)
+}
+
+// dockerArchiveTag returns the tag selecting the image in the docker archive
+// that the source's Reference refers to. The Reference is matched as written
+// against the RepoTags in the archive's manifest first, and then against their
+// normalized forms, so a bare tag such as app:1.0 matches however it was
+// saved, including under localhost/ as podman does. The returned tag is nil if
+// the archive only holds that image.
+func (s *LocalSource) dockerArchiveTag() (*name.Tag, error) {
+	manifest, err := tarball.LoadManifest(func() (io.ReadCloser, error) { return os.Open(s.Path) })
+	if err != nil {
+		return nil, fmt.Errorf("could not read docker archive manifest: %w", err)
+	}
+
+	candidates := []string{s.Reference}
+	if !strings.HasPrefix(s.Reference, "localhost/") {
+		candidates = append(candidates, "localhost/"+s.Reference)
+	}
+	normalized := func(ref string) string {
+		tag, err := name.NewTag(ref)
+		if err != nil {
+			return ""
+		}
+		return tag.Name()
+	}
+	matchers := []func(repoTag string) bool{
+		func(repoTag string) bool { return slices.Contains(candidates, repoTag) },
+		func(repoTag string) bool {
+			n := normalized(repoTag)
+			return n != "" && slices.ContainsFunc(candidates, func(c string) bool { return normalized(c) == n })
+		},
+	}
+	for _, matches := range matchers {
+		for _, desc := range manifest {
+			for _, repoTag := range desc.RepoTags {
+				if !matches(repoTag) {
+					continue
+				}
+				if len(manifest) == 1 {
+					return nil, nil
+				}
+				tag, err := name.NewTag(repoTag)
+				if err != nil {
+					return nil, fmt.Errorf("invalid tag %s in docker archive: %w", repoTag, err)
+				}
+				return &tag, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("tag %s not found in docker archive %s", s.Reference, s.Path)
+}
+
+// NamedReference returns the registry reference the source was tagged with,
+// if the source's Reference is a fully qualified image reference. Bare tags
+// (e.g. "v1.0") do not identify a repository and return false.
+func (s *LocalSource) NamedReference() (name.Reference, bool) {
+	if !strings.Contains(s.Reference, "/") {
+		return nil, false
+	}
+	ref, err := name.ParseReference(s.Reference)
+	if err != nil {
+		return nil, false
+	}
+	return ref, true
+}
+
+// Tag returns the tag portion of the source's Reference, or an empty string
+// if no tag was provided.
+func (s *LocalSource) Tag() string {
+	if ref, ok := s.NamedReference(); ok {
+		return ref.Identifier()
+	}
+	return s.Reference
+}
+
+// selectDescriptor finds the manifest in the OCI layout's top-level index that
+// matches the source's Reference. If no Reference was provided, the layout
+// must contain exactly one manifest.
+func (s *LocalSource) selectDescriptor() (v1.ImageIndex, v1.Descriptor, error) {
+	idx, err := layout.ImageIndexFromPath(s.layoutPath)
+	if err != nil {
+		return nil, v1.Descriptor{}, fmt.Errorf("could not read oci layout %s: %w", s.Path, err)
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, v1.Descriptor{}, fmt.Errorf("could not read oci layout index: %w", err)
+	}
+
+	if s.Reference == "" {
+		if len(manifest.Manifests) != 1 {
+			return nil, v1.Descriptor{}, fmt.Errorf("oci layout %s contains %d manifests: a reference must be provided as %s:%s:<reference>",
+				s.Path, len(manifest.Manifests), s.Transport, s.Path)
+		}
+		return idx, manifest.Manifests[0], nil
+	}
+
+	for _, m := range manifest.Manifests {
+		refName := m.Annotations[ociRefNameAnnotation]
+		if refName == s.Reference || strings.HasSuffix(refName, ":"+s.Reference) {
+			return idx, m, nil
+		}
+	}
+
+	return nil, v1.Descriptor{}, fmt.Errorf("reference %s not found in oci layout %s", s.Reference, s.Path)
+}
+
+// extractOCIArchive expands the tarball at src into dst. OCI layouts only
+// contain directories and regular files, so all other entries are ignored.
+func extractOCIArchive(src, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Clean(header.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive entry %s would be extracted outside of the destination", header.Name)
+		}
+		target := filepath.Join(dst, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			out.Close()
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Local image sources", func() {
+	DescribeTable("parsing references",
+		func(ref string, expected LocalSource, ok bool) {
+			src, isLocal := ParseLocalSource(ref)
+			Expect(isLocal).To(Equal(ok))
+			Expect(src).To(Equal(expected))
+		},
+		Entry("oci layout", "oci:/tmp/layout", LocalSource{Transport: TransportOCILayout, Path: "/tmp/layout"}, true),
+		Entry("oci layout with tag", "oci:/tmp/layout:v1", LocalSource{Transport: TransportOCILayout, Path: "/tmp/layout", Reference: "v1"}, true),
+		Entry("oci archive", "oci-archive:/tmp/image.tar", LocalSource{Transport: TransportOCIArchive, Path: "/tmp/image.tar"}, true),
+		Entry("docker archive with full reference", "docker-archive:/tmp/image.tar:quay.io/foo/bar:v1",
+			LocalSource{Transport: TransportDockerArchive, Path: "/tmp/image.tar", Reference: "quay.io/foo/bar:v1"}, true),
+		Entry("registry reference", "quay.io/foo/bar:v1", LocalSource{}, false),
+		Entry("registry reference with port", "localhost:5000/foo/bar:v1", LocalSource{}, false),
+		Entry("no transport", "bar", LocalSource{}, false),
+	)
+
+	Context("reading an OCI layout", func() {
+		var layoutDir string
+		var img v1.Image
+		BeforeEach(func() {
+			var err error
+			img, err = random.Image(1024, 2)
+			Expect(err).ToNot(HaveOccurred())
+
+			layoutDir = GinkgoT().TempDir()
+			p, err := layout.Write(layoutDir, empty.Index)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.AppendImage(img, layout.WithAnnotations(map[string]string{ociRefNameAnnotation: "v1"}))).To(Succeed())
+		})
+
+		It("should return the image when no reference is given", func() {
+			src, err := OpenLocalSource("oci:" + layoutDir)
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(src.Close)
+
+			idx, err := src.Index()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(idx).To(BeNil())
+
+			loaded, err := src.Image("amd64")
+			Expect(err).ToNot(HaveOccurred())
+			expected, _ := img.Digest()
+			actual, _ := loaded.Digest()
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("should return the image matching the reference", func() {
+			src, err := OpenLocalSource("oci:" + layoutDir + ":v1")
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(src.Close)
+
+			_, err = src.Image("amd64")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(src.Tag()).To(Equal("v1"))
+			_, named := src.NamedReference()
+			Expect(named).To(BeFalse())
+		})
+
+		It("should fail when the reference is not in the layout", func() {
+			src, err := OpenLocalSource("oci:" + layoutDir + ":nope")
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(src.Close)
+
+			_, err = src.Image("amd64")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should read the same layout from an oci-archive", func() {
+			archive := filepath.Join(GinkgoT().TempDir(), "image.tar")
+			Expect(tarDirectory(layoutDir, archive)).To(Succeed())
+
+			src, err := OpenLocalSource("oci-archive:" + archive)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = src.Image("amd64")
+			Expect(err).ToNot(HaveOccurred())
+
+			tmpdir := src.tmpdir
+			Expect(src.Close()).To(Succeed())
+			Expect(tmpdir).ToNot(BeADirectory())
+		})
+	})
+
+	Context("reading an OCI layout containing an index", func() {
+		var layoutDir string
+		BeforeEach(func() {
+			amd, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+			idx := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{
+				Add: amd,
+				Descriptor: v1.Descriptor{
+					Platform: &v1.Platform{OS: "linux", Architecture: "amd64"},
+				},
+			})
+
+			layoutDir = GinkgoT().TempDir()
+			p, err := layout.Write(layoutDir, empty.Index)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.AppendIndex(idx)).To(Succeed())
+		})
+
+		It("should return the index and select images by platform", func() {
+			src, err := OpenLocalSource("oci:" + layoutDir)
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(src.Close)
+
+			idx, err := src.Index()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(idx).ToNot(BeNil())
+
+			_, err = src.Image("amd64")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = src.Image("s390x")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("reading a docker archive", func() {
+		var archive string
+		BeforeEach(func() {
+			img, err := random.Image(1024, 2)
+			Expect(err).ToNot(HaveOccurred())
+			tag, err := name.NewTag("quay.io/foo/bar:v1")
+			Expect(err).ToNot(HaveOccurred())
+
+			archive = filepath.Join(GinkgoT().TempDir(), "image.tar")
+			Expect(tarball.WriteToFile(archive, tag, img)).To(Succeed())
+		})
+
+		It("should load the image without a reference", func() {
+			src, err := OpenLocalSource("docker-archive:" + archive)
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(src.Close)
+
+			_, err = src.Image("amd64")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should expose a fully qualified reference", func() {
+			src, err := OpenLocalSource("docker-archive:" + archive + ":quay.io/foo/bar:v1")
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(src.Close)
+
+			_, err = src.Image("amd64")
+			Expect(err).ToNot(HaveOccurred())
+
+			ref, named := src.NamedReference()
+			Expect(named).To(BeTrue())
+			Expect(ref.Context().RepositoryStr()).To(Equal("foo/bar"))
+			Expect(src.Tag()).To(Equal("v1"))
+		})
+	})
+
+	Context("reading a docker archive saved with bare tags", func() {
+		var archive string
+		var app, other v1.Image
+		BeforeEach(func() {
+			var err error
+			app, err = random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+			other, err = random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+			appTag, err := name.NewTag("app:1.0")
+			Expect(err).ToNot(HaveOccurred())
+			otherTag, err := name.NewTag("other:2.0")
+			Expect(err).ToNot(HaveOccurred())
+
+			archive = filepath.Join(GinkgoT().TempDir(), "image.tar")
+			Expect(tarball.MultiRefWriteToFile(archive, map[name.Reference]v1.Image{appTag: app, otherTag: other})).To(Succeed())
+			Expect(setDockerArchiveRepoTags(archive, map[string]string{
+				appTag.String():   "app:1.0",
+				otherTag.String(): "localhost/other:2.0",
+			})).To(Succeed())
+		})
+
+		DescribeTable("should select the image matching the reference",
+			func(reference string, expected func() v1.Image) {
+				src, err := OpenLocalSource("docker-archive:" + archive + ":" + reference)
+				Expect(err).ToNot(HaveOccurred())
+				DeferCleanup(src.Close)
+
+				img, err := src.Image("amd64")
+				Expect(err).ToNot(HaveOccurred())
+				digest, err := img.Digest()
+				Expect(err).ToNot(HaveOccurred())
+				expectedDigest, err := expected().Digest()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(digest).To(Equal(expectedDigest))
+			},
+			Entry("bare repo:tag", "app:1.0", func() v1.Image { return app }),
+			Entry("normalized repo:tag", "docker.io/library/app:1.0", func() v1.Image { return app }),
+			Entry("repo:tag saved under localhost", "other:2.0", func() v1.Image { return other }),
+		)
+
+		It("should fail when the tag is not in the archive", func() {
+			src, err := OpenLocalSource("docker-archive:" + archive + ":app:2.0")
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(src.Close)
+
+			_, err = src.Image("amd64")
+			Expect(err).To(MatchError(ContainSubstring("tag app:2.0 not found")))
+		})
+	})
+
+	When("the path does not exist", func() {
+		It("should return an error", func() {
+			_, err := OpenLocalSource("oci:/does/not/exist")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the oci-archive contains an entry outside of the archive", func() {
+		It("should return an error", func() {
+			archive := filepath.Join(GinkgoT().TempDir(), "image.tar")
+			f, err := os.Create(archive)
+			Expect(err).ToNot(HaveOccurred())
+			tw := tar.NewWriter(f)
+			Expect(tw.WriteHeader(&tar.Header{Name: "../escape", Typeflag: tar.TypeReg, Mode: 0o644})).To(Succeed())
+			Expect(tw.Close()).To(Succeed())
+			Expect(f.Close()).To(Succeed())
+
+			_, err = OpenLocalSource("oci-archive:" + archive)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+// setDockerArchiveRepoTags rewrites the RepoTags in the manifest of the docker
+// archive at path, replacing each tag in repoTags with its value.
+func setDockerArchiveRepoTags(path string, repoTags map[string]string) error {
+	manifest, err := tarball.LoadManifest(func() (io.ReadCloser, error) { return os.Open(path) })
+	if err != nil {
+		return err
+	}
+	for i := range manifest {
+		for j, tag := range manifest[i].RepoTags {
+			if replacement, ok := repoTags[tag]; ok {
+				manifest[i].RepoTags[j] = replacement
+			}
+		}
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	tr := tar.NewReader(bytes.NewReader(in))
+	tw := tar.NewWriter(out)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		var content io.Reader = tr
+		if header.Name == "manifest.json" {
+			header.Size = int64(len(manifestJSON))
+			content = bytes.NewReader(manifestJSON)
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, content); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// tarDirectory writes the contents of dir to a tarball at dst.
+func tarDirectory(dir, dst string) error {
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	tw := tar.NewWriter(out)
+	defer tw.Close()
+
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = rel
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
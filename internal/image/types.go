@@ -11,4 +11,28 @@ type ImageReference struct {
 	ImageRegistry      string
 	ImageTagOrSha      string
 	ManifestListDigest string
+	// Local is true when the image was read from the local filesystem
+	// (e.g. an OCI layout or docker archive) instead of a registry.
+	Local bool
+	// Files holds the metadata of every file in the image's filesystem, as
+	// recorded in the image layers. It is populated when the image is extracted.
+	Files *FileIndex
+	// Facts provides data about the image shared by all checks and artifact
+	// writers in a run. Use ImageFacts to read it.
+	Facts *Facts
+}
+
+// ImageFacts returns the facts shared for the image. If none were provided, an
+// unshared Facts is returned so callers need not check for nil.
+func (r ImageReference) ImageFacts() *Facts {
+	if r.Facts != nil {
+		return r.Facts
+	}
+	return NewFacts(r.ImageInfo, r.ImageFSPath)
+}
+
+// SupportedArchitectures returns the container architectures that are
+// supported for certification.
+func SupportedArchitectures() []string {
+	return []string{"arm64", "amd64", "ppc64le", "s390x"}
 }
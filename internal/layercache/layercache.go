@@ -0,0 +1,476 @@
+// Package layercache implements a persistent, content-addressed cache for image
+// layer blobs that can be shared across preflight runs and processes.
+//
+// Blobs are stored under the cache directory keyed by their digest, and are
+// only ever made visible by atomically renaming a fully written and verified
+// temporary file into place. Processes using the cache hold a shared lock on
+// the cache directory, and eviction only happens when an exclusive lock can be
+// taken, so blobs are never removed while another process may be reading them.
+package layercache
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// DefaultMaxSize is the size, in bytes, the cache is pruned to when no other
+// size is configured.
+const DefaultMaxSize int64 = 10 << 30 // 10GiB
+
+const (
+	blobsDir = "blobs"
+	tmpDir   = "tmp"
+	lockFile = ".lock"
+)
+
+// ErrCacheInUse is returned when the cache cannot be pruned because another
+// process is using it.
+var ErrCacheInUse = errors.New("layer cache is in use by another process")
+
+var _ cache.Cache = &Cache{}
+
+// Cache is a persistent layer cache rooted at a directory. It implements
+// go-containerregistry's cache.Cache, and is intended to be used with
+// cache.Image.
+type Cache struct {
+	dir     string
+	maxSize int64
+	lock    *os.File
+}
+
+// Option configures a Cache.
+type Option func(*Cache)
+
+// WithMaxSize sets the size, in bytes, the cache is pruned to when it is closed.
+// A value less than or equal to zero disables pruning on close.
+func WithMaxSize(bytes int64) Option {
+	return func(c *Cache) {
+		c.maxSize = bytes
+	}
+}
+
+// Open prepares dir for use as a layer cache, creating it if necessary, and
+// registers the caller as a user of the cache. Callers must call Close when
+// they no longer need any layers read from the cache.
+func Open(dir string, opts ...Option) (*Cache, error) {
+	c := &Cache{
+		dir:     dir,
+		maxSize: DefaultMaxSize,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if err := ensureLayout(dir); err != nil {
+		return nil, err
+	}
+
+	lock, err := openLock(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockShared(lock); err != nil {
+		lock.Close()
+		return nil, fmt.Errorf("could not lock layer cache %s: %w", dir, err)
+	}
+	c.lock = lock
+
+	return c, nil
+}
+
+// Close releases the caller's hold on the cache. If no other process is using
+// the cache, least recently used blobs are evicted until the cache fits within
+// its configured max size.
+func (c *Cache) Close() error {
+	if c.lock == nil {
+		return nil
+	}
+	defer func() {
+		c.lock.Close()
+		c.lock = nil
+	}()
+
+	if err := unlock(c.lock); err != nil {
+		return err
+	}
+	if c.maxSize <= 0 {
+		return nil
+	}
+
+	if _, err := prune(c.dir, c.lock, c.maxSize); err != nil && !errors.Is(err, ErrCacheInUse) {
+		return err
+	}
+
+	return nil
+}
+
+// Put returns a layer that populates the cache as its contents are read.
+func (c *Cache) Put(l v1.Layer) (v1.Layer, error) {
+	digest, err := l.Digest()
+	if err != nil {
+		return nil, err
+	}
+	diffID, err := l.DiffID()
+	if err != nil {
+		return nil, err
+	}
+	return &cachingLayer{
+		Layer:  l,
+		cache:  c,
+		digest: digest,
+		diffID: diffID,
+	}, nil
+}
+
+// Get returns the cached blob for h, or cache.ErrNotFound. Reading a blob
+// marks it as recently used.
+func (c *Cache) Get(h v1.Hash) (v1.Layer, error) {
+	path := c.blobPath(h)
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, cache.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	// Failing to update the access time only affects eviction order.
+	_ = os.Chtimes(path, now, now)
+
+	return &blob{path: path, hash: h, size: info.Size()}, nil
+}
+
+// Delete removes the blob for h from the cache.
+func (c *Cache) Delete(h v1.Hash) error {
+	err := os.Remove(c.blobPath(h))
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache.ErrNotFound
+	}
+	return err
+}
+
+func (c *Cache) blobPath(h v1.Hash) string {
+	return filepath.Join(c.dir, blobsDir, h.Algorithm, h.Hex)
+}
+
+// create returns a writer that stores a blob for h. The blob only becomes
+// visible in the cache once the writer is committed.
+func (c *Cache) create(h v1.Hash) (*blobWriter, error) {
+	f, err := os.CreateTemp(filepath.Join(c.dir, tmpDir), h.Hex+"-*")
+	if err != nil {
+		return nil, err
+	}
+	w := &blobWriter{f: f, hash: h, dst: c.blobPath(h)}
+	if h.Algorithm == "sha256" {
+		w.hasher = sha256.New()
+	}
+	return w, nil
+}
+
+// Stats describes the contents of a layer cache.
+type Stats struct {
+	// Entries is the number of blobs in the cache.
+	Entries int
+	// Size is the total size of all blobs, in bytes.
+	Size int64
+	// Oldest is the last time the least recently used blob was accessed.
+	Oldest time.Time
+	// Newest is the last time the most recently used blob was accessed.
+	Newest time.Time
+}
+
+// ReadStats returns statistics about the cache in dir.
+func ReadStats(dir string) (Stats, error) {
+	entries, err := listBlobs(dir)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	stats := Stats{Entries: len(entries)}
+	for i, e := range entries {
+		stats.Size += e.size
+		if i == 0 {
+			stats.Oldest = e.modTime
+		}
+		stats.Newest = e.modTime
+	}
+	return stats, nil
+}
+
+// PruneResult describes the blobs removed by Prune.
+type PruneResult struct {
+	// Removed is the number of blobs that were evicted.
+	Removed int
+	// Reclaimed is the number of bytes freed.
+	Reclaimed int64
+}
+
+// Prune evicts least recently used blobs from the cache in dir until it is no
+// larger than maxSize bytes. A maxSize of zero empties the cache. ErrCacheInUse
+// is returned if another process is using the cache.
+func Prune(dir string, maxSize int64) (PruneResult, error) {
+	if err := ensureLayout(dir); err != nil {
+		return PruneResult{}, err
+	}
+
+	lock, err := openLock(dir)
+	if err != nil {
+		return PruneResult{}, err
+	}
+	defer lock.Close()
+
+	return prune(dir, lock, maxSize)
+}
+
+// prune takes an exclusive lock on lock without blocking, and evicts blobs
+// until the cache fits in maxSize. Temporary files are removed as well, since
+// no other process can be writing to the cache while the lock is held.
+func prune(dir string, lock *os.File, maxSize int64) (PruneResult, error) {
+	ok, err := tryLockExclusive(lock)
+	if err != nil {
+		return PruneResult{}, err
+	}
+	if !ok {
+		return PruneResult{}, ErrCacheInUse
+	}
+	defer func() { _ = unlock(lock) }()
+
+	if err := removeTemporaryFiles(dir); err != nil {
+		return PruneResult{}, err
+	}
+
+	entries, err := listBlobs(dir)
+	if err != nil {
+		return PruneResult{}, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+
+	var result PruneResult
+	for _, e := range entries {
+		if total <= maxSize {
+			break
+		}
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return result, err
+		}
+		total -= e.size
+		result.Removed++
+		result.Reclaimed += e.size
+	}
+
+	return result, nil
+}
+
+type blobEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// listBlobs returns all blobs in the cache, least recently used first.
+func listBlobs(dir string) ([]blobEntry, error) {
+	var entries []blobEntry
+	err := filepath.WalkDir(filepath.Join(dir, blobsDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		entries = append(entries, blobEntry{path: path, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read layer cache %s: %w", dir, err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+	return entries, nil
+}
+
+func removeTemporaryFiles(dir string) error {
+	tmp := filepath.Join(dir, tmpDir)
+	files, err := os.ReadDir(tmp)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := os.RemoveAll(filepath.Join(tmp, f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureLayout(dir string) error {
+	for _, d := range []string{filepath.Join(dir, blobsDir), filepath.Join(dir, tmpDir)} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return fmt.Errorf("could not create layer cache directory %s: %w", d, err)
+		}
+	}
+	return nil
+}
+
+func openLock(dir string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open layer cache lock: %w", err)
+	}
+	return f, nil
+}
+
+// cachingLayer writes a layer's blobs to the cache as they are read.
+type cachingLayer struct {
+	v1.Layer
+	cache          *Cache
+	digest, diffID v1.Hash
+}
+
+func (l *cachingLayer) Compressed() (io.ReadCloser, error) {
+	return l.tee(l.digest, l.Layer.Compressed)
+}
+
+func (l *cachingLayer) Uncompressed() (io.ReadCloser, error) {
+	return l.tee(l.diffID, l.Layer.Uncompressed)
+}
+
+func (l *cachingLayer) tee(h v1.Hash, open func() (io.ReadCloser, error)) (io.ReadCloser, error) {
+	rc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	w, err := l.cache.create(h)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	return &teeReadCloser{rc: rc, w: w}, nil
+}
+
+// teeReadCloser copies everything read from rc into w. On Close, the rest of
+// rc is read so that the blob is complete, and w is committed to the cache if
+// no errors were encountered.
+type teeReadCloser struct {
+	rc     io.ReadCloser
+	w      *blobWriter
+	failed bool
+}
+
+func (t *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.rc.Read(p)
+	if n > 0 {
+		if _, werr := t.w.Write(p[:n]); werr != nil {
+			t.failed = true
+		}
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		t.failed = true
+	}
+	return n, err
+}
+
+func (t *teeReadCloser) Close() error {
+	if !t.failed {
+		if _, err := io.Copy(t.w, t.rc); err != nil {
+			t.failed = true
+		}
+	}
+	err := t.rc.Close()
+
+	if t.failed {
+		t.w.abort()
+		return err
+	}
+	// A blob that cannot be committed is simply not cached.
+	_ = t.w.commit()
+	return err
+}
+
+// blobWriter writes a blob to a temporary file and verifies its digest before
+// moving it into place.
+type blobWriter struct {
+	f      *os.File
+	hash   v1.Hash
+	hasher hash.Hash
+	dst    string
+}
+
+func (w *blobWriter) Write(p []byte) (int, error) {
+	if w.hasher != nil {
+		w.hasher.Write(p)
+	}
+	return w.f.Write(p)
+}
+
+func (w *blobWriter) abort() {
+	w.f.Close()
+	os.Remove(w.f.Name())
+}
+
+func (w *blobWriter) commit() error {
+	if w.hasher != nil {
+		if got := fmt.Sprintf("%x", w.hasher.Sum(nil)); got != w.hash.Hex {
+			w.abort()
+			return fmt.Errorf("blob digest mismatch: expected %s, got sha256:%s", w.hash, got)
+		}
+	}
+	if err := w.f.Close(); err != nil {
+		os.Remove(w.f.Name())
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(w.dst), 0o755); err != nil {
+		os.Remove(w.f.Name())
+		return err
+	}
+	if err := os.Rename(w.f.Name(), w.dst); err != nil {
+		os.Remove(w.f.Name())
+		return err
+	}
+	return nil
+}
+
+// blob is a layer served from the cache. Blobs are stored by the hash of their
+// contents, so a blob is always served exactly as it was stored. This matches
+// how cache.Image reads from a Cache: lookups by digest are read with
+// Compressed, and lookups by diffID are read with Uncompressed.
+type blob struct {
+	path string
+	hash v1.Hash
+	size int64
+}
+
+func (b *blob) Digest() (v1.Hash, error)             { return b.hash, nil }
+func (b *blob) DiffID() (v1.Hash, error)             { return b.hash, nil }
+func (b *blob) Size() (int64, error)                 { return b.size, nil }
+func (b *blob) MediaType() (types.MediaType, error)  { return types.DockerLayer, nil }
+func (b *blob) Compressed() (io.ReadCloser, error)   { return os.Open(b.path) }
+func (b *blob) Uncompressed() (io.ReadCloser, error) { return os.Open(b.path) }
@@ -0,0 +1,148 @@
+package layercache
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Layer cache", func() {
+	var dir string
+	var c *Cache
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		var err error
+		c, err = Open(dir, WithMaxSize(0))
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(c.Close)
+	})
+
+	Context("When a layer is read through the cache", func() {
+		It("should store the blob and serve it on later lookups", func() {
+			layer, err := random.Layer(1024, "application/vnd.docker.image.rootfs.diff.tar.gzip")
+			Expect(err).ToNot(HaveOccurred())
+			digest, err := layer.Digest()
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = c.Get(digest)
+			Expect(err).To(MatchError(cache.ErrNotFound))
+
+			cached, err := c.Put(layer)
+			Expect(err).ToNot(HaveOccurred())
+			rc, err := cached.Compressed()
+			Expect(err).ToNot(HaveOccurred())
+			want, err := io.ReadAll(rc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rc.Close()).To(Succeed())
+
+			hit, err := c.Get(digest)
+			Expect(err).ToNot(HaveOccurred())
+			rc, err = hit.Compressed()
+			Expect(err).ToNot(HaveOccurred())
+			defer rc.Close()
+			got, err := io.ReadAll(rc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got).To(Equal(want))
+
+			tmp, err := os.ReadDir(filepath.Join(dir, tmpDir))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tmp).To(BeEmpty())
+		})
+
+		It("should complete the blob if the reader is closed early", func() {
+			layer, err := random.Layer(4096, "application/vnd.docker.image.rootfs.diff.tar.gzip")
+			Expect(err).ToNot(HaveOccurred())
+			diffID, err := layer.DiffID()
+			Expect(err).ToNot(HaveOccurred())
+
+			cached, err := c.Put(layer)
+			Expect(err).ToNot(HaveOccurred())
+			rc, err := cached.Uncompressed()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = rc.Read(make([]byte, 10))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rc.Close()).To(Succeed())
+
+			_, err = c.Get(diffID)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should delete a cached blob", func() {
+			layer, err := random.Layer(1024, "application/vnd.docker.image.rootfs.diff.tar.gzip")
+			Expect(err).ToNot(HaveOccurred())
+			digest, err := layer.Digest()
+			Expect(err).ToNot(HaveOccurred())
+			cached, err := c.Put(layer)
+			Expect(err).ToNot(HaveOccurred())
+			rc, err := cached.Compressed()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rc.Close()).To(Succeed())
+
+			Expect(c.Delete(digest)).To(Succeed())
+			Expect(c.Delete(digest)).To(MatchError(cache.ErrNotFound))
+		})
+	})
+
+	Context("When pruning the cache", func() {
+		var oldest, newest string
+
+		BeforeEach(func() {
+			oldest = writeBlob(dir, "aaaa", 100, time.Now().Add(-2*time.Hour))
+			newest = writeBlob(dir, "bbbb", 100, time.Now())
+			Expect(os.WriteFile(filepath.Join(dir, tmpDir, "partial"), []byte("x"), 0o644)).To(Succeed())
+		})
+
+		It("should report in use while another user holds the cache", func() {
+			_, err := Prune(dir, 0)
+			Expect(err).To(MatchError(ErrCacheInUse))
+		})
+
+		It("should evict the least recently used blobs first", func() {
+			Expect(c.Close()).To(Succeed())
+
+			result, err := Prune(dir, 150)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Removed).To(Equal(1))
+			Expect(result.Reclaimed).To(BeNumerically("==", 100))
+			Expect(oldest).ToNot(BeAnExistingFile())
+			Expect(newest).To(BeAnExistingFile())
+			Expect(filepath.Join(dir, tmpDir, "partial")).ToNot(BeAnExistingFile())
+		})
+
+		It("should report statistics", func() {
+			stats, err := ReadStats(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Entries).To(Equal(2))
+			Expect(stats.Size).To(BeNumerically("==", 200))
+			Expect(stats.Oldest.Before(stats.Newest)).To(BeTrue())
+		})
+
+		It("should prune on close when no one else is using the cache", func() {
+			other, err := Open(dir, WithMaxSize(150))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Close()).To(Succeed())
+			Expect(oldest).To(BeAnExistingFile())
+
+			Expect(other.Close()).To(Succeed())
+			Expect(oldest).ToNot(BeAnExistingFile())
+			Expect(newest).To(BeAnExistingFile())
+		})
+	})
+})
+
+// writeBlob places a blob of size bytes directly into the cache in dir, last
+// accessed at atime.
+func writeBlob(dir, hex string, size int, atime time.Time) string {
+	path := filepath.Join(dir, blobsDir, "sha256", hex)
+	Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+	Expect(os.WriteFile(path, make([]byte, size), 0o644)).To(Succeed())
+	Expect(os.Chtimes(path, atime, atime)).To(Succeed())
+	return path
+}
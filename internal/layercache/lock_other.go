@@ -0,0 +1,21 @@
+//go:build !unix
+
+package layercache
+
+import "os"
+
+// File locking is only implemented for unix platforms. Elsewhere, the cache
+// is still safe to read and write concurrently, but pruning does not wait for
+// other processes to stop using it.
+
+func lockShared(*os.File) error {
+	return nil
+}
+
+func tryLockExclusive(*os.File) (bool, error) {
+	return true, nil
+}
+
+func unlock(*os.File) error {
+	return nil
+}
@@ -11,23 +11,30 @@ import (
 	cranev1 "github.com/google/go-containerregistry/pkg/v1"
 )
 
-var _ check.Check = &BasedOnUBICheck{}
+var (
+	_ check.Check             = &BasedOnUBICheck{}
+	_ check.RequirementsCheck = &BasedOnUBICheck{}
+)
 
 // BasedOnUBICheck evaluates if the provided image is based on the Red Hat Universal Base Image.
+// The image's layers are looked up in Pyxis, or in a local catalog of approved base images
+// when running disconnected.
 type BasedOnUBICheck struct {
-	LayerHashCheckEngine layerHashChecker
+	LayerHashCheckEngine LayerHashChecker
 }
 
-type layerHashChecker interface {
+// LayerHashChecker finds the certified images whose uncompressed top layer is one of
+// the given layers, e.g. in Pyxis or in a local base image catalog.
+type LayerHashChecker interface {
 	CertifiedImagesContainingLayers(ctx context.Context, uncompressedLayerHashes []cranev1.Hash) ([]pyxis.CertImage, error)
 }
 
-func NewBasedOnUbiCheck(layerHashChecker layerHashChecker) *BasedOnUBICheck {
+func NewBasedOnUbiCheck(layerHashChecker LayerHashChecker) *BasedOnUBICheck {
 	return &BasedOnUBICheck{LayerHashCheckEngine: layerHashChecker}
 }
 
 func (p *BasedOnUBICheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
-	layerHashes, err := p.getImageLayers(imgRef.ImageInfo)
+	layerHashes, err := p.getImageLayers(imgRef.ImageFacts())
 	if err != nil {
 		return false, fmt.Errorf("could not get image layers: %v", err)
 	}
@@ -36,8 +43,8 @@ func (p *BasedOnUBICheck) Validate(ctx context.Context, imgRef image.ImageRefere
 }
 
 // getImageLayers returns the root filesystem DiffIDs of the image.
-func (p *BasedOnUBICheck) getImageLayers(image cranev1.Image) ([]cranev1.Hash, error) {
-	configFile, err := image.ConfigFile()
+func (p *BasedOnUBICheck) getImageLayers(facts *image.Facts) ([]cranev1.Hash, error) {
+	configFile, err := facts.Config()
 	if err != nil {
 		return nil, err
 	}
@@ -45,12 +52,12 @@ func (p *BasedOnUBICheck) getImageLayers(image cranev1.Image) ([]cranev1.Hash, e
 	return configFile.RootFS.DiffIDs, nil
 }
 
-// certifiedImagesFound checks to make sure images exist in Red Hat Pyxis containing the uncompressed
-// top layer IDs of the image under test.
+// certifiedImagesFound checks to make sure images exist in Red Hat Pyxis, or in the local base
+// image catalog, containing the uncompressed top layer IDs of the image under test.
 func (p *BasedOnUBICheck) certifiedImagesFound(ctx context.Context, layerHashes []cranev1.Hash) (bool, error) {
 	certImages, err := p.LayerHashCheckEngine.CertifiedImagesContainingLayers(ctx, layerHashes)
 	if err != nil {
-		return false, fmt.Errorf("pyxis query for uncompressed top layers ids %+q failed: %w", layerHashes, err)
+		return false, fmt.Errorf("lookup of uncompressed top layers ids %+q failed: %w", layerHashes, err)
 	}
 	if len(certImages) >= 1 {
 		return true, nil
@@ -66,9 +73,20 @@ func (p *BasedOnUBICheck) validate(ctx context.Context, layerHashes []cranev1.Ha
 	if hasUBIHash {
 		return true, nil
 	}
+	check.AddFinding(ctx, check.Finding{
+		Message:     "none of the image's layers were found in a certified Red Hat image",
+		Severity:    check.SeverityError,
+		Remediation: "Build the image from a Red Hat Universal Base Image, e.g. FROM registry.access.redhat.com/ubi9/ubi",
+	})
 	return false, nil
 }
 
+// Requirements implements check.RequirementsCheck. The check only reads the image's
+// layer diff IDs from the config.
+func (p *BasedOnUBICheck) Requirements() check.Requirement {
+	return check.RequiresConfig
+}
+
 func (p *BasedOnUBICheck) Name() string {
 	return "BasedOnUbi"
 }
@@ -0,0 +1,395 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layercache"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+var (
+	_ check.Check             = &hasConsistentArchitecturesCheck{}
+	_ check.RequirementsCheck = &hasConsistentArchitecturesCheck{}
+)
+
+// platformSpecificLabels are expected to differ between the images of a
+// manifest list.
+var platformSpecificLabels = []string{"architecture", "build-date", "com.redhat.build-host"}
+
+// NewHasConsistentArchitecturesCheck returns a check comparing the images of
+// all architectures in a manifest list. The manifest list is read with the
+// same registry options as the image under test. If comparePackages is true,
+// the layers of every architecture are read to compare their installed
+// packages, through the persistent layer cache in layerCacheDir, if set.
+// runArchitectures are the architectures of the manifest list checked in the
+// same run, if any, so the comparison is only made once.
+func NewHasConsistentArchitecturesCheck(dockercfg string, insecure bool, requiredArchitectures, runArchitectures []string, layerCacheDir string, comparePackages bool) *hasConsistentArchitecturesCheck {
+	return &hasConsistentArchitecturesCheck{
+		registry:              manifestListRegistry{dockerConfig: dockercfg, insecure: insecure},
+		requiredArchitectures: requiredArchitectures,
+		runArchitectures:      runArchitectures,
+		layerCacheDir:         layerCacheDir,
+		comparePackages:       comparePackages,
+	}
+}
+
+// hasConsistentArchitecturesCheck compares the images of all architectures in
+// the manifest list the image under test belongs to. Their labels, user,
+// entrypoint and exposed ports must match, and any required architectures must
+// be present. Differences between the installed packages are reported, but do
+// not fail the check, as some packages only exist for some architectures.
+// Packages are not compared in metadata-only runs, as that downloads the
+// layers of every architecture.
+//
+// The comparison covers the whole manifest list, so when several of its
+// architectures are checked in the same run, it is only made when checking
+// the first of them in the manifest list.
+type hasConsistentArchitecturesCheck struct {
+	registry              manifestListRegistry
+	requiredArchitectures []string
+	runArchitectures      []string
+	layerCacheDir         string
+	comparePackages       bool
+}
+
+// manifestListRegistry configures how the manifest list is read from its
+// registry.
+type manifestListRegistry struct {
+	dockerConfig string
+	insecure     bool
+}
+
+var _ option.CraneConfig = manifestListRegistry{}
+
+func (r manifestListRegistry) CraneDockerConfig() string {
+	return r.dockerConfig
+}
+
+// CranePlatform returns no platform, as the images of all platforms in the
+// manifest list are read.
+func (r manifestListRegistry) CranePlatform() string {
+	return ""
+}
+
+func (r manifestListRegistry) CraneInsecure() bool {
+	return r.insecure
+}
+
+// platformSummary holds the attributes of the image for one architecture
+// that are compared between architectures.
+type platformSummary struct {
+	Architecture string
+	Labels       map[string]string
+	User         string
+	Entrypoint   []string
+	ExposedPorts []string
+	// Packages maps the name of each installed package to its version-release.
+	Packages map[string]string
+}
+
+func (p *hasConsistentArchitecturesCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	if imgRef.ManifestListDigest == "" {
+		// The image is the only architecture there is, so any other required
+		// architecture is missing.
+		if len(p.requiredArchitectures) > 0 {
+			configFile, err := imgRef.ImageInfo.ConfigFile()
+			if err != nil {
+				return false, fmt.Errorf("could not get image config: %w", err)
+			}
+			if !p.hasRequiredArchitectures(ctx, []string{configFile.Architecture}) {
+				return false, nil
+			}
+		}
+		return false, check.MissingPrerequisite("the image is not part of a manifest list")
+	}
+	if imgRef.Local {
+		return false, check.MissingPrerequisite("manifest lists can only be compared for images in a registry")
+	}
+
+	platforms, err := p.getPlatformImages(ctx, imgRef)
+	if err != nil {
+		return false, err
+	}
+	if len(platforms) == 0 {
+		return false, fmt.Errorf("manifest list %s contains no images of a supported architecture", imgRef.ManifestListDigest)
+	}
+
+	digest, err := imgRef.ImageInfo.Digest()
+	if err != nil {
+		return false, fmt.Errorf("could not get image digest: %w", err)
+	}
+	if comparer, ok := p.comparingPlatform(platforms); ok && comparer.digest != digest {
+		return false, check.MissingPrerequisite(fmt.Sprintf("the manifest list is compared when checking its %s image", comparer.architecture))
+	}
+
+	var layerCache cache.Cache
+	if p.comparePackages && p.layerCacheDir != "" {
+		// Pruning is left to the engine, which owns the cache's size limit.
+		lc, err := layercache.Open(p.layerCacheDir, layercache.WithMaxSize(0))
+		if err != nil {
+			return false, fmt.Errorf("could not open layer cache: %w", err)
+		}
+		defer lc.Close()
+		layerCache = lc
+	}
+
+	summaries := make([]platformSummary, 0, len(platforms))
+	for _, platform := range platforms {
+		img := platform.image
+		if layerCache != nil {
+			img = cache.Image(img, layerCache)
+		}
+		summary, err := summarizePlatform(ctx, platform.architecture, img, p.comparePackages)
+		if err != nil {
+			return false, fmt.Errorf("could not read %s image: %w", platform.architecture, err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return p.validate(ctx, summaries)
+}
+
+// platformImage is the image of one architecture in a manifest list.
+type platformImage struct {
+	architecture string
+	digest       v1.Hash
+	image        v1.Image
+}
+
+// comparingPlatform returns the first of platforms that is checked in this
+// run, if the run checks any other architecture of the manifest list.
+func (p *hasConsistentArchitecturesCheck) comparingPlatform(platforms []platformImage) (platformImage, bool) {
+	for _, platform := range platforms {
+		if slices.Contains(p.runArchitectures, platform.architecture) {
+			return platform, true
+		}
+	}
+	return platformImage{}, false
+}
+
+// getPlatformImages returns the images of the supported architectures in the
+// manifest list of imgRef, in the order they are listed.
+func (p *hasConsistentArchitecturesCheck) getPlatformImages(ctx context.Context, imgRef image.ImageReference) ([]platformImage, error) {
+	options := crane.GetOptions(option.GenerateCraneOptions(ctx, p.registry)...)
+	ref, err := name.ParseReference(fmt.Sprintf("%s/%s@%s", imgRef.ImageRegistry, imgRef.ImageRepository, imgRef.ManifestListDigest), options.Name...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest list reference: %w", err)
+	}
+
+	idx, err := remote.Index(ref, options.Remote...)
+	if err != nil {
+		return nil, fmt.Errorf("could not get manifest list %s: %w", ref, err)
+	}
+
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest list %s: %w", ref, err)
+	}
+
+	var platforms []platformImage
+	for _, desc := range manifest.Manifests {
+		if desc.Platform == nil || !slices.Contains(image.SupportedArchitectures(), desc.Platform.Architecture) {
+			continue
+		}
+		img, err := idx.Image(desc.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("could not get %s image: %w", desc.Platform.Architecture, err)
+		}
+		platforms = append(platforms, platformImage{architecture: desc.Platform.Architecture, digest: desc.Digest, image: img})
+	}
+	return platforms, nil
+}
+
+// summarizePlatform reads the attributes of img that are compared between
+// architectures. If withPackages is true, the packages are read from the
+// topmost layer containing an rpm database, if any.
+func summarizePlatform(ctx context.Context, architecture string, img v1.Image, withPackages bool) (platformSummary, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		return platformSummary{}, err
+	}
+
+	summary := platformSummary{
+		Architecture: architecture,
+		Labels:       configFile.Config.Labels,
+		User:         configFile.Config.User,
+		Entrypoint:   configFile.Config.Entrypoint,
+		Packages:     map[string]string{},
+	}
+	for port := range configFile.Config.ExposedPorts {
+		summary.ExposedPorts = append(summary.ExposedPorts, port)
+	}
+	sort.Strings(summary.ExposedPorts)
+	if !withPackages {
+		return summary, nil
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return platformSummary{}, err
+	}
+	for i := len(layers) - 1; i >= 0; i-- {
+		found, pkgList := findRPMDB(ctx, layers[i])
+		if !found {
+			continue
+		}
+		for _, pkg := range pkgList {
+			summary.Packages[pkg.Name] = fmt.Sprintf("%s-%s", pkg.Version, pkg.Release)
+		}
+		break
+	}
+	logger.V(log.DBG).Info("read image of manifest list", "architecture", architecture, "packages", len(summary.Packages))
+
+	return summary, nil
+}
+
+func (p *hasConsistentArchitecturesCheck) validate(ctx context.Context, summaries []platformSummary) (bool, error) {
+	passed := true
+	divergence := func(message string) {
+		passed = false
+		check.AddFinding(ctx, check.Finding{
+			Message:     message,
+			Severity:    check.SeverityError,
+			Remediation: "Build the images of all architectures from the same sources and configuration",
+		})
+	}
+
+	architectures := make([]string, 0, len(summaries))
+	for _, s := range summaries {
+		architectures = append(architectures, s.Architecture)
+	}
+	if !p.hasRequiredArchitectures(ctx, architectures) {
+		passed = false
+	}
+
+	labelNames := map[string]bool{}
+	for _, s := range summaries {
+		for name := range s.Labels {
+			if !slices.Contains(platformSpecificLabels, name) {
+				labelNames[name] = true
+			}
+		}
+	}
+	for _, name := range sortedKeys(labelNames) {
+		if values, differ := compareAcross(summaries, func(s platformSummary) string { return s.Labels[name] }); differ {
+			divergence(fmt.Sprintf("label %s differs between architectures: %s", name, values))
+		}
+	}
+
+	if values, differ := compareAcross(summaries, func(s platformSummary) string { return s.User }); differ {
+		divergence(fmt.Sprintf("user differs between architectures: %s", values))
+	}
+	if values, differ := compareAcross(summaries, func(s platformSummary) string { return strings.Join(s.Entrypoint, " ") }); differ {
+		divergence(fmt.Sprintf("entrypoint differs between architectures: %s", values))
+	}
+	if values, differ := compareAcross(summaries, func(s platformSummary) string { return strings.Join(s.ExposedPorts, ",") }); differ {
+		divergence(fmt.Sprintf("exposed ports differ between architectures: %s", values))
+	}
+
+	// Some packages are only built for some architectures, so differences in
+	// the installed packages are reported without failing the check.
+	packageNames := map[string]bool{}
+	for _, s := range summaries {
+		for name := range s.Packages {
+			packageNames[name] = true
+		}
+	}
+	for _, name := range sortedKeys(packageNames) {
+		if values, differ := compareAcross(summaries, func(s platformSummary) string { return s.Packages[name] }); differ {
+			check.AddFinding(ctx, check.Finding{
+				Message:  fmt.Sprintf("package %s differs between architectures: %s", name, values),
+				Severity: check.SeverityWarning,
+				Package:  name,
+			})
+		}
+	}
+
+	return passed, nil
+}
+
+// hasRequiredArchitectures reports each of the required architectures that is
+// not one of architectures, and returns false if any is missing.
+func (p *hasConsistentArchitecturesCheck) hasRequiredArchitectures(ctx context.Context, architectures []string) bool {
+	found := true
+	for _, required := range p.requiredArchitectures {
+		if !slices.Contains(architectures, required) {
+			found = false
+			check.AddFinding(ctx, check.Finding{
+				Message:     fmt.Sprintf("the image of required architecture %s is missing", required),
+				Severity:    check.SeverityError,
+				Remediation: fmt.Sprintf("Build and publish an image for %s with the other architectures", required),
+			})
+		}
+	}
+	return found
+}
+
+// compareAcross returns true if value differs between the summaries, along
+// with a description of the value for each architecture.
+func compareAcross(summaries []platformSummary, value func(platformSummary) string) (string, bool) {
+	differ := false
+	described := make([]string, 0, len(summaries))
+	for _, s := range summaries {
+		v := value(s)
+		if v != value(summaries[0]) {
+			differ = true
+		}
+		if v == "" {
+			v = "<none>"
+		}
+		described = append(described, fmt.Sprintf("%s=%s", s.Architecture, v))
+	}
+	return strings.Join(described, ", "), differ
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Requirements implements check.RequirementsCheck. The check reads the
+// images of the other architectures from the registry itself, and only
+// downloads their layers if it compares packages.
+func (p *hasConsistentArchitecturesCheck) Requirements() check.Requirement {
+	return check.RequiresManifest
+}
+
+func (p *hasConsistentArchitecturesCheck) Name() string {
+	return "HasConsistentArchitectures"
+}
+
+func (p *hasConsistentArchitecturesCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the images of all architectures in the manifest list have the same labels, user, entrypoint and exposed ports.",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *hasConsistentArchitecturesCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasConsistentArchitectures encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Build the images of all architectures in the manifest list from the same sources and configuration, and publish an image for every required architecture.",
+	}
+}
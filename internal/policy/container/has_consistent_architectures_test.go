@@ -0,0 +1,243 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("HasConsistentArchitectures", func() {
+	var (
+		host    string
+		indexes map[string]v1.ImageIndex
+
+		mu             sync.Mutex
+		blobsRequested []string
+	)
+
+	// layersRequested returns how many layers of the images in repo were
+	// downloaded from the registry.
+	layersRequested := func(repo string) int {
+		manifest, err := indexes[repo].IndexManifest()
+		Expect(err).ToNot(HaveOccurred())
+		var layers []string
+		for _, desc := range manifest.Manifests {
+			img, err := indexes[repo].Image(desc.Digest)
+			Expect(err).ToNot(HaveOccurred())
+			imgLayers, err := img.Layers()
+			Expect(err).ToNot(HaveOccurred())
+			for _, layer := range imgLayers {
+				digest, err := layer.Digest()
+				Expect(err).ToNot(HaveOccurred())
+				layers = append(layers, digest.String())
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		count := 0
+		for _, blob := range blobsRequested {
+			if slices.Contains(layers, blob) {
+				count++
+			}
+		}
+		return count
+	}
+
+	platformImage := func(arch string, config v1.Config) mutate.IndexAddendum {
+		img, err := random.Image(512, 1)
+		Expect(err).ToNot(HaveOccurred())
+		img, err = mutate.Config(img, config)
+		Expect(err).ToNot(HaveOccurred())
+		return mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: arch}}}
+	}
+
+	pushIndex := func(repo string, addenda ...mutate.IndexAddendum) {
+		idx := mutate.AppendManifests(empty.Index, addenda...)
+		ref, err := name.ParseReference(fmt.Sprintf("%s/%s", host, repo))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.WriteIndex(ref, idx)).To(Succeed())
+		indexes[repo] = idx
+	}
+
+	// imageRefFor returns the reference to the image of the architecture at
+	// position i of the index in repo.
+	imageRefFor := func(repo string, i int) image.ImageReference {
+		idx := indexes[repo]
+		digest, err := idx.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		manifest, err := idx.IndexManifest()
+		Expect(err).ToNot(HaveOccurred())
+		img, err := idx.Image(manifest.Manifests[i].Digest)
+		Expect(err).ToNot(HaveOccurred())
+		return image.ImageReference{
+			ImageRegistry:      host,
+			ImageRepository:    repo,
+			ManifestListDigest: digest.String(),
+			ImageInfo:          img,
+		}
+	}
+
+	BeforeEach(func() {
+		registryLogger := log.New(io.Discard, "", log.Ldate)
+		blobsRequested = nil
+		reg := registry.New(registry.Logger(registryLogger))
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, blob, ok := strings.Cut(r.URL.Path, "/blobs/"); ok && r.Method == http.MethodGet {
+				mu.Lock()
+				blobsRequested = append(blobsRequested, blob)
+				mu.Unlock()
+			}
+			reg.ServeHTTP(w, r)
+		}))
+		DeferCleanup(s.Close)
+		u, err := url.Parse(s.URL)
+		Expect(err).ToNot(HaveOccurred())
+		host = u.Host
+		indexes = map[string]v1.ImageIndex{}
+
+		config := v1.Config{User: "1001", Labels: map[string]string{"version": "1.0", "architecture": "x86_64"}, Entrypoint: []string{"/app"}}
+		s390xConfig := v1.Config{User: "1001", Labels: map[string]string{"version": "1.0", "architecture": "s390x"}, Entrypoint: []string{"/app"}}
+		pushIndex("test/consistent", platformImage("amd64", config), platformImage("s390x", s390xConfig))
+
+		divergentConfig := v1.Config{User: "root", Labels: map[string]string{"version": "1.1"}, Entrypoint: []string{"/app"}}
+		pushIndex("test/divergent", platformImage("amd64", config), platformImage("s390x", divergentConfig))
+	})
+
+	It("should pass if the images of all architectures match", func() {
+		findings := &check.Findings{}
+		ctx := check.ContextWithFindings(context.TODO(), findings)
+		ok, err := NewHasConsistentArchitecturesCheck("", false, []string{"amd64", "s390x"}, nil, "", true).Validate(ctx, imageRefFor("test/consistent", 0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(findings.List()).To(BeEmpty())
+	})
+
+	It("should fail and report the labels and user that differ", func() {
+		findings := &check.Findings{}
+		ctx := check.ContextWithFindings(context.TODO(), findings)
+		ok, err := NewHasConsistentArchitecturesCheck("", false, nil, nil, "", true).Validate(ctx, imageRefFor("test/divergent", 0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(findings.List()).To(HaveLen(2))
+		Expect(findings.List()[0].Message).To(Equal("label version differs between architectures: amd64=1.0, s390x=1.1"))
+		Expect(findings.List()[1].Message).To(Equal("user differs between architectures: amd64=1001, s390x=root"))
+	})
+
+	It("should fail if a required architecture is missing", func() {
+		findings := &check.Findings{}
+		ctx := check.ContextWithFindings(context.TODO(), findings)
+		ok, err := NewHasConsistentArchitecturesCheck("", false, []string{"ppc64le"}, nil, "", true).Validate(ctx, imageRefFor("test/consistent", 0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(findings.List()[0].Message).To(ContainSubstring("ppc64le"))
+	})
+
+	It("should only compare the manifest list when checking the first architecture of the run", func() {
+		_, err := NewHasConsistentArchitecturesCheck("", false, nil, []string{"amd64", "s390x"}, "", true).Validate(context.TODO(), imageRefFor("test/divergent", 1))
+		reason, ok := check.IsMissingPrerequisite(err)
+		Expect(ok).To(BeTrue())
+		Expect(reason).To(ContainSubstring("amd64"))
+	})
+
+	It("should compare the manifest list if its first architecture is not part of the run", func() {
+		ok, err := NewHasConsistentArchitecturesCheck("", false, nil, []string{"s390x"}, "", true).Validate(context.TODO(), imageRefFor("test/divergent", 1))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		ok, err = NewHasConsistentArchitecturesCheck("", false, nil, nil, "", true).Validate(context.TODO(), imageRefFor("test/divergent", 1))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should not apply to images outside of a manifest list", func() {
+		_, err := NewHasConsistentArchitecturesCheck("", false, nil, nil, "", true).Validate(context.TODO(), image.ImageReference{ImageRegistry: host, ImageRepository: "test/consistent"})
+		_, ok := check.IsMissingPrerequisite(err)
+		Expect(ok).To(BeTrue())
+	})
+
+	Context("When the image is not part of a manifest list and architectures are required", func() {
+		var imgRef image.ImageReference
+
+		BeforeEach(func() {
+			img, err := random.Image(512, 1)
+			Expect(err).ToNot(HaveOccurred())
+			img, err = mutate.ConfigFile(img, &v1.ConfigFile{Architecture: "amd64", OS: "linux"})
+			Expect(err).ToNot(HaveOccurred())
+			imgRef = image.ImageReference{ImageRegistry: host, ImageRepository: "test/single", ImageInfo: img}
+		})
+
+		It("should fail if another architecture is required", func() {
+			findings := &check.Findings{}
+			ctx := check.ContextWithFindings(context.TODO(), findings)
+			ok, err := NewHasConsistentArchitecturesCheck("", false, []string{"amd64", "s390x"}, nil, "", true).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(HaveLen(1))
+			Expect(findings.List()[0].Message).To(ContainSubstring("s390x"))
+		})
+
+		It("should not apply if only its architecture is required", func() {
+			_, err := NewHasConsistentArchitecturesCheck("", false, []string{"amd64"}, nil, "", true).Validate(context.TODO(), imgRef)
+			_, ok := check.IsMissingPrerequisite(err)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	It("should not download any layers if packages are not compared", func() {
+		ok, err := NewHasConsistentArchitecturesCheck("", false, nil, nil, "", false).Validate(context.TODO(), imageRefFor("test/consistent", 0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(layersRequested("test/consistent")).To(BeZero())
+	})
+
+	It("should read the layers of every architecture through the layer cache", func() {
+		cacheDir := GinkgoT().TempDir()
+		chk := NewHasConsistentArchitecturesCheck("", false, nil, nil, cacheDir, true)
+		_, err := chk.Validate(context.TODO(), imageRefFor("test/consistent", 0))
+		Expect(err).ToNot(HaveOccurred())
+		downloaded := layersRequested("test/consistent")
+		Expect(downloaded).To(Equal(2))
+
+		_, err = chk.Validate(context.TODO(), imageRefFor("test/consistent", 0))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(layersRequested("test/consistent")).To(Equal(downloaded))
+	})
+
+	It("should report package differences without failing", func() {
+		findings := &check.Findings{}
+		ctx := check.ContextWithFindings(context.TODO(), findings)
+		ok, err := NewHasConsistentArchitecturesCheck("", false, nil, nil, "", true).validate(ctx, []platformSummary{
+			{Architecture: "amd64", Packages: map[string]string{"bash": "5.1.8-6.el9", "grub2-pc": "2.06-70.el9"}},
+			{Architecture: "s390x", Packages: map[string]string{"bash": "5.1.8-6.el9", "s390utils-base": "2.29.0-3.el9"}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(findings.List()).To(HaveLen(2))
+		Expect(findings.List()[0]).To(Equal(check.Finding{
+			Message:  "package grub2-pc differs between architectures: amd64=2.06-70.el9, s390x=<none>",
+			Severity: check.SeverityWarning,
+			Package:  "grub2-pc",
+		}))
+	})
+})
@@ -0,0 +1,435 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"debug/elf"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+
+	"github.com/go-logr/logr"
+)
+
+var (
+	_ check.Check             = &hasHardenedBinariesCheck{}
+	_ check.RequirementsCheck = &hasHardenedBinariesCheck{}
+)
+
+// Hardening properties of ELF binaries that can be required.
+const (
+	HardeningPIE            = "pie"
+	HardeningRELRO          = "relro"
+	HardeningNX             = "nx"
+	HardeningStackProtector = "stack-protector"
+	HardeningFortifySource  = "fortify"
+)
+
+// hardeningProperties are the properties that can be required, in the order
+// they are reported.
+var hardeningProperties = []string{HardeningPIE, HardeningRELRO, HardeningNX, HardeningStackProtector, HardeningFortifySource}
+
+// DefaultHardeningMinimum are the hardening properties required when no
+// minimum is configured. FORTIFY_SOURCE is not required by default, as it
+// can only be detected in binaries calling a fortified function.
+var DefaultHardeningMinimum = []string{HardeningPIE, HardeningRELRO, HardeningNX, HardeningStackProtector}
+
+// hardeningExcludedPaths are not searched for binaries.
+var hardeningExcludedPaths = []string{"/proc", "/sys", "/dev"}
+
+// hardeningRemediations suggest how to build a C or C++ binary with each
+// property.
+var hardeningRemediations = map[string]string{
+	HardeningPIE:            "-fPIE -pie",
+	HardeningRELRO:          "-Wl,-z,relro,-z,now",
+	HardeningNX:             "-Wl,-z,noexecstack",
+	HardeningStackProtector: "-fstack-protector-strong",
+	HardeningFortifySource:  "-O2 -D_FORTIFY_SOURCE=2",
+}
+
+// NewHasHardenedBinariesCheck returns a check grading the hardening of the
+// ELF binaries added to the image outside of rpms. Binaries lacking any of
+// the properties in minimum fail the check. If minimum is empty,
+// DefaultHardeningMinimum is required, and if it is none, binaries are only
+// reported. An error is returned if minimum names an unknown property.
+func NewHasHardenedBinariesCheck(minimum []string) (*hasHardenedBinariesCheck, error) {
+	if len(minimum) == 0 {
+		minimum = DefaultHardeningMinimum
+	}
+	required := []string{}
+	for _, m := range minimum {
+		m = strings.ToLower(strings.TrimSpace(m))
+		if m == "none" || slices.Contains(required, m) {
+			continue
+		}
+		if !slices.Contains(hardeningProperties, m) {
+			return nil, fmt.Errorf("unknown hardening property %q: must be one of %s or none", m, strings.Join(hardeningProperties, ", "))
+		}
+		required = append(required, m)
+	}
+	return &hasHardenedBinariesCheck{minimum: required}, nil
+}
+
+// hasHardenedBinariesCheck evaluates whether the executables and shared
+// objects added to the image by its vendor are built with PIE, full RELRO,
+// a non-executable stack, the stack protector and FORTIFY_SOURCE. Files
+// installed by rpms are not graded, as they are hardened by their
+// distribution. Every binary graded is written to the binary-hardening.json
+// artifact.
+type hasHardenedBinariesCheck struct {
+	minimum []string
+}
+
+// binaryHardening is the hardening of a binary. Properties that do not apply
+// to the binary are nil, e.g. PIE for a shared object or the stack protector
+// for a Go binary.
+type binaryHardening struct {
+	Path  string `json:"path"`
+	Layer string `json:"layer,omitempty"`
+	// Type is executable or shared_object.
+	Type string `json:"type"`
+	// Language is go for binaries built with Go, which is memory safe.
+	Language string `json:"language,omitempty"`
+	Static   bool   `json:"static"`
+	PIE      *bool  `json:"pie"`
+	// RELRO is full, partial or none.
+	RELRO          string `json:"relro"`
+	NX             bool   `json:"nx"`
+	StackProtector *bool  `json:"stack_protector"`
+	FortifySource  *bool  `json:"fortify_source"`
+	// Missing are the required properties the binary lacks.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// hardeningReport is written to the binary-hardening.json artifact.
+type hardeningReport struct {
+	Minimum  []string          `json:"minimum"`
+	Binaries []binaryHardening `json:"binaries"`
+}
+
+func (p *hasHardenedBinariesCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	binaries, err := p.getDataToValidate(ctx, imgRef)
+	if err != nil {
+		return false, fmt.Errorf("could not grade binaries: %v", err)
+	}
+
+	return p.validate(ctx, binaries)
+}
+
+func (p *hasHardenedBinariesCheck) getDataToValidate(ctx context.Context, imgRef image.ImageReference) ([]binaryHardening, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	if imgRef.Files == nil {
+		return nil, errors.New("the image's file metadata is not available")
+	}
+
+	pkgList, err := imgRef.ImageFacts().Packages(ctx)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("could not get rpm list: %w", err)
+	}
+	packageFiles, err := installedFileMapWithExclusions(ctx, pkgList)
+	if err != nil {
+		return nil, fmt.Errorf("could not get rpm file list: %w", err)
+	}
+
+	layerDigests, err := getLayerDigests(imgRef.ImageInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	var binaries []binaryHardening
+	owned := func(info image.FileInfo) bool {
+		_, owned := packageFiles[normalize(info.Path)]
+		return owned
+	}
+	err = walkELFFiles(ctx, imgRef, owned, func(info image.FileInfo, _ *os.File, ef *elf.File) error {
+		b, ok := gradeBinary(ef)
+		if !ok {
+			return nil
+		}
+		b.Path = info.Path
+		if info.Layer < len(layerDigests) {
+			b.Layer = layerDigests[info.Layer]
+		}
+		binaries = append(binaries, b)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.V(log.DBG).Info("graded binaries not installed by rpms", "count", len(binaries))
+
+	return binaries, nil
+}
+
+// excludedFromHardening returns true if the file at p is not graded.
+func excludedFromHardening(p string) bool {
+	for _, excluded := range hardeningExcludedPaths {
+		if strings.HasPrefix(p, excluded+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// walkELFFiles calls inspect with each ELF file in imgRef's filesystem that may
+// be an executable or a shared object, is not excluded from hardening, and is
+// not skipped. Files that cannot be read or inspected are logged and skipped,
+// so that one unreadable file does not fail the check.
+func walkELFFiles(ctx context.Context, imgRef image.ImageReference, skip func(image.FileInfo) bool, inspect func(info image.FileInfo, f *os.File, ef *elf.File) error) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	return imgRef.Files.Walk(func(info image.FileInfo) error {
+		if !info.Mode.IsRegular() || info.HardLink || excludedFromHardening(info.Path) {
+			return nil
+		}
+		if info.Mode&0o111 == 0 && !strings.Contains(path.Base(info.Path), ".so") {
+			return nil
+		}
+		if skip(info) {
+			return nil
+		}
+
+		if err := openELFFile(filepath.Join(imgRef.ImageFSPath, filepath.FromSlash(info.Path)), func(f *os.File, ef *elf.File) error {
+			return inspect(info, f, ef)
+		}); err != nil {
+			logger.V(log.DBG).Info("could not inspect ELF file", "path", info.Path, "reason", err)
+		}
+		return nil
+	})
+}
+
+// openELFFile calls inspect with the file at p, if it is an ELF file.
+func openELFFile(p string, inspect func(f *os.File, ef *elf.File) error) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(elf.ELFMAG))
+	if _, err := io.ReadFull(f, magic); err != nil || !bytes.Equal(magic, []byte(elf.ELFMAG)) {
+		return nil
+	}
+
+	ef, err := elf.NewFile(f)
+	if err != nil {
+		return err
+	}
+	defer ef.Close()
+
+	return inspect(f, ef)
+}
+
+// gradeBinary reads the hardening of the ELF file ef. It returns false if ef
+// is not an executable or shared object.
+func gradeBinary(ef *elf.File) (binaryHardening, bool) {
+	if ef.Type != elf.ET_EXEC && ef.Type != elf.ET_DYN {
+		return binaryHardening{}, false
+	}
+
+	var hasInterp, hasDynamic, hasRELRO, hasStack, execStack bool
+	for _, prog := range ef.Progs {
+		switch prog.Type {
+		case elf.PT_INTERP:
+			hasInterp = true
+		case elf.PT_DYNAMIC:
+			hasDynamic = true
+		case elf.PT_GNU_RELRO:
+			hasRELRO = true
+		case elf.PT_GNU_STACK:
+			hasStack = true
+			execStack = prog.Flags&elf.PF_X != 0
+		}
+	}
+
+	var flags, flags1 uint64
+	bindNow := false
+	if hasDynamic {
+		if values, err := ef.DynValue(elf.DT_FLAGS); err == nil && len(values) > 0 {
+			flags = values[0]
+		}
+		if values, err := ef.DynValue(elf.DT_FLAGS_1); err == nil && len(values) > 0 {
+			flags1 = values[0]
+		}
+		if values, err := ef.DynValue(elf.DT_BIND_NOW); err == nil && len(values) > 0 {
+			bindNow = true
+		}
+	}
+	bindNow = bindNow || flags&uint64(elf.DF_BIND_NOW) != 0 || flags1&uint64(elf.DF_1_NOW) != 0
+
+	b := binaryHardening{
+		Type:   "executable",
+		Static: !hasDynamic,
+		RELRO:  "none",
+		// Without a PT_GNU_STACK header, the stack is executable.
+		NX: hasStack && !execStack,
+	}
+	if hasRELRO {
+		b.RELRO = "partial"
+		if bindNow {
+			b.RELRO = "full"
+		}
+	}
+
+	isPIE := ef.Type == elf.ET_DYN && (hasInterp || flags1&uint64(elf.DF_1_PIE) != 0)
+	if ef.Type == elf.ET_DYN && !isPIE {
+		// Shared objects are always position independent.
+		b.Type = "shared_object"
+	} else {
+		b.PIE = &isPIE
+	}
+
+	if ef.Section(".go.buildinfo") != nil || ef.Section(".gopclntab") != nil {
+		// Go does not use the C stack protector or fortified functions.
+		b.Language = "go"
+		return b, true
+	}
+
+	var stackProtector, fortify bool
+	for _, name := range elfSymbolNames(ef) {
+		switch {
+		case name == "__stack_chk_fail" || name == "__stack_chk_guard":
+			stackProtector = true
+		case strings.HasPrefix(name, "__") && strings.HasSuffix(name, "_chk"):
+			fortify = true
+		}
+	}
+	b.StackProtector, b.FortifySource = &stackProtector, &fortify
+
+	return b, true
+}
+
+// elfSymbolNames returns the names of the dynamic symbols of f, along with
+// its static symbols if it was not stripped.
+func elfSymbolNames(f *elf.File) []string {
+	var names []string
+	if symbols, err := f.DynamicSymbols(); err == nil {
+		for _, s := range symbols {
+			names = append(names, s.Name)
+		}
+	}
+	if symbols, err := f.Symbols(); err == nil {
+		for _, s := range symbols {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+// missing returns the properties in minimum that b lacks.
+func (b binaryHardening) missing(minimum []string) []string {
+	var missing []string
+	for _, property := range hardeningProperties {
+		if !slices.Contains(minimum, property) {
+			continue
+		}
+		var ok bool
+		switch property {
+		case HardeningPIE:
+			ok = b.PIE == nil || *b.PIE
+		case HardeningRELRO:
+			ok = b.RELRO == "full"
+		case HardeningNX:
+			ok = b.NX
+		case HardeningStackProtector:
+			ok = b.StackProtector == nil || *b.StackProtector
+		case HardeningFortifySource:
+			ok = b.FortifySource == nil || *b.FortifySource
+		}
+		if !ok {
+			missing = append(missing, property)
+		}
+	}
+	return missing
+}
+
+func (p *hasHardenedBinariesCheck) validate(ctx context.Context, binaries []binaryHardening) (bool, error) {
+	passed := true
+	for i := range binaries {
+		b := &binaries[i]
+		b.Missing = b.missing(p.minimum)
+		if len(b.Missing) == 0 {
+			continue
+		}
+		passed = false
+
+		remediation := "Rebuild the binary with go build -buildmode=pie"
+		if b.Language != "go" {
+			var flags []string
+			for _, m := range b.Missing {
+				flags = append(flags, hardeningRemediations[m])
+			}
+			remediation = fmt.Sprintf("Rebuild the binary with %s", strings.Join(flags, " "))
+		}
+		check.AddFinding(ctx, check.Finding{
+			Message:     fmt.Sprintf("%s %s is not built with %s", strings.ReplaceAll(b.Type, "_", " "), b.Path, strings.Join(b.Missing, ", ")),
+			Severity:    check.SeverityWarning,
+			Path:        b.Path,
+			Layer:       b.Layer,
+			Remediation: remediation,
+		})
+	}
+
+	if err := writeHardeningReport(ctx, hardeningReport{Minimum: p.minimum, Binaries: binaries}); err != nil {
+		return false, err
+	}
+
+	return passed, nil
+}
+
+// writeHardeningReport writes report to the binary-hardening.json artifact.
+func writeHardeningReport(ctx context.Context, report hardeningReport) error {
+	artifactWriter := artifacts.WriterFromContext(ctx)
+	if artifactWriter == nil {
+		return nil
+	}
+	if report.Binaries == nil {
+		report.Binaries = []binaryHardening{}
+	}
+
+	contents, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return fmt.Errorf("could not marshal binary hardening report: %w", err)
+	}
+	if _, err := artifactWriter.WriteFile(check.DefaultBinaryHardeningFilename, bytes.NewReader(contents)); err != nil {
+		return fmt.Errorf("failed to save file to artifacts directory: %w", err)
+	}
+	return nil
+}
+
+// Requirements implements check.RequirementsCheck.
+func (p *hasHardenedBinariesCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
+func (p *hasHardenedBinariesCheck) Name() string {
+	return "HasHardenedBinaries"
+}
+
+func (p *hasHardenedBinariesCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that executables and shared objects not installed by rpms are built with PIE, full RELRO, a non-executable stack, the stack protector and FORTIFY_SOURCE.",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *hasHardenedBinariesCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasHardenedBinaries encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Build C and C++ binaries with -fPIE -pie -Wl,-z,relro,-z,now -Wl,-z,noexecstack -fstack-protector-strong -O2 -D_FORTIFY_SOURCE=2, and Go binaries with -buildmode=pie. The hardening of each binary is listed in the binary-hardening.json artifact.",
+	}
+}
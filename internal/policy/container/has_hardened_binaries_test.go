@@ -0,0 +1,305 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"debug/elf"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+// elfSpec describes an ELF64 binary built by elfBinary.
+type elfSpec struct {
+	Type elf.Type
+	// Progs are the types of the program headers, with PT_GNU_STACK
+	// executable if ExecStack is set.
+	Progs     []elf.ProgType
+	ExecStack bool
+	// Dynamic entries are written to a .dynamic section.
+	Dynamic []elf.Dyn64
+	// Symbols are written to a .symtab section.
+	Symbols []string
+	// Sections are empty sections added by name, e.g. .go.buildinfo.
+	Sections []string
+}
+
+// elfBinary returns a minimal little-endian x86-64 ELF file for spec. It has
+// no code, but enough headers for its hardening to be read.
+func elfBinary(spec elfSpec) []byte {
+	const (
+		headerSize  = 64
+		progSize    = 56
+		sectionSize = 64
+	)
+	var data bytes.Buffer
+	var sections []elf.Section64
+	shstrtab := []byte{0}
+	addName := func(name string) uint32 {
+		offset := len(shstrtab)
+		shstrtab = append(append(shstrtab, name...), 0)
+		return uint32(offset)
+	}
+	dataOffset := uint64(headerSize + progSize*len(spec.Progs))
+
+	sections = append(sections, elf.Section64{})
+	for _, name := range spec.Sections {
+		sections = append(sections, elf.Section64{Name: addName(name), Type: uint32(elf.SHT_PROGBITS)})
+	}
+	if len(spec.Dynamic) > 0 {
+		offset := dataOffset + uint64(data.Len())
+		for _, d := range append(spec.Dynamic, elf.Dyn64{Tag: int64(elf.DT_NULL)}) {
+			Expect(binary.Write(&data, binary.LittleEndian, d)).To(Succeed())
+		}
+		sections = append(sections, elf.Section64{
+			Name: addName(".dynamic"), Type: uint32(elf.SHT_DYNAMIC), Off: offset,
+			Size: dataOffset + uint64(data.Len()) - offset, Entsize: 16,
+		})
+	}
+	if len(spec.Symbols) > 0 {
+		strtab := []byte{0}
+		symtabOffset := dataOffset + uint64(data.Len())
+		Expect(binary.Write(&data, binary.LittleEndian, elf.Sym64{})).To(Succeed())
+		for _, s := range spec.Symbols {
+			Expect(binary.Write(&data, binary.LittleEndian, elf.Sym64{
+				Name: uint32(len(strtab)),
+				Info: elf.ST_INFO(elf.STB_GLOBAL, elf.STT_FUNC),
+			})).To(Succeed())
+			strtab = append(append(strtab, s...), 0)
+		}
+		symtabSize := dataOffset + uint64(data.Len()) - symtabOffset
+		strtabOffset := dataOffset + uint64(data.Len())
+		data.Write(strtab)
+		sections = append(sections,
+			elf.Section64{
+				Name: addName(".symtab"), Type: uint32(elf.SHT_SYMTAB), Off: symtabOffset, Size: symtabSize,
+				Link: uint32(len(sections) + 1), Info: 1, Entsize: 24,
+			},
+			elf.Section64{Name: addName(".strtab"), Type: uint32(elf.SHT_STRTAB), Off: strtabOffset, Size: uint64(len(strtab))},
+		)
+	}
+	shstrtabName := addName(".shstrtab")
+	sections = append(sections, elf.Section64{
+		Name: shstrtabName, Type: uint32(elf.SHT_STRTAB), Off: dataOffset + uint64(data.Len()), Size: uint64(len(shstrtab)),
+	})
+	data.Write(shstrtab)
+
+	var out bytes.Buffer
+	header := elf.Header64{
+		Type:      uint16(spec.Type),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Phoff:     headerSize,
+		Shoff:     dataOffset + uint64(data.Len()),
+		Ehsize:    headerSize,
+		Phentsize: progSize,
+		Phnum:     uint16(len(spec.Progs)),
+		Shentsize: sectionSize,
+		Shnum:     uint16(len(sections)),
+		Shstrndx:  uint16(len(sections) - 1),
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	Expect(binary.Write(&out, binary.LittleEndian, header)).To(Succeed())
+	for _, t := range spec.Progs {
+		flags := elf.PF_R | elf.PF_W
+		if t == elf.PT_GNU_STACK && spec.ExecStack {
+			flags |= elf.PF_X
+		}
+		Expect(binary.Write(&out, binary.LittleEndian, elf.Prog64{Type: uint32(t), Flags: uint32(flags)})).To(Succeed())
+	}
+	out.Write(data.Bytes())
+	for _, s := range sections {
+		Expect(binary.Write(&out, binary.LittleEndian, s)).To(Succeed())
+	}
+	return out.Bytes()
+}
+
+// newHardenedBinariesCheck returns the check requiring minimum, which must
+// only name known properties.
+func newHardenedBinariesCheck(minimum []string) *hasHardenedBinariesCheck {
+	chk, err := NewHasHardenedBinariesCheck(minimum)
+	Expect(err).ToNot(HaveOccurred())
+	return chk
+}
+
+var _ = Describe("HasHardenedBinaries", func() {
+	var (
+		imgRef    image.ImageReference
+		ctx       context.Context
+		findings  *check.Findings
+		aw        *artifacts.MapWriter
+		hardened  elfSpec
+		sharedObj elfSpec
+	)
+
+	addFile := func(name string, mode fs.FileMode, contents []byte) {
+		p := filepath.Join(imgRef.ImageFSPath, name)
+		Expect(os.MkdirAll(filepath.Dir(p), 0o755)).To(Succeed())
+		Expect(os.WriteFile(p, contents, 0o644)).To(Succeed())
+		imgRef.Files.Add(image.FileInfo{Path: name, Mode: mode})
+	}
+
+	readReport := func() hardeningReport {
+		r, ok := aw.Files()[check.DefaultBinaryHardeningFilename]
+		Expect(ok).To(BeTrue())
+		contents, err := io.ReadAll(r)
+		Expect(err).ToNot(HaveOccurred())
+		var report hardeningReport
+		Expect(json.Unmarshal(contents, &report)).To(Succeed())
+		return report
+	}
+
+	BeforeEach(func() {
+		img, err := random.Image(256, 1)
+		Expect(err).ToNot(HaveOccurred())
+		imgRef = image.ImageReference{
+			ImageFSPath: GinkgoT().TempDir(),
+			ImageInfo:   img,
+			Files:       image.NewFileIndex(),
+		}
+		findings = &check.Findings{}
+		aw, err = artifacts.NewMapWriter()
+		Expect(err).ToNot(HaveOccurred())
+		ctx = check.ContextWithFindings(artifacts.ContextWithWriter(context.TODO(), aw), findings)
+
+		hardened = elfSpec{
+			Type:    elf.ET_DYN,
+			Progs:   []elf.ProgType{elf.PT_INTERP, elf.PT_DYNAMIC, elf.PT_GNU_RELRO, elf.PT_GNU_STACK},
+			Dynamic: []elf.Dyn64{{Tag: int64(elf.DT_FLAGS_1), Val: uint64(elf.DF_1_NOW | elf.DF_1_PIE)}},
+			Symbols: []string{"main", "__stack_chk_fail", "__memcpy_chk"},
+		}
+		sharedObj = elfSpec{
+			Type:    elf.ET_DYN,
+			Progs:   []elf.ProgType{elf.PT_DYNAMIC, elf.PT_GNU_RELRO, elf.PT_GNU_STACK},
+			Dynamic: []elf.Dyn64{{Tag: int64(elf.DT_BIND_NOW)}},
+			Symbols: []string{"__stack_chk_fail"},
+		}
+		addFile("/opt/app/bin/server", 0o755, elfBinary(hardened))
+		addFile("/opt/app/lib/libapp.so.1", 0o644, elfBinary(sharedObj))
+		addFile("/opt/app/bin/start.sh", 0o755, []byte("#!/bin/sh\n"))
+		addFile("/opt/app/share/data.bin", 0o644, elfBinary(elfSpec{Type: elf.ET_EXEC}))
+	})
+
+	Context("When the binaries are hardened", func() {
+		It("should pass Validate and write the report", func() {
+			ok, err := newHardenedBinariesCheck(nil).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+
+			report := readReport()
+			Expect(report.Minimum).To(Equal(DefaultHardeningMinimum))
+			Expect(report.Binaries).To(HaveLen(2))
+
+			server := report.Binaries[0]
+			Expect(server.Path).To(Equal("/opt/app/bin/server"))
+			Expect(server.Type).To(Equal("executable"))
+			Expect(server.Layer).ToNot(BeEmpty())
+			Expect(*server.PIE).To(BeTrue())
+			Expect(server.RELRO).To(Equal("full"))
+			Expect(server.NX).To(BeTrue())
+			Expect(*server.StackProtector).To(BeTrue())
+			Expect(*server.FortifySource).To(BeTrue())
+
+			lib := report.Binaries[1]
+			Expect(lib.Type).To(Equal("shared_object"))
+			Expect(lib.PIE).To(BeNil())
+			Expect(lib.RELRO).To(Equal("full"))
+			Expect(*lib.FortifySource).To(BeFalse())
+		})
+	})
+
+	Context("When a binary is not hardened", func() {
+		BeforeEach(func() {
+			addFile("/opt/app/bin/legacy", 0o755, elfBinary(elfSpec{
+				Type:      elf.ET_EXEC,
+				Progs:     []elf.ProgType{elf.PT_INTERP, elf.PT_DYNAMIC, elf.PT_GNU_RELRO, elf.PT_GNU_STACK},
+				ExecStack: true,
+				Dynamic:   []elf.Dyn64{{Tag: int64(elf.DT_DEBUG)}},
+				Symbols:   []string{"main"},
+			}))
+		})
+		It("should not pass Validate and report the missing properties", func() {
+			ok, err := newHardenedBinariesCheck(nil).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			legacy := readReport().Binaries[0]
+			Expect(findings.List()).To(ConsistOf(check.Finding{
+				Message:     "executable /opt/app/bin/legacy is not built with pie, relro, nx, stack-protector",
+				Severity:    check.SeverityWarning,
+				Path:        "/opt/app/bin/legacy",
+				Layer:       legacy.Layer,
+				Remediation: "Rebuild the binary with -fPIE -pie -Wl,-z,relro,-z,now -Wl,-z,noexecstack -fstack-protector-strong",
+			}))
+			Expect(legacy.RELRO).To(Equal("partial"))
+			Expect(legacy.Missing).To(Equal([]string{"pie", "relro", "nx", "stack-protector"}))
+		})
+		It("should only report the binary when no minimum is required", func() {
+			ok, err := newHardenedBinariesCheck([]string{"none"}).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(readReport().Binaries).To(HaveLen(3))
+		})
+		It("should only require the configured minimum", func() {
+			ok, err := newHardenedBinariesCheck([]string{"NX"}).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(HaveLen(1))
+			Expect(findings.List()[0].Message).To(HaveSuffix("is not built with nx"))
+		})
+	})
+
+	Context("When a Go binary is not built as PIE", func() {
+		BeforeEach(func() {
+			addFile("/usr/local/bin/operator", 0o755, elfBinary(elfSpec{
+				Type:     elf.ET_EXEC,
+				Progs:    []elf.ProgType{elf.PT_GNU_STACK},
+				Sections: []string{".go.buildinfo"},
+			}))
+		})
+		It("should only require the properties that apply to Go", func() {
+			ok, err := newHardenedBinariesCheck([]string{"pie", "stack-protector"}).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(HaveLen(1))
+			Expect(findings.List()[0].Message).To(Equal("executable /usr/local/bin/operator is not built with pie"))
+			Expect(findings.List()[0].Remediation).To(Equal("Rebuild the binary with go build -buildmode=pie"))
+
+			operator := readReport().Binaries[2]
+			Expect(operator.Language).To(Equal("go"))
+			Expect(operator.Static).To(BeTrue())
+			Expect(operator.StackProtector).To(BeNil())
+		})
+	})
+
+	Context("When the minimum names an unknown property", func() {
+		It("should return an error when creating the check", func() {
+			_, err := NewHasHardenedBinariesCheck([]string{"pie", "aslr"})
+			Expect(err).To(MatchError(ContainSubstring(`unknown hardening property "aslr"`)))
+		})
+	})
+
+	Context("When checking metadata", func() {
+		It("should be warn-level and require the filesystem", func() {
+			chk := newHardenedBinariesCheck(nil)
+			Expect(chk.Name()).To(Equal("HasHardenedBinaries"))
+			Expect(chk.Metadata().Level).To(Equal(check.LevelWarn))
+			Expect(chk.Requirements()).To(Equal(check.RequiresFilesystem))
+		})
+	})
+})
@@ -22,7 +22,10 @@ const (
 
 var errLicensesNotADir = errors.New("licenses is not a directory")
 
-var _ check.Check = &HasLicenseCheck{}
+var (
+	_ check.Check             = &HasLicenseCheck{}
+	_ check.RequirementsCheck = &HasLicenseCheck{}
+)
 
 // HasLicenseCheck evaluates that the image contains a license definition available at
 // /licenses.
@@ -32,6 +35,12 @@ func (p *HasLicenseCheck) Validate(ctx context.Context, imgRef image.ImageRefere
 	licenseFileList, err := p.getDataToValidate(ctx, imgRef.ImageFSPath)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, errLicensesNotADir) {
+			check.AddFinding(ctx, check.Finding{
+				Message:     fmt.Sprintf("%s is missing or is not a directory", licensePath),
+				Severity:    check.SeverityError,
+				Path:        licensePath,
+				Remediation: fmt.Sprintf("Copy your license files into %s, e.g. with COPY LICENSE %s/ in your Containerfile", licensePath, licensePath),
+			})
 			return false, nil
 		}
 		return false, fmt.Errorf("could not get license file list: %v", err)
@@ -57,7 +66,6 @@ func (p *HasLicenseCheck) getDataToValidate(ctx context.Context, mountedPath str
 	return files, nil
 }
 
-//nolint:unparam // ctx is unused. Keep for future use.
 func (p *HasLicenseCheck) validate(ctx context.Context, licenseFileList []fs.DirEntry) (bool, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 
@@ -73,7 +81,22 @@ func (p *HasLicenseCheck) validate(ctx context.Context, licenseFileList []fs.Dir
 		}
 	}
 	logger.V(log.DBG).Info("number of licenses found", "licenseCount", len(licenseFileList))
-	return len(licenseFileList) >= minLicenseFileCount && nonZeroLength, nil
+
+	if len(licenseFileList) < minLicenseFileCount || !nonZeroLength {
+		check.AddFinding(ctx, check.Finding{
+			Message:     fmt.Sprintf("%s does not contain any non-empty license files", licensePath),
+			Severity:    check.SeverityError,
+			Path:        licensePath,
+			Remediation: fmt.Sprintf("Add the license terms of your software to %s", licensePath),
+		})
+		return false, nil
+	}
+	return true, nil
+}
+
+// Requirements implements check.RequirementsCheck.
+func (p *HasLicenseCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem
 }
 
 func (p *HasLicenseCheck) Name() string {
@@ -2,7 +2,6 @@ package container
 
 import (
 	"archive/tar"
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
@@ -23,7 +22,10 @@ import (
 	"github.com/spf13/afero"
 )
 
-var _ check.Check = &HasModifiedFilesCheck{}
+var (
+	_ check.Check             = &HasModifiedFilesCheck{}
+	_ check.RequirementsCheck = &HasModifiedFilesCheck{}
+)
 
 // HasModifiedFilesCheck evaluates that no files from the base layer have been modified by
 // subsequent layers by comparing the file list installed by Packages against the file list
@@ -32,6 +34,9 @@ type HasModifiedFilesCheck struct{}
 
 const whiteoutPrefix = ".wh."
 
+// platformIDPattern matches the distribution in an os-release PLATFORM_ID value.
+var platformIDPattern = regexp.MustCompile(`^platform:([[:alnum:]]+)`)
+
 type packageMeta struct {
 	Name        string
 	Version     string
@@ -45,6 +50,11 @@ func (pm packageMeta) Compare(other packageMeta) int {
 	return 0
 }
 
+// NVRA returns the name-version-release.arch of the package.
+func (pm packageMeta) NVRA() string {
+	return fmt.Sprintf("%s-%s-%s.%s", pm.Name, pm.Version, pm.Release, pm.Arch)
+}
+
 type packageFilesRef struct {
 	// LayerFiles contains a slice of files created/modified in layer
 	LayerFiles []string
@@ -65,7 +75,7 @@ func (p *HasModifiedFilesCheck) Validate(ctx context.Context, imgRef image.Image
 		return false, fmt.Errorf("could not generate modified files list: %v", err)
 	}
 
-	packageDist, err := p.parsePackageDist(ctx, imgRef.ImageFSPath, fs)
+	packageDist, err := p.parsePackageDist(ctx, imgRef.ImageFacts())
 	if err != nil {
 		return false, fmt.Errorf("could not generate modified files list: %v", err)
 	}
@@ -75,30 +85,18 @@ func (p *HasModifiedFilesCheck) Validate(ctx context.Context, imgRef image.Image
 
 // parsePackageDist returns the platform's distribution value from the
 // os-release contents in the extracted image.
-func (p *HasModifiedFilesCheck) parsePackageDist(_ context.Context, extractedImageFSPath string, fs afero.Fs) (string, error) {
-	osRelease, err := fs.Open(filepath.Join(extractedImageFSPath, "etc", "os-release"))
+func (p *HasModifiedFilesCheck) parsePackageDist(_ context.Context, facts *image.Facts) (string, error) {
+	osRelease, err := facts.OSRelease()
 	if err != nil {
-		return "", fmt.Errorf("could not open os-release: %v", err)
-	}
-	defer osRelease.Close()
-	scanner := bufio.NewScanner(osRelease)
-	packageDist := "unknown"
-	for scanner.Scan() {
-		line := scanner.Text()
-		r, _ := regexp.Compile(`PLATFORM_ID="platform:([[:alnum:]]+)"`)
-		m := r.FindStringSubmatch(line)
-		if m == nil {
-			continue
-		}
-		packageDist = m[1]
-		break
+		return "", err
 	}
 
-	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("error while scanning for package dist: %v", err)
+	m := platformIDPattern.FindStringSubmatch(osRelease["PLATFORM_ID"])
+	if m == nil {
+		return "unknown", nil
 	}
 
-	return packageDist, nil
+	return m[1], nil
 }
 
 // gatherDataToValidate returns a map from layer digest to a struct containing the list of files
@@ -125,6 +123,11 @@ func (p *HasModifiedFilesCheck) gatherDataToValidate(ctx context.Context, imgRef
 		return nil, nil, err
 	}
 
+	layerFacts, err := imgRef.ImageFacts().Layers()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	layerIDs := make([]string, 0, len(layers))
 	layerRefs := make(map[string]packageFilesRef, len(layers))
 
@@ -133,21 +136,9 @@ func (p *HasModifiedFilesCheck) gatherDataToValidate(ctx context.Context, imgRef
 	// Also generate a list of the layerIDs so we can keep the
 	// order within the maps.
 	for idx, layer := range layers {
-		layerIDHash, err := layer.Digest()
-		if err != nil {
-			return nil, nil, fmt.Errorf("unable to retrieve diff id for layer: %w", err)
-		}
-
-		// Capture the diff ID to aid in debugging. We don't technically care if
-		// there's an error returned here because we don't use the layerDiffID
-		// value for anything meaningful.
-		layerDiffID := "unknown"
-		layerDiffHash, err := layer.DiffID()
-		if err == nil && layerDiffHash.String() != "" {
-			layerDiffID = layerDiffHash.String()
-		}
+		rawLayerID := layerFacts[idx].Digest.String()
+		layerDiffID := layerFacts[idx].DiffID.String()
 
-		rawLayerID := layerIDHash.String()
 		// Map everything using a combination of the layer index and the layer
 		// ID to avoid problems when images have multiple scattered layers with
 		// the same ID.
@@ -162,10 +153,7 @@ func (p *HasModifiedFilesCheck) gatherDataToValidate(ctx context.Context, imgRef
 
 		layerIDs = append(layerIDs, layerID)
 
-		files, err := generateChangesFor(ctx, layer)
-		if err != nil {
-			return nil, nil, err
-		}
+		files := generateChangesFor(ctx, layerFacts[idx].Files)
 
 		found, pkgList := findRPMDB(ctx, layer)
 		if !found {
@@ -238,12 +226,14 @@ func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string,
 				if !strings.Contains(currentPackage.Release, packageDist) && packageDist != "unknown" {
 					// This means it's _probably_ not a RH package. If the file is changed, warn, but don't fail
 					logger.Info("WARN: an rpm-installed file was modified outside of rpm, but appears to be from a third-party. This could be a failure in the future")
+					reportModifiedFile(ctx, check.SeverityWarning, "third-party rpm-installed file was modified outside of rpm", modifiedFile, currentPackage, layerID)
 					continue
 				}
 
 				if currentPackage.Vendor != "Red Hat, Inc." && previousPackage.Vendor != "Red Hat, Inc." {
 					// This means it's _probably_ not a RH package. If the file is changed, warn, but don't fail
 					logger.Info("WARN: an rpm-installed file was modified outside of rpm, but appears to be from a third-party. This could be a failure in the future")
+					reportModifiedFile(ctx, check.SeverityWarning, "third-party rpm-installed file was modified outside of rpm", modifiedFile, currentPackage, layerID)
 					continue
 				}
 
@@ -258,6 +248,7 @@ func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string,
 
 				// Nope, nope, nope. File was modified without using RPM
 				logger.Info("found disallowed modification in layer", "file", modifiedFile)
+				reportModifiedFile(ctx, check.SeverityError, "rpm-installed file was modified outside of rpm", modifiedFile, currentPackage, layerID)
 				disallowedModifications = true
 				continue
 			}
@@ -269,6 +260,7 @@ func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string,
 
 			if previousOsRelease && !currentOsRelease {
 				logger.Info("mismatch in OS release", "file", modifiedFile)
+				reportModifiedFile(ctx, check.SeverityError, "package providing the file was replaced with one built for a different OS release", modifiedFile, currentPackage, layerID)
 				disallowedModifications = true
 				continue
 			}
@@ -276,6 +268,7 @@ func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string,
 			// Check that the architectures for previous version and current version of a given package match
 			if previousPackage.Arch != currentPackage.Arch {
 				logger.Info("mismatch in package architecture", "file", modifiedFile)
+				reportModifiedFile(ctx, check.SeverityError, "package providing the file was replaced with one built for a different architecture", modifiedFile, currentPackage, layerID)
 				disallowedModifications = true
 				continue
 			}
@@ -287,6 +280,26 @@ func (p *HasModifiedFilesCheck) validate(ctx context.Context, layerIDs []string,
 	return !disallowedModifications, nil
 }
 
+// reportModifiedFile records a finding for file, which is provided by pkg and
+// was modified in layerID.
+func reportModifiedFile(ctx context.Context, severity, message, file string, pkg packageMeta, layerID string) {
+	// layerIDs are prefixed with the layer's index.
+	_, digest, _ := strings.Cut(layerID, "-")
+	check.AddFinding(ctx, check.Finding{
+		Message:     message,
+		Severity:    severity,
+		Path:        "/" + file,
+		Package:     pkg.NVRA(),
+		Layer:       digest,
+		Remediation: "Update or reinstall the package with dnf instead of modifying its files",
+	})
+}
+
+// Requirements implements check.RequirementsCheck.
+func (p HasModifiedFilesCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
 func (p HasModifiedFilesCheck) Name() string {
 	return "HasModifiedFiles"
 }
@@ -472,55 +485,34 @@ func installedFileMapWithExclusions(ctx context.Context, pkglist []*rpmdb.Packag
 	return m, nil
 }
 
-// generateChangesFor will check layer for file changes, and will return a list of those.
-func generateChangesFor(ctx context.Context, layer v1.Layer) ([]string, error) {
+// generateChangesFor will check the files in a layer for changes, and will return a list of those.
+func generateChangesFor(ctx context.Context, layerFiles []image.FileInfo) []string {
 	logger := logr.FromContextOrDiscard(ctx)
-	layerReader, err := layer.Uncompressed()
-	if err != nil {
-		return nil, fmt.Errorf("reading layer contents: %w", err)
-	}
-	defer layerReader.Close()
-	tarReader := tar.NewReader(layerReader)
 	// Use a map so we can remove items easily. Will turn this into a string slice before returning
 	filelist := make(map[string]struct{})
 	var links []string
-	for {
-		header, err := tarReader.Next()
-		if errors.Is(err, io.EOF) {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("reading tar: %w", err)
-		}
-
-		// Some tools prepend everything with "./", so if we don't Clean the
-		// name, we may have duplicate entries, which angers tar-split.
-		header.Name = filepath.Clean(header.Name)
-		// force PAX format to remove Name/Linkname length limit of 100 characters
-		// required by USTAR and to not depend on internal tar package guess which
-		// prefers USTAR over PAX
-		header.Format = tar.FormatPAX
-
-		basename := filepath.Base(header.Name)
-		dirname := filepath.Dir(header.Name)
+	for _, file := range layerFiles {
+		name := strings.TrimPrefix(file.Path, "/")
+		basename := filepath.Base(name)
+		dirname := filepath.Dir(name)
 		tombstone := strings.HasPrefix(basename, whiteoutPrefix)
 		if tombstone {
 			basename = basename[len(whiteoutPrefix):]
 		}
 
 		// If there is a capability entry, ignore the file
-		if _, found := header.PAXRecords["SCHILY.xattr.security.capability"]; found {
-			logger.V(log.TRC).Info("security capabilities found in layer tar, ignoring file", "file", header.Name)
+		if file.Capability() != nil {
+			logger.V(log.TRC).Info("security capabilities found in layer tar, ignoring file", "file", name)
 			continue
 		}
 
 		switch {
-		case (header.Typeflag == tar.TypeDir && tombstone) || header.Typeflag == tar.TypeReg:
-			filelist[strings.TrimPrefix(filepath.Join(dirname, basename), "/")] = struct{}{}
-		case header.Typeflag == tar.TypeSymlink || header.Typeflag == tar.TypeLink:
-			filelist[strings.TrimPrefix(header.Name, "/")] = struct{}{}
+		case file.HardLink || file.Mode&os.ModeSymlink != 0:
+			filelist[name] = struct{}{}
 			// Add the target to the links slice so we can remove them later
-			links = append(links, strings.TrimPrefix(header.Linkname, "/"))
+			links = append(links, strings.TrimPrefix(file.Linkname, "/"))
+		case (file.Mode.IsDir() && tombstone) || file.Mode.IsRegular():
+			filelist[strings.TrimPrefix(filepath.Join(dirname, basename), "/")] = struct{}{}
 		default:
 			// TODO: what do we do with other flags?
 			continue
@@ -540,7 +532,7 @@ func generateChangesFor(ctx context.Context, layer v1.Layer) ([]string, error) {
 		keys = append(keys, k)
 	}
 
-	return keys, nil
+	return keys
 }
 
 // ExtractRPMDB copies /var/lib/rpm/* from the archive and derives a list of packages from
@@ -1,6 +1,7 @@
 package container
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"path"
@@ -8,6 +9,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/spf13/afero"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 
 	"github.com/bombsimon/logrusr/v4"
@@ -182,6 +184,21 @@ var _ = Describe("HasModifiedFiles", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ok).To(BeFalse())
 			})
+			It("should report the file, package and layer as a finding", func() {
+				layers[1] = "01-sha256:abc"
+				pkgs[layers[1]] = pkgs["secondlayer"]
+				findings := &check.Findings{}
+				_, err := hasModifiedFiles.validate(check.ContextWithFindings(context.Background(), findings), layers, pkgs, dist)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(findings.List()).To(ConsistOf(check.Finding{
+					Message:     "rpm-installed file was modified outside of rpm",
+					Severity:    check.SeverityError,
+					Path:        "/this",
+					Package:     "foo-1.0-1.d9.fooarch",
+					Layer:       "sha256:abc",
+					Remediation: "Update or reinstall the package with dnf instead of modifying its files",
+				}))
+			})
 		})
 		When("a package is updated", func() {
 			var pkgs map[string]packageFilesRef
@@ -436,6 +453,23 @@ var _ = Describe("HasModifiedFiles", func() {
 		})
 	})
 
+	When("listing the changes in a layer", func() {
+		It("should include whiteouts and links but not link targets or files with capabilities", func() {
+			files := []image.FileInfo{
+				image.FileInfoFromHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "usr/bin/foo"}, 1),
+				image.FileInfoFromHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "usr/lib/.wh.libbar.so"}, 1),
+				image.FileInfoFromHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "usr/bin/baz", Linkname: "/usr/bin/foo"}, 1),
+				image.FileInfoFromHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "usr/share"}, 1),
+				image.FileInfoFromHeader(&tar.Header{
+					Typeflag:   tar.TypeReg,
+					Name:       "usr/bin/ping",
+					PAXRecords: map[string]string{"SCHILY.xattr.security.capability": "\x01"},
+				}, 1),
+			}
+			Expect(generateChangesFor(context.TODO(), files)).To(ConsistOf("usr/lib/libbar.so", "usr/bin/baz"))
+		})
+	})
+
 	When("calling the top level Validate", func() {
 		It("should fail with an invalid ImageReference", func() {
 			passed, err := hasModifiedFiles.Validate(context.TODO(), image.ImageReference{})
@@ -0,0 +1,301 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+
+	"github.com/go-logr/logr"
+)
+
+var (
+	_ check.Check             = &hasNoEmbeddedSecretsCheck{}
+	_ check.RequirementsCheck = &hasNoEmbeddedSecretsCheck{}
+)
+
+// maxSecretScanFileSize is the size of the largest file scanned for secrets.
+// Credentials and keys are small, while larger files are mostly data that
+// would slow down the scan.
+const maxSecretScanFileSize = 1 << 20
+
+// secretScanExcludedPaths are not scanned for secrets. They hold content
+// shipped by packages, such as documentation and test fixtures, that is
+// known to contain example keys.
+var secretScanExcludedPaths = []string{
+	"/proc",
+	"/sys",
+	"/dev",
+	"/usr/share/doc",
+	"/usr/share/man",
+	"/usr/share/info",
+	"/usr/share/locale",
+	"/usr/share/licenses",
+	"/usr/include",
+	"/var/lib/rpm",
+	"/var/cache",
+}
+
+// secretPattern describes a kind of secret that is matched with high
+// confidence.
+type secretPattern struct {
+	description string
+	re          *regexp.Regexp
+	// secretGroup is the index of the submatch holding the secret value,
+	// which is shown redacted. If 0, no part of the match is shown.
+	secretGroup int
+}
+
+var secretPatterns = []secretPattern{
+	{description: "PEM private key", re: regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |ENCRYPTED |PGP )?PRIVATE KEY(?: BLOCK)?-----`)},
+	{description: "AWS access key ID", re: regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`), secretGroup: 1},
+	{description: "AWS secret access key", re: regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[:=]\s*["']?([A-Za-z0-9/+]{40})\b`), secretGroup: 1},
+	{description: "GCP service account key", re: regexp.MustCompile(`"private_key_id"\s*:\s*"([0-9a-f]{40})"`), secretGroup: 1},
+	{description: "GCP API key", re: regexp.MustCompile(`\b(AIza[0-9A-Za-z_\-]{35})\b`), secretGroup: 1},
+	{description: "Azure storage account key", re: regexp.MustCompile(`AccountKey=([A-Za-z0-9+/]{86}==)`), secretGroup: 1},
+	{description: "registry credentials", re: regexp.MustCompile(`"auth"\s*:\s*"([A-Za-z0-9+/]{8,}={0,2})"`), secretGroup: 1},
+	{description: "npm auth token", re: regexp.MustCompile(`_authToken\s*=\s*([^\s$"'][^\s"']*)`), secretGroup: 1},
+	{description: "kubeconfig client key", re: regexp.MustCompile(`client-key-data:\s*([A-Za-z0-9+/]{20,}={0,2})`), secretGroup: 1},
+}
+
+// NewHasNoEmbeddedSecretsCheck returns a check scanning the image for secrets.
+// Unless enforcing, secrets are reported as a warning without failing the
+// certification.
+func NewHasNoEmbeddedSecretsCheck(enforcing bool) *hasNoEmbeddedSecretsCheck {
+	return &hasNoEmbeddedSecretsCheck{enforcing: enforcing}
+}
+
+// hasNoEmbeddedSecretsCheck scans the image's filesystem and environment for
+// secrets that were included by accident, such as private keys, cloud
+// provider credentials, registry credentials, npm tokens and kubeconfigs.
+// Only patterns that identify secrets with high confidence are matched, and
+// matched values are redacted in the findings.
+type hasNoEmbeddedSecretsCheck struct {
+	enforcing bool
+}
+
+// secretMatch is a secret found in the image.
+type secretMatch struct {
+	// Description names the kind of secret, e.g. PEM private key.
+	Description string
+	// Path is the absolute path of the file in the image, or empty if the
+	// secret was found in the environment.
+	Path string
+	// Env is the name of the environment variable holding the secret.
+	Env string
+	// Line is the line number of the match in the file.
+	Line int
+	// Redacted is the redacted value of the secret, if it can be shown.
+	Redacted string
+	// Layer is the digest of the layer that added the file.
+	Layer string
+}
+
+func (p *hasNoEmbeddedSecretsCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	matches, err := p.getDataToValidate(ctx, imgRef)
+	if err != nil {
+		return false, fmt.Errorf("could not scan image for secrets: %v", err)
+	}
+
+	return p.validate(ctx, matches)
+}
+
+func (p *hasNoEmbeddedSecretsCheck) getDataToValidate(ctx context.Context, imgRef image.ImageReference) ([]secretMatch, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	config, err := imgRef.ImageFacts().Config()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve image config: %w", err)
+	}
+
+	var matches []secretMatch
+	for _, env := range config.Config.Env {
+		name, value, _ := strings.Cut(env, "=")
+		for _, m := range findSecrets([]byte(value)) {
+			m.Env = name
+			matches = append(matches, m)
+		}
+	}
+
+	if imgRef.ImageFSPath == "" {
+		return matches, nil
+	}
+
+	var layerDigests []string
+	if imgRef.Files != nil {
+		layerDigests, err = getLayerDigests(imgRef.ImageInfo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	scanned := 0
+	fileSystem := os.DirFS(imgRef.ImageFSPath)
+	err = fs.WalkDir(fileSystem, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		imagePath := image.CleanPath(p)
+		if excludedFromSecretScan(imagePath) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() == 0 || info.Size() > maxSecretScanFileSize {
+			return nil
+		}
+
+		contents, err := fs.ReadFile(fileSystem, p)
+		if err != nil {
+			// Files extracted with restrictive permissions, e.g. /etc/shadow,
+			// cannot be read when not running as root.
+			logger.V(log.DBG).Info("could not read file", "path", imagePath, "reason", err)
+			return nil
+		}
+		// Binary files are not expected to hold the text formats matched.
+		if bytes.IndexByte(contents[:min(len(contents), 8192)], 0) != -1 {
+			return nil
+		}
+		scanned++
+
+		for _, m := range findSecrets(contents) {
+			m.Path = imagePath
+			if fileInfo, ok := imgRef.Files.Lookup(imagePath); ok && fileInfo.Layer < len(layerDigests) {
+				m.Layer = layerDigests[fileInfo.Layer]
+			}
+			matches = append(matches, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read image filesystem: %w", err)
+	}
+	logger.V(log.DBG).Info("scanned image for secrets", "files", scanned)
+
+	return matches, nil
+}
+
+// findSecrets returns the first match of each secret pattern in contents.
+func findSecrets(contents []byte) []secretMatch {
+	var matches []secretMatch
+	for _, pattern := range secretPatterns {
+		loc := pattern.re.FindSubmatchIndex(contents)
+		if loc == nil {
+			continue
+		}
+		m := secretMatch{
+			Description: pattern.description,
+			Line:        bytes.Count(contents[:loc[0]], []byte("\n")) + 1,
+		}
+		if pattern.secretGroup > 0 {
+			m.Redacted = redactSecret(string(contents[loc[2*pattern.secretGroup]:loc[2*pattern.secretGroup+1]]))
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// redactSecret hides all but the first four characters of secret, so that it
+// can be identified without being disclosed.
+func redactSecret(secret string) string {
+	if len(secret) <= 8 {
+		return "********"
+	}
+	return secret[:4] + strings.Repeat("*", 8)
+}
+
+// excludedFromSecretScan returns true if the file or directory at p, an
+// absolute path in the image, is not scanned for secrets.
+func excludedFromSecretScan(p string) bool {
+	for _, excluded := range secretScanExcludedPaths {
+		if p == excluded || strings.HasPrefix(p, excluded+"/") {
+			return true
+		}
+	}
+	// Test fixtures, which commonly contain sample keys, are kept in testdata
+	// directories by convention.
+	return path.Base(p) == "testdata"
+}
+
+func (p *hasNoEmbeddedSecretsCheck) validate(ctx context.Context, matches []secretMatch) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	severity := check.SeverityWarning
+	if p.enforcing {
+		severity = check.SeverityError
+	}
+
+	for _, m := range matches {
+		var location, remediation string
+		if m.Env != "" {
+			location = fmt.Sprintf("environment variable %s", m.Env)
+			remediation = fmt.Sprintf("Remove %s from the image's environment and provide it at runtime, e.g. from a Secret", m.Env)
+		} else {
+			location = fmt.Sprintf("%s, line %d", m.Path, m.Line)
+			remediation = fmt.Sprintf("Remove %s from the image, and from every layer that added it, and revoke the secret", m.Path)
+		}
+		message := fmt.Sprintf("%s found in %s", m.Description, location)
+		if m.Redacted != "" {
+			message = fmt.Sprintf("%s %s found in %s", m.Description, m.Redacted, location)
+		}
+
+		check.AddFinding(ctx, check.Finding{
+			Message:     message,
+			Severity:    severity,
+			Path:        m.Path,
+			Layer:       m.Layer,
+			Remediation: remediation,
+		})
+	}
+
+	if len(matches) > 0 {
+		logger.V(log.DBG).Info("secrets found in image", "count", len(matches))
+	}
+
+	return len(matches) == 0, nil
+}
+
+// Requirements implements check.RequirementsCheck.
+func (p *hasNoEmbeddedSecretsCheck) Requirements() check.Requirement {
+	return check.RequiresConfig | check.RequiresFilesystem
+}
+
+func (p *hasNoEmbeddedSecretsCheck) Name() string {
+	return "HasNoEmbeddedSecrets"
+}
+
+func (p *hasNoEmbeddedSecretsCheck) Metadata() check.Metadata {
+	level := check.LevelWarn
+	if p.enforcing {
+		level = check.LevelBest
+	}
+	return check.Metadata{
+		Description:      "Checking that the image's filesystem and environment do not contain secrets such as private keys, cloud credentials or registry credentials.",
+		Level:            level,
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *hasNoEmbeddedSecretsCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasNoEmbeddedSecrets encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Remove secrets from the image and provide them at runtime instead, e.g. from a Secret. Secrets removed in a later layer remain in the layer that added them, so rebuild the image without them and revoke any secret that was published.",
+	}
+}
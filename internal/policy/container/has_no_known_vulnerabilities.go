@@ -0,0 +1,138 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/advisory"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/sbom"
+
+	"github.com/go-logr/logr"
+)
+
+var (
+	_ check.Check             = &hasNoKnownVulnerabilitiesCheck{}
+	_ check.RequirementsCheck = &hasNoKnownVulnerabilitiesCheck{}
+)
+
+// NewHasNoKnownVulnerabilitiesCheck returns a check matching the image's rpms
+// and Go modules against the advisories in advisoryDB, an OSV or CSAF JSON
+// file or a directory of them. If advisoryDB is empty, the check is skipped.
+func NewHasNoKnownVulnerabilitiesCheck(advisoryDB string) *hasNoKnownVulnerabilitiesCheck {
+	return &hasNoKnownVulnerabilitiesCheck{advisoryDB: advisoryDB}
+}
+
+// hasNoKnownVulnerabilitiesCheck reports the packages in the image that are
+// affected by a known vulnerability, before the image is submitted and
+// scanned by Red Hat. Advisories are read from a local file, so no network
+// access is needed, and the results are only as current as that file.
+type hasNoKnownVulnerabilitiesCheck struct {
+	advisoryDB string
+}
+
+func (p *hasNoKnownVulnerabilitiesCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	if p.advisoryDB == "" {
+		return false, check.MissingPrerequisite("no advisory database was provided")
+	}
+
+	matches, err := p.getDataToValidate(ctx, imgRef)
+	if err != nil {
+		return false, fmt.Errorf("could not match image against advisories: %v", err)
+	}
+
+	return p.validate(ctx, matches)
+}
+
+func (p *hasNoKnownVulnerabilitiesCheck) getDataToValidate(ctx context.Context, imgRef image.ImageReference) ([]advisory.Match, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	if imgRef.Files == nil {
+		return nil, errors.New("the image's file metadata is not available")
+	}
+
+	db, err := advisory.Load(p.advisoryDB)
+	if err != nil {
+		return nil, err
+	}
+	logger.V(log.DBG).Info("loaded advisory database", "path", p.advisoryDB, "advisories", len(db.Advisories))
+
+	s, err := sbom.Collect(ctx, imgRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Match(s.Components), nil
+}
+
+func (p *hasNoKnownVulnerabilitiesCheck) validate(ctx context.Context, matches []advisory.Match) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	for _, m := range matches {
+		id := m.Advisory.ID
+		if len(m.Advisory.Aliases) > 0 {
+			id = fmt.Sprintf("%s (%s)", id, strings.Join(m.Advisory.Aliases, ", "))
+		}
+		severity := m.Advisory.Severity
+		if severity == "" {
+			severity = "unknown"
+		}
+
+		message := fmt.Sprintf("%s %s is affected by %s, severity %s", m.Component.Name, m.Component.Version, id, severity)
+		if m.Advisory.Summary != "" {
+			message += ": " + m.Advisory.Summary
+		}
+		remediation := fmt.Sprintf("No fixed version of %s is known; consider removing it, or check for a fix in the advisory", m.Component.Name)
+		if m.FixedVersion != "" {
+			message += fmt.Sprintf(". Fixed in %s", m.FixedVersion)
+			remediation = fmt.Sprintf("Update %s to %s or later", m.Component.Name, m.FixedVersion)
+			if m.Component.Type == sbom.TypeGolang {
+				remediation = fmt.Sprintf("Rebuild %s with %s %s or later", m.Component.Path, m.Component.Name, m.FixedVersion)
+			}
+		}
+
+		check.AddFinding(ctx, check.Finding{
+			Message:     message,
+			Severity:    check.SeverityWarning,
+			Path:        m.Component.Path,
+			Package:     m.Component.Name + "-" + m.Component.Version,
+			Layer:       m.Component.Layer,
+			Remediation: remediation,
+		})
+	}
+
+	if len(matches) > 0 {
+		logger.V(log.DBG).Info("known vulnerabilities found in image", "count", len(matches))
+	}
+
+	return len(matches) == 0, nil
+}
+
+// Requirements implements check.RequirementsCheck.
+func (p *hasNoKnownVulnerabilitiesCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
+func (p *hasNoKnownVulnerabilitiesCheck) Name() string {
+	return "HasNoKnownVulnerabilities"
+}
+
+func (p *hasNoKnownVulnerabilitiesCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the image's rpms and Go modules are not affected by the vulnerabilities in the provided advisory database.",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *hasNoKnownVulnerabilitiesCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasNoKnownVulnerabilities encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Update the affected packages to a fixed version, rebuild Go binaries with fixed modules, and rebuild the image on an up to date base image.",
+	}
+}
@@ -0,0 +1,109 @@
+package container
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("HasNoKnownVulnerabilities", func() {
+	var (
+		imgRef     image.ImageReference
+		advisoryDB string
+	)
+
+	writeAdvisory := func(contents string) {
+		Expect(os.WriteFile(advisoryDB, []byte(contents), 0o644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		img, err := random.Image(256, 1)
+		Expect(err).ToNot(HaveOccurred())
+		imgRef = image.ImageReference{
+			ImageFSPath: GinkgoT().TempDir(),
+			ImageInfo:   img,
+			Files:       image.NewFileIndex(),
+		}
+
+		// The test binary is built with Go, so its modules are found.
+		executable, err := os.Executable()
+		Expect(err).ToNot(HaveOccurred())
+		contents, err := os.ReadFile(executable)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(imgRef.ImageFSPath, "usr", "bin"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(imgRef.ImageFSPath, "usr", "bin", "app"), contents, 0o755)).To(Succeed())
+		imgRef.Files.Add(image.FileInfo{Path: "/usr/bin/app", Mode: 0o755})
+
+		advisoryDB = filepath.Join(GinkgoT().TempDir(), "advisories.json")
+	})
+
+	Context("When no advisory database is provided", func() {
+		It("should be skipped", func() {
+			_, err := NewHasNoKnownVulnerabilitiesCheck("").Validate(context.TODO(), imgRef)
+			reason, ok := check.IsMissingPrerequisite(err)
+			Expect(ok).To(BeTrue())
+			Expect(reason).To(Equal("no advisory database was provided"))
+		})
+	})
+
+	Context("When no advisory affects the image", func() {
+		BeforeEach(func() {
+			writeAdvisory(`{"id": "GO-0000-0001", "affected": [{"package": {"ecosystem": "Go", "name": "stdlib"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.0.0"}]}]}]}`)
+		})
+		It("should pass Validate", func() {
+			ok, err := NewHasNoKnownVulnerabilitiesCheck(advisoryDB).Validate(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When an advisory affects the image", func() {
+		BeforeEach(func() {
+			writeAdvisory(`{
+				"id": "GO-0000-0002",
+				"aliases": ["CVE-0000-0002"],
+				"summary": "Everything is vulnerable",
+				"database_specific": {"severity": "HIGH"},
+				"affected": [{"package": {"ecosystem": "Go", "name": "stdlib"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "999.0.0"}]}]}]
+			}`)
+		})
+		It("should not pass Validate and report the affected module", func() {
+			findings := &check.Findings{}
+			ctx := check.ContextWithFindings(context.TODO(), findings)
+			ok, err := NewHasNoKnownVulnerabilitiesCheck(advisoryDB).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			Expect(findings.List()).To(HaveLen(1))
+			finding := findings.List()[0]
+			Expect(finding.Severity).To(Equal(check.SeverityWarning))
+			Expect(finding.Message).To(HavePrefix("stdlib go1."))
+			Expect(finding.Message).To(HaveSuffix("is affected by GO-0000-0002 (CVE-0000-0002), severity HIGH: Everything is vulnerable. Fixed in 999.0.0"))
+			Expect(finding.Path).To(Equal("/usr/bin/app"))
+			Expect(finding.Remediation).To(Equal("Rebuild /usr/bin/app with stdlib 999.0.0 or later"))
+		})
+	})
+
+	Context("When the advisory database cannot be read", func() {
+		It("should return an error", func() {
+			_, err := NewHasNoKnownVulnerabilitiesCheck(advisoryDB).Validate(context.TODO(), imgRef)
+			Expect(err).To(MatchError(ContainSubstring("could not read advisory database")))
+		})
+	})
+
+	Context("When checking metadata", func() {
+		It("should be warn-level and require the filesystem", func() {
+			chk := NewHasNoKnownVulnerabilitiesCheck(advisoryDB)
+			Expect(chk.Name()).To(Equal("HasNoKnownVulnerabilities"))
+			Expect(chk.Metadata().Level).To(Equal(check.LevelWarn))
+			Expect(chk.Requirements()).To(Equal(check.RequiresFilesystem))
+		})
+	})
+})
@@ -0,0 +1,175 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+
+	"github.com/go-logr/logr"
+)
+
+var (
+	_ check.Check             = &hasNoUnexpectedFileCapabilitiesCheck{}
+	_ check.RequirementsCheck = &hasNoUnexpectedFileCapabilitiesCheck{}
+)
+
+// DefaultAllowedCapabilities are the file capabilities permitted when no
+// allowlist is configured. The restricted SCC allows binding to ports below
+// 1024, so cap_net_bind_service does not fail at runtime.
+var DefaultAllowedCapabilities = []string{"cap_net_bind_service"}
+
+// NewHasNoUnexpectedFileCapabilitiesCheck returns a check that fails if any
+// executable carries file capabilities outside of allowed, e.g. cap_net_raw.
+// If allowed is empty, DefaultAllowedCapabilities are permitted.
+func NewHasNoUnexpectedFileCapabilitiesCheck(allowed []string) *hasNoUnexpectedFileCapabilitiesCheck {
+	if len(allowed) == 0 {
+		allowed = DefaultAllowedCapabilities
+	}
+	normalized := make(map[string]struct{}, len(allowed))
+	for _, c := range allowed {
+		normalized[normalizeCapability(c)] = struct{}{}
+	}
+	return &hasNoUnexpectedFileCapabilitiesCheck{allowed: normalized}
+}
+
+// hasNoUnexpectedFileCapabilitiesCheck evaluates the file capabilities carried
+// by the image's executables in their security.capability extended attributes.
+// Capabilities granted this way are blocked by the restricted SCC when the
+// container runs on OpenShift, so every executable carrying them is reported,
+// and the check fails if any capability is not allowed.
+type hasNoUnexpectedFileCapabilitiesCheck struct {
+	allowed map[string]struct{}
+}
+
+// capabilityFile is an executable carrying file capabilities.
+type capabilityFile struct {
+	image.FileInfo
+	Capabilities image.FileCapabilities
+	// ParseErr is set instead of Capabilities if the file's
+	// security.capability attribute could not be decoded.
+	ParseErr error
+	// LayerDigest is the digest of the layer that introduced the file.
+	LayerDigest string
+}
+
+func (p *hasNoUnexpectedFileCapabilitiesCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	files, err := p.getDataToValidate(imgRef)
+	if err != nil {
+		return false, fmt.Errorf("could not get files with capabilities: %v", err)
+	}
+
+	return p.validate(ctx, files)
+}
+
+func (p *hasNoUnexpectedFileCapabilitiesCheck) getDataToValidate(imgRef image.ImageReference) ([]capabilityFile, error) {
+	if imgRef.Files == nil {
+		return nil, errors.New("the image's file metadata is not available")
+	}
+
+	layerDigests, err := getLayerDigests(imgRef.ImageInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []capabilityFile
+	err = imgRef.Files.Walk(func(info image.FileInfo) error {
+		raw := info.Capability()
+		if raw == nil {
+			return nil
+		}
+		caps, err := image.ParseFileCapabilities(raw)
+		file := capabilityFile{FileInfo: info, Capabilities: caps, ParseErr: err}
+		if info.Layer < len(layerDigests) {
+			file.LayerDigest = layerDigests[info.Layer]
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func (p *hasNoUnexpectedFileCapabilitiesCheck) validate(ctx context.Context, files []capabilityFile) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	passed := true
+	for _, file := range files {
+		if file.ParseErr != nil {
+			logger.V(log.DBG).Info("could not decode file capabilities", "path", file.Path, "reason", file.ParseErr.Error())
+			check.AddFinding(ctx, check.Finding{
+				Message:  fmt.Sprintf("%s has unparseable capabilities: %v", file.Path, file.ParseErr),
+				Severity: check.SeverityWarning,
+				Path:     file.Path,
+				Layer:    file.LayerDigest,
+			})
+			continue
+		}
+
+		var unexpected []string
+		for _, name := range file.Capabilities.Names() {
+			if _, ok := p.allowed[name]; !ok {
+				unexpected = append(unexpected, name)
+			}
+		}
+
+		finding := check.Finding{
+			Message:  fmt.Sprintf("%s has file capabilities %s", file.Path, file.Capabilities),
+			Severity: check.SeverityInfo,
+			Path:     file.Path,
+			Layer:    file.LayerDigest,
+		}
+		if len(unexpected) > 0 {
+			passed = false
+			finding.Message = fmt.Sprintf("%s has file capabilities %s, including %s which are not allowed", file.Path, file.Capabilities, strings.Join(unexpected, ","))
+			finding.Severity = check.SeverityError
+			finding.Remediation = fmt.Sprintf("Remove the capabilities with setcap -r %s, or request them through the pod's security context instead", file.Path)
+		}
+		logger.V(log.DBG).Info("found file with capabilities", "path", file.Path, "capabilities", file.Capabilities.String(), "unexpected", unexpected)
+		check.AddFinding(ctx, finding)
+	}
+
+	return passed, nil
+}
+
+// normalizeCapability returns name in the form used by getcap, e.g. both
+// CAP_NET_RAW and net_raw become cap_net_raw.
+func normalizeCapability(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !strings.HasPrefix(name, "cap_") {
+		name = "cap_" + name
+	}
+	return name
+}
+
+// Requirements implements check.RequirementsCheck.
+func (p *hasNoUnexpectedFileCapabilitiesCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
+func (p *hasNoUnexpectedFileCapabilitiesCheck) Name() string {
+	return "HasNoUnexpectedFileCapabilities"
+}
+
+func (p *hasNoUnexpectedFileCapabilitiesCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that executables in the image do not carry file capabilities other than those allowed, as they are blocked by the restricted SCC when running on OpenShift.",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *hasNoUnexpectedFileCapabilitiesCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasNoUnexpectedFileCapabilities encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Remove file capabilities from executables with setcap -r, and request the capabilities the container needs through its security context.",
+	}
+}
@@ -0,0 +1,131 @@
+package container
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+// capabilityXattr returns a revision 2 security.capability attribute granting
+// the capabilities numbered caps in the permitted and effective sets.
+func capabilityXattr(caps ...int) []byte {
+	var permitted uint64
+	for _, c := range caps {
+		permitted |= 1 << c
+	}
+	raw := binary.LittleEndian.AppendUint32(nil, 0x02000001)
+	raw = binary.LittleEndian.AppendUint32(raw, uint32(permitted))
+	raw = binary.LittleEndian.AppendUint32(raw, 0)
+	raw = binary.LittleEndian.AppendUint32(raw, uint32(permitted>>32))
+	return binary.LittleEndian.AppendUint32(raw, 0)
+}
+
+const (
+	capNetBindService = 10
+	capNetRaw         = 13
+)
+
+var _ = Describe("HasNoUnexpectedFileCapabilities", func() {
+	var imgRef image.ImageReference
+
+	BeforeEach(func() {
+		img, err := random.Image(256, 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		files := image.NewFileIndex()
+		files.Add(image.FileInfo{Path: "/usr/bin/ls", Mode: 0o755})
+		files.Add(image.FileInfo{Path: "/opt/app/server", Mode: 0o755, Layer: 1, Xattrs: map[string][]byte{
+			image.XattrCapability: capabilityXattr(capNetBindService),
+		}})
+		imgRef = image.ImageReference{ImageInfo: img, Files: files}
+	})
+
+	Context("When executables only carry allowed capabilities", func() {
+		It("should pass Validate and report the executables", func() {
+			findings := &check.Findings{}
+			ctx := check.ContextWithFindings(context.TODO(), findings)
+			ok, err := NewHasNoUnexpectedFileCapabilitiesCheck(nil).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(HaveLen(1))
+			Expect(findings.List()[0].Severity).To(Equal(check.SeverityInfo))
+			Expect(findings.List()[0].Message).To(Equal("/opt/app/server has file capabilities cap_net_bind_service=ep"))
+		})
+	})
+
+	Context("When an executable carries a capability that is not allowed", func() {
+		BeforeEach(func() {
+			imgRef.Files.Add(image.FileInfo{Path: "/usr/bin/ping", Mode: 0o755, Xattrs: map[string][]byte{
+				image.XattrCapability: capabilityXattr(capNetRaw),
+			}})
+		})
+
+		It("should not pass Validate", func() {
+			findings := &check.Findings{}
+			ctx := check.ContextWithFindings(context.TODO(), findings)
+			ok, err := NewHasNoUnexpectedFileCapabilitiesCheck(nil).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			layers, err := imgRef.ImageInfo.Layers()
+			Expect(err).ToNot(HaveOccurred())
+			baseLayer, err := layers[0].Digest()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(findings.List()).To(HaveLen(2))
+			Expect(findings.List()[1]).To(Equal(check.Finding{
+				Message:     "/usr/bin/ping has file capabilities cap_net_raw=ep, including cap_net_raw which are not allowed",
+				Severity:    check.SeverityError,
+				Path:        "/usr/bin/ping",
+				Layer:       baseLayer.String(),
+				Remediation: "Remove the capabilities with setcap -r /usr/bin/ping, or request them through the pod's security context instead",
+			}))
+		})
+
+		It("should pass Validate if the capability is allowed", func() {
+			ok, err := NewHasNoUnexpectedFileCapabilitiesCheck([]string{"CAP_NET_RAW", "net_bind_service"}).Validate(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When a capability attribute cannot be decoded", func() {
+		BeforeEach(func() {
+			imgRef.Files.Add(image.FileInfo{Path: "/usr/bin/bad", Xattrs: map[string][]byte{image.XattrCapability: {0x01}}})
+			imgRef.Files.Add(image.FileInfo{Path: "/usr/bin/ping", Mode: 0o755, Xattrs: map[string][]byte{
+				image.XattrCapability: capabilityXattr(capNetRaw),
+			}})
+		})
+
+		It("should report the file and keep auditing the others", func() {
+			findings := &check.Findings{}
+			ctx := check.ContextWithFindings(context.TODO(), findings)
+			ok, err := NewHasNoUnexpectedFileCapabilitiesCheck(nil).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			var bad []check.Finding
+			for _, f := range findings.List() {
+				if f.Path == "/usr/bin/bad" {
+					bad = append(bad, f)
+				}
+			}
+			Expect(bad).To(HaveLen(1))
+			Expect(bad[0].Severity).To(Equal(check.SeverityWarning))
+			Expect(bad[0].Message).To(HavePrefix("/usr/bin/bad has unparseable capabilities: "))
+			Expect(findings.List()).To(ContainElement(HaveField("Path", "/usr/bin/ping")))
+		})
+
+		It("should pass Validate if the other capabilities are allowed", func() {
+			ok, err := NewHasNoUnexpectedFileCapabilitiesCheck([]string{"cap_net_raw", "cap_net_bind_service"}).Validate(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
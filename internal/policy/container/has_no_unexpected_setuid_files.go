@@ -0,0 +1,201 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+var (
+	_ check.Check             = &HasNoUnexpectedSetuidFilesCheck{}
+	_ check.RequirementsCheck = &HasNoUnexpectedSetuidFilesCheck{}
+)
+
+// HasNoUnexpectedSetuidFilesCheck evaluates that the image contains no setuid
+// or setgid executables, other than those shipped by RHEL packages that are
+// expected to carry them. The bits are lost when the image is extracted, so
+// they are read from the layer tar headers instead.
+type HasNoUnexpectedSetuidFilesCheck struct{}
+
+// setuidFile is a setuid or setgid file found in the image.
+type setuidFile struct {
+	image.FileInfo
+	// LayerDigest is the digest of the layer that introduced the file.
+	LayerDigest string
+}
+
+func (p *HasNoUnexpectedSetuidFilesCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	files, err := p.getDataToValidate(imgRef)
+	if err != nil {
+		return false, fmt.Errorf("could not get setuid and setgid files: %v", err)
+	}
+
+	return p.validate(ctx, files)
+}
+
+func (p *HasNoUnexpectedSetuidFilesCheck) getDataToValidate(imgRef image.ImageReference) ([]setuidFile, error) {
+	if imgRef.Files == nil {
+		return nil, errors.New("the image's file metadata is not available")
+	}
+
+	layerDigests, err := getLayerDigests(imgRef.ImageInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []setuidFile
+	err = imgRef.Files.Walk(func(info image.FileInfo) error {
+		if !info.Mode.IsRegular() || info.Mode&(fs.ModeSetuid|fs.ModeSetgid) == 0 {
+			return nil
+		}
+		file := setuidFile{FileInfo: info}
+		if info.Layer < len(layerDigests) {
+			file.LayerDigest = layerDigests[info.Layer]
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func (p *HasNoUnexpectedSetuidFilesCheck) validate(ctx context.Context, files []setuidFile) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	var unexpected []string
+	for _, file := range files {
+		if _, ok := setuidAllowList[file.Path]; ok {
+			logger.V(log.DBG).Info("found expected setuid or setgid file", "path", file.Path)
+			continue
+		}
+		unexpected = append(unexpected, file.Path)
+		check.AddFinding(ctx, check.Finding{
+			Message:     fmt.Sprintf("%s %s has mode %04o and was introduced by layer %d", setuidKind(file.Mode), file.Path, unixMode(file.Mode), file.Layer),
+			Severity:    check.SeverityError,
+			Path:        file.Path,
+			Layer:       file.LayerDigest,
+			Remediation: fmt.Sprintf("Remove the setuid and setgid bits, e.g. with chmod ug-s %s", file.Path),
+		})
+	}
+
+	if len(unexpected) > 0 {
+		logger.V(log.DBG).Info("unexpected setuid or setgid files found", "fileCount", len(unexpected), "fileList", unexpected)
+	}
+
+	return len(unexpected) == 0, nil
+}
+
+// getLayerDigests returns the digests of the layers of img, from the base
+// layer up, so that they can be looked up by image.FileInfo.Layer.
+func getLayerDigests(img v1.Image) ([]string, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("could not get image layers: %w", err)
+	}
+	digests := make([]string, 0, len(layers))
+	for _, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			return nil, fmt.Errorf("could not get layer digest: %w", err)
+		}
+		digests = append(digests, digest.String())
+	}
+	return digests, nil
+}
+
+// setuidKind describes which of the setuid and setgid bits are set in mode.
+func setuidKind(mode fs.FileMode) string {
+	switch {
+	case mode&fs.ModeSetuid != 0 && mode&fs.ModeSetgid != 0:
+		return "setuid and setgid file"
+	case mode&fs.ModeSetuid != 0:
+		return "setuid file"
+	default:
+		return "setgid file"
+	}
+}
+
+// unixMode returns the permission bits of mode as they would be shown by
+// stat, e.g. 4755 for a setuid executable.
+func unixMode(mode fs.FileMode) uint32 {
+	m := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		m |= 0o4000
+	}
+	if mode&fs.ModeSetgid != 0 {
+		m |= 0o2000
+	}
+	if mode&fs.ModeSticky != 0 {
+		m |= 0o1000
+	}
+	return m
+}
+
+// Requirements implements check.RequirementsCheck.
+func (p *HasNoUnexpectedSetuidFilesCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
+func (p *HasNoUnexpectedSetuidFilesCheck) Name() string {
+	return "HasNoUnexpectedSetuidFiles"
+}
+
+func (p *HasNoUnexpectedSetuidFilesCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the image does not contain setuid or setgid executables other than those expected in Red Hat Enterprise Linux (RHEL).",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *HasNoUnexpectedSetuidFilesCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasNoUnexpectedSetuidFiles encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Remove the setuid and setgid bits from executables the container does not need to run with elevated privileges, e.g. with chmod ug-s.",
+	}
+}
+
+// setuidAllowList contains the setuid and setgid executables shipped by RHEL
+// packages, which are expected to carry these bits.
+var setuidAllowList = map[string]struct{}{
+	"/usr/bin/at":                             {},
+	"/usr/bin/chage":                          {},
+	"/usr/bin/chfn":                           {},
+	"/usr/bin/chsh":                           {},
+	"/usr/bin/crontab":                        {},
+	"/usr/bin/fusermount":                     {},
+	"/usr/bin/fusermount3":                    {},
+	"/usr/bin/gpasswd":                        {},
+	"/usr/bin/mount":                          {},
+	"/usr/bin/newgidmap":                      {},
+	"/usr/bin/newgrp":                         {},
+	"/usr/bin/newuidmap":                      {},
+	"/usr/bin/passwd":                         {},
+	"/usr/bin/pkexec":                         {},
+	"/usr/bin/ssh-agent":                      {},
+	"/usr/bin/su":                             {},
+	"/usr/bin/sudo":                           {},
+	"/usr/bin/umount":                         {},
+	"/usr/bin/write":                          {},
+	"/usr/lib/polkit-1/polkit-agent-helper-1": {},
+	"/usr/libexec/dbus-1/dbus-daemon-launch-helper": {},
+	"/usr/libexec/openssh/ssh-keysign":              {},
+	"/usr/libexec/utempter/utempter":                {},
+	"/usr/sbin/grub2-set-bootflag":                  {},
+	"/usr/sbin/mount.nfs":                           {},
+	"/usr/sbin/pam_timestamp_check":                 {},
+	"/usr/sbin/unix_chkpwd":                         {},
+	"/usr/sbin/userhelper":                          {},
+}
@@ -0,0 +1,77 @@
+package container
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("HasNoUnexpectedSetuidFiles", func() {
+	var (
+		setuidCheck HasNoUnexpectedSetuidFilesCheck
+		imgRef      image.ImageReference
+	)
+
+	BeforeEach(func() {
+		img, err := random.Image(256, 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		files := image.NewFileIndex()
+		files.Add(image.FileInfo{Path: "/usr/bin/passwd", Mode: 0o755 | fs.ModeSetuid, Layer: 0})
+		files.Add(image.FileInfo{Path: "/usr/bin/ls", Mode: 0o755, Layer: 0})
+		files.Add(image.FileInfo{Path: "/var/lib/shared", Mode: fs.ModeDir | 0o775 | fs.ModeSetgid, Layer: 1})
+		imgRef = image.ImageReference{ImageInfo: img, Files: files}
+	})
+
+	Context("When the image only contains expected setuid files", func() {
+		It("should pass Validate", func() {
+			ok, err := setuidCheck.Validate(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When the image contains unexpected setuid and setgid files", func() {
+		BeforeEach(func() {
+			imgRef.Files.Add(image.FileInfo{Path: "/opt/app/helper", Mode: 0o750 | fs.ModeSetuid | fs.ModeSetgid, Layer: 1})
+			imgRef.Files.Add(image.FileInfo{Path: "/usr/local/bin/tool", Mode: 0o711 | fs.ModeSetgid, Layer: 0})
+		})
+
+		It("should not pass Validate and report each file with its mode and layer", func() {
+			findings := &check.Findings{}
+			ctx := check.ContextWithFindings(context.TODO(), findings)
+			ok, err := setuidCheck.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			layers, err := imgRef.ImageInfo.Layers()
+			Expect(err).ToNot(HaveOccurred())
+			topLayer, err := layers[1].Digest()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(findings.List()).To(HaveLen(2))
+			Expect(findings.List()[0]).To(Equal(check.Finding{
+				Message:     "setuid and setgid file /opt/app/helper has mode 6750 and was introduced by layer 1",
+				Severity:    check.SeverityError,
+				Path:        "/opt/app/helper",
+				Layer:       topLayer.String(),
+				Remediation: "Remove the setuid and setgid bits, e.g. with chmod ug-s /opt/app/helper",
+			}))
+			Expect(findings.List()[1].Message).To(Equal("setgid file /usr/local/bin/tool has mode 2711 and was introduced by layer 0"))
+		})
+	})
+
+	Context("When the file metadata is not available", func() {
+		It("should return an error", func() {
+			imgRef.Files = nil
+			_, err := setuidCheck.Validate(context.TODO(), imgRef)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -8,19 +8,22 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
-	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
 
 	"github.com/go-logr/logr"
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
 )
 
-var _ check.Check = &HasNoProhibitedPackagesCheck{}
+var (
+	_ check.Check             = &HasNoProhibitedPackagesCheck{}
+	_ check.RequirementsCheck = &HasNoProhibitedPackagesCheck{}
+)
 
 // HasProhibitedPackages evaluates that the image does not contain prohibited packages,
 // which refers to packages that are not redistributable without an appropriate license.
 type HasNoProhibitedPackagesCheck struct{}
 
 func (p *HasNoProhibitedPackagesCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
-	pkgList, err := p.getDataToValidate(ctx, imgRef.ImageFSPath)
+	pkgList, err := p.getDataToValidate(ctx, imgRef.ImageFacts())
 	if err != nil {
 		return false, fmt.Errorf("unable to get a list of all packages in the image: %v", err)
 	}
@@ -28,35 +31,30 @@ func (p *HasNoProhibitedPackagesCheck) Validate(ctx context.Context, imgRef imag
 	return p.validate(ctx, pkgList)
 }
 
-func (p *HasNoProhibitedPackagesCheck) getDataToValidate(ctx context.Context, dir string) ([]string, error) {
-	pkgList, err := rpm.GetPackageList(ctx, dir)
+func (p *HasNoProhibitedPackagesCheck) getDataToValidate(ctx context.Context, facts *image.Facts) ([]*rpmdb.PackageInfo, error) {
+	pkgList, err := facts.Packages(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("could not get rpm list: %w", err)
 	}
-	pkgs := make([]string, 0, len(pkgList))
-	for _, pkg := range pkgList {
-		pkgs = append(pkgs, pkg.Name)
-	}
-	return pkgs, nil
+
+	return pkgList, nil
 }
 
-//nolint:unparam // ctx is unused. Keep for future use.
-func (p *HasNoProhibitedPackagesCheck) validate(ctx context.Context, pkgList []string) (bool, error) {
+func (p *HasNoProhibitedPackagesCheck) validate(ctx context.Context, pkgList []*rpmdb.PackageInfo) (bool, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 
 	var prohibitedPackages []string
 	for _, pkg := range pkgList {
-		_, ok := prohibitedPackageList[pkg]
-		if ok {
-			prohibitedPackages = append(prohibitedPackages, pkg)
+		if !isProhibitedPackage(pkg.Name) {
 			continue
 		}
-		for _, name := range prohibitedPackageGlobList {
-			if strings.HasPrefix(pkg, name) {
-				prohibitedPackages = append(prohibitedPackages, pkg)
-				continue
-			}
-		}
+		prohibitedPackages = append(prohibitedPackages, pkg.Name)
+		check.AddFinding(ctx, check.Finding{
+			Message:     fmt.Sprintf("prohibited package %s is installed", pkg.Name),
+			Severity:    check.SeverityError,
+			Package:     nvra(pkg),
+			Remediation: fmt.Sprintf("Remove %s from the image, e.g. with dnf remove %s", pkg.Name, pkg.Name),
+		})
 	}
 
 	if len(prohibitedPackages) > 0 {
@@ -66,6 +64,30 @@ func (p *HasNoProhibitedPackagesCheck) validate(ctx context.Context, pkgList []s
 	return len(prohibitedPackages) == 0, nil
 }
 
+// isProhibitedPackage returns true if the package called name cannot be
+// redistributed.
+func isProhibitedPackage(name string) bool {
+	if _, ok := prohibitedPackageList[name]; ok {
+		return true
+	}
+	for _, prefix := range prohibitedPackageGlobList {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// nvra returns the name-version-release.arch of pkg, e.g. kernel-5.14.0-70.el9.x86_64.
+func nvra(pkg *rpmdb.PackageInfo) string {
+	return fmt.Sprintf("%s-%s-%s.%s", pkg.Name, pkg.Version, pkg.Release, pkg.Arch)
+}
+
+// Requirements implements check.RequirementsCheck.
+func (p *HasNoProhibitedPackagesCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
 func (p *HasNoProhibitedPackagesCheck) Name() string {
 	return "HasNoProhibitedPackages"
 }
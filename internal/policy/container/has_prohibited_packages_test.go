@@ -3,6 +3,9 @@ package container
 import (
 	"context"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -10,16 +13,24 @@ import (
 var _ = Describe("HasNoProhibitedPackages", func() {
 	var (
 		hasNoProhibitedPackages HasNoProhibitedPackagesCheck
-		pkgList                 []string
+		pkgList                 []*rpmdb.PackageInfo
 	)
 
+	packages := func(names ...string) []*rpmdb.PackageInfo {
+		pkgs := make([]*rpmdb.PackageInfo, 0, len(names))
+		for _, name := range names {
+			pkgs = append(pkgs, &rpmdb.PackageInfo{Name: name, Version: "1.0", Release: "1.el9", Arch: "x86_64"})
+		}
+		return pkgs
+	}
+
 	BeforeEach(func() {
-		pkgList = []string{
+		pkgList = packages(
 			"this",
 			"is",
 			"not",
 			"prohibited",
-		}
+		)
 	})
 
 	AssertMetaData(&hasNoProhibitedPackages)
@@ -33,20 +44,28 @@ var _ = Describe("HasNoProhibitedPackages", func() {
 			})
 		})
 		Context("When there was a prohibited packages found", func() {
-			var pkgs []string
+			var pkgs []*rpmdb.PackageInfo
 			BeforeEach(func() {
-				pkgs = append(pkgList, "grub")
+				pkgs = append(pkgList, packages("grub")...)
 			})
 			It("should not pass Validate", func() {
 				ok, err := hasNoProhibitedPackages.validate(context.TODO(), pkgs)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ok).To(BeFalse())
 			})
+			It("should report the package as a finding", func() {
+				findings := &check.Findings{}
+				_, err := hasNoProhibitedPackages.validate(check.ContextWithFindings(context.TODO(), findings), pkgs)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(findings.List()).To(HaveLen(1))
+				Expect(findings.List()[0].Package).To(Equal("grub-1.0-1.el9.x86_64"))
+				Expect(findings.List()[0].Severity).To(Equal(check.SeverityError))
+			})
 		})
 		Context("When there is a prohibited package in the glob list found", func() {
-			var pkgs []string
+			var pkgs []*rpmdb.PackageInfo
 			BeforeEach(func() {
-				pkgs = append(pkgList, "kpatch2121")
+				pkgs = append(pkgList, packages("kpatch2121")...)
 			})
 			It("should not pass Validate", func() {
 				ok, err := hasNoProhibitedPackages.validate(context.TODO(), pkgs)
@@ -9,19 +9,21 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 
 	"github.com/go-logr/logr"
-	cranev1 "github.com/google/go-containerregistry/pkg/v1"
 )
 
 var requiredLabels = []string{"name", "vendor", "version", "release", "summary", "description"}
 
-var _ check.Check = &HasRequiredLabelsCheck{}
+var (
+	_ check.Check             = &HasRequiredLabelsCheck{}
+	_ check.RequirementsCheck = &HasRequiredLabelsCheck{}
+)
 
 // HasRequiredLabelsCheck evaluates the image manifest to ensure that the appropriate metadata
 // labels are present on the image asset as it exists in its current container registry.
 type HasRequiredLabelsCheck struct{}
 
 func (p *HasRequiredLabelsCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
-	labels, err := p.getDataForValidate(imgRef.ImageInfo)
+	labels, err := p.getDataForValidate(imgRef.ImageFacts())
 	if err != nil {
 		return false, fmt.Errorf("could not retrieve image labels: %v", err)
 	}
@@ -29,9 +31,12 @@ func (p *HasRequiredLabelsCheck) Validate(ctx context.Context, imgRef image.Imag
 	return p.validate(ctx, labels)
 }
 
-func (p *HasRequiredLabelsCheck) getDataForValidate(image cranev1.Image) (map[string]string, error) {
-	configFile, err := image.ConfigFile()
-	return configFile.Config.Labels, err
+func (p *HasRequiredLabelsCheck) getDataForValidate(facts *image.Facts) (map[string]string, error) {
+	configFile, err := facts.Config()
+	if err != nil {
+		return nil, err
+	}
+	return configFile.Config.Labels, nil
 }
 
 func (p *HasRequiredLabelsCheck) validate(ctx context.Context, labels map[string]string) (bool, error) {
@@ -44,14 +49,25 @@ func (p *HasRequiredLabelsCheck) validate(ctx context.Context, labels map[string
 		}
 	}
 
-	// TODO: We should be reporting this in the results, not in a log message
 	if len(missingLabels) > 0 {
 		logger.V(log.DBG).Info("expected labels are missing", "missingLabels", missingLabels)
 	}
+	for _, label := range missingLabels {
+		check.AddFinding(ctx, check.Finding{
+			Message:     fmt.Sprintf("required label %q is missing or empty", label),
+			Severity:    check.SeverityError,
+			Remediation: fmt.Sprintf("Add LABEL %s=\"<value>\" to your Containerfile", label),
+		})
+	}
 
 	return len(missingLabels) == 0, nil
 }
 
+// Requirements implements check.RequirementsCheck.
+func (p *HasRequiredLabelsCheck) Requirements() check.Requirement {
+	return check.RequiresConfig
+}
+
 func (p *HasRequiredLabelsCheck) Name() string {
 	return "HasRequiredLabel"
 }
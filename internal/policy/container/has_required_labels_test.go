@@ -8,6 +8,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 )
 
@@ -77,6 +78,13 @@ var _ = Describe("HasRequiredLabels", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(ok).To(BeFalse())
 			})
+			It("should report the missing label as a finding", func() {
+				findings := &check.Findings{}
+				_, err := hasRequiredLabelsCheck.Validate(check.ContextWithFindings(context.TODO(), findings), imageRef)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(findings.List()).To(HaveLen(1))
+				Expect(findings.List()[0].Message).To(ContainSubstring(`"description"`))
+			})
 		})
 	})
 
@@ -0,0 +1,252 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"debug/elf"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+
+	"github.com/go-logr/logr"
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+var (
+	_ check.Check             = &HasResolvableEntrypointCheck{}
+	_ check.RequirementsCheck = &HasResolvableEntrypointCheck{}
+)
+
+// defaultPath is used to find commands if the image does not set PATH.
+const defaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// maxShebangLength is the longest interpreter line read from a script.
+const maxShebangLength = 256
+
+// elfMachines maps container architectures to the ELF machine of their binaries.
+var elfMachines = map[string]elf.Machine{
+	"amd64":   elf.EM_X86_64,
+	"arm64":   elf.EM_AARCH64,
+	"ppc64le": elf.EM_PPC64,
+	"s390x":   elf.EM_S390,
+	"386":     elf.EM_386,
+	"arm":     elf.EM_ARM,
+}
+
+// HasResolvableEntrypointCheck evaluates that the executable the container
+// starts with exists in the image and can be run. The first element of the
+// Entrypoint, or of the Cmd if there is no Entrypoint, is looked up along
+// the PATH of the image's environment, following symbolic links. It must
+// be executable, a script's interpreter must itself resolve, and an ELF
+// binary must be built for the image's architecture.
+type HasResolvableEntrypointCheck struct{}
+
+// entrypointData holds what is needed to evaluate the image.
+type entrypointData struct {
+	Config       *cranev1.Config
+	Architecture string
+	Files        *image.FileIndex
+	// FSPath is the directory the image's filesystem was extracted to.
+	FSPath string
+}
+
+func (p *HasResolvableEntrypointCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	data, err := p.getDataToValidate(imgRef)
+	if err != nil {
+		return false, fmt.Errorf("could not get validation data: %v", err)
+	}
+
+	if len(data.Config.Entrypoint) == 0 && len(data.Config.Cmd) == 0 {
+		return false, check.MissingPrerequisite("the image has no entrypoint or command")
+	}
+
+	return p.validate(ctx, data)
+}
+
+func (p *HasResolvableEntrypointCheck) getDataToValidate(imgRef image.ImageReference) (entrypointData, error) {
+	if imgRef.Files == nil {
+		return entrypointData{}, errors.New("the image's file metadata is not available")
+	}
+
+	configFile, err := imgRef.ImageFacts().Config()
+	if err != nil {
+		return entrypointData{}, fmt.Errorf("could not retrieve ConfigFile from Image: %w", err)
+	}
+
+	return entrypointData{
+		Config:       &configFile.Config,
+		Architecture: configFile.Architecture,
+		Files:        imgRef.Files,
+		FSPath:       imgRef.ImageFSPath,
+	}, nil
+}
+
+func (p *HasResolvableEntrypointCheck) validate(ctx context.Context, data entrypointData) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	source, command := "Entrypoint", data.Config.Entrypoint
+	if len(command) == 0 {
+		source, command = "Cmd", data.Config.Cmd
+	}
+
+	fail := func(message, remediation, filePath string) (bool, error) {
+		check.AddFinding(ctx, check.Finding{
+			Message:     message,
+			Severity:    check.SeverityError,
+			Path:        filePath,
+			Remediation: remediation,
+		})
+		return false, nil
+	}
+
+	info, err := p.resolveCommand(data, command[0])
+	if err != nil {
+		return fail(fmt.Sprintf("%s %s cannot be found in the image: %v", source, command[0], err),
+			fmt.Sprintf("Install %s in the image, or change the %s to a command that exists", command[0], source), "")
+	}
+	logger.V(log.DBG).Info("resolved command", "command", command[0], "path", info.Path)
+
+	if info.Mode.IsDir() || info.Mode&0o111 == 0 {
+		return fail(fmt.Sprintf("%s %s resolves to %s, which is not executable (mode %04o)", source, command[0], info.Path, unixMode(info.Mode)),
+			fmt.Sprintf("Make %s executable, e.g. with chmod +x %s", info.Path, info.Path), info.Path)
+	}
+
+	header, err := readHeader(data.FSPath, info.Path)
+	if err != nil {
+		return false, fmt.Errorf("could not read %s: %v", info.Path, err)
+	}
+
+	switch {
+	case bytes.HasPrefix(header, []byte("#!")):
+		interpreter, args := parseShebang(header)
+		if interpreter == "" {
+			return fail(fmt.Sprintf("%s %s is a script with an empty interpreter line", source, info.Path),
+				fmt.Sprintf("Add an interpreter to the first line of %s, e.g. #!/bin/sh", info.Path), info.Path)
+		}
+		interpreterInfo, err := data.Files.Resolve(interpreter)
+		if err == nil && path.Base(interpreter) == "env" {
+			// #!/usr/bin/env looks the actual interpreter up along the PATH,
+			// after any options such as -S.
+			for _, arg := range args {
+				if !strings.HasPrefix(arg, "-") {
+					interpreter = arg
+					interpreterInfo, err = p.resolveCommand(data, interpreter)
+					break
+				}
+			}
+		}
+		if err != nil || interpreterInfo.Mode&0o111 == 0 {
+			return fail(fmt.Sprintf("%s %s is a script whose interpreter %s cannot be found in the image", source, info.Path, interpreter),
+				fmt.Sprintf("Install %s in the image, or change the first line of %s", interpreter, info.Path), info.Path)
+		}
+	case bytes.HasPrefix(header, []byte(elf.ELFMAG)):
+		machine, err := elfMachine(data.FSPath, info.Path)
+		if err != nil {
+			return false, fmt.Errorf("could not read ELF header of %s: %v", info.Path, err)
+		}
+		if expected, ok := elfMachines[data.Architecture]; ok && machine != expected {
+			return fail(fmt.Sprintf("%s %s is built for %s, but the image architecture is %s", source, info.Path, machine, data.Architecture),
+				fmt.Sprintf("Build %s for %s", info.Path, data.Architecture), info.Path)
+		}
+	}
+
+	return true, nil
+}
+
+// resolveCommand finds command the way execvp would in the container. A
+// command containing a slash is resolved relative to the working directory,
+// while others are looked up along the PATH.
+func (p *HasResolvableEntrypointCheck) resolveCommand(data entrypointData, command string) (image.FileInfo, error) {
+	if strings.Contains(command, "/") {
+		if !path.IsAbs(command) {
+			command = path.Join("/", data.Config.WorkingDir, command)
+		}
+		return data.Files.Resolve(command)
+	}
+
+	searchPath := envValue(data.Config.Env, "PATH")
+	if searchPath == "" {
+		searchPath = defaultPath
+	}
+	for _, dir := range strings.Split(searchPath, ":") {
+		if dir == "" {
+			continue
+		}
+		info, err := data.Files.Resolve(path.Join(dir, command))
+		if err == nil && !info.Mode.IsDir() {
+			return info, nil
+		}
+	}
+	return image.FileInfo{}, fmt.Errorf("not found in PATH %s", searchPath)
+}
+
+// readHeader returns the start of the file at p in the image, enough to
+// recognize a script or an ELF binary.
+func readHeader(fsPath, p string) ([]byte, error) {
+	f, err := os.Open(filepath.Join(fsPath, filepath.FromSlash(p)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, maxShebangLength)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return header[:n], nil
+}
+
+// parseShebang returns the interpreter and its arguments from the first line
+// of a script starting with #!.
+func parseShebang(header []byte) (string, []string) {
+	line, _, _ := bytes.Cut(header[2:], []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return fields[0], fields[1:]
+}
+
+// elfMachine returns the machine the ELF binary at p in the image is built for.
+func elfMachine(fsPath, p string) (elf.Machine, error) {
+	f, err := elf.Open(filepath.Join(fsPath, filepath.FromSlash(p)))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return f.Machine, nil
+}
+
+// Requirements implements check.RequirementsCheck.
+func (p *HasResolvableEntrypointCheck) Requirements() check.Requirement {
+	return check.RequiresConfig | check.RequiresFilesystem
+}
+
+func (p *HasResolvableEntrypointCheck) Name() string {
+	return "HasResolvableEntrypoint"
+}
+
+func (p *HasResolvableEntrypointCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the image's entrypoint or command exists in the image, is executable, and is built for the image's architecture.",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *HasResolvableEntrypointCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasResolvableEntrypoint encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Make sure the Entrypoint or Cmd of the image refers to an executable installed in the image, that scripts start with an interpreter installed in the image, and that binaries are built for the image's architecture.",
+	}
+}
@@ -0,0 +1,144 @@
+package container
+
+import (
+	"context"
+	"debug/elf"
+	"encoding/binary"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	fakecranev1 "github.com/google/go-containerregistry/pkg/v1/fake"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+// elfHeader returns the header of a little endian, 64-bit ELF executable for
+// machine, without program or section headers.
+func elfHeader(machine elf.Machine) []byte {
+	header := make([]byte, 64)
+	copy(header, elf.ELFMAG)
+	header[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	binary.LittleEndian.PutUint16(header[16:], uint16(elf.ET_EXEC))
+	binary.LittleEndian.PutUint16(header[18:], uint16(machine))
+	binary.LittleEndian.PutUint32(header[20:], uint32(elf.EV_CURRENT))
+	binary.LittleEndian.PutUint16(header[52:], 64)
+	return header
+}
+
+var _ = Describe("HasResolvableEntrypoint", func() {
+	var (
+		entrypointCheck HasResolvableEntrypointCheck
+		imgRef          image.ImageReference
+		configFile      cranev1.ConfigFile
+		findings        *check.Findings
+		ctx             context.Context
+	)
+
+	addFile := func(name string, mode fs.FileMode, contents []byte) {
+		p := filepath.Join(imgRef.ImageFSPath, name)
+		Expect(os.MkdirAll(filepath.Dir(p), 0o755)).To(Succeed())
+		Expect(os.WriteFile(p, contents, 0o644)).To(Succeed())
+		imgRef.Files.Add(image.FileInfo{Path: name, Mode: mode})
+	}
+
+	BeforeEach(func() {
+		configFile = cranev1.ConfigFile{
+			Architecture: "amd64",
+			Config: cranev1.Config{
+				Entrypoint: []string{"app"},
+				Env:        []string{"PATH=/opt/app/bin:/usr/bin"},
+				WorkingDir: "/opt/app",
+			},
+		}
+		imgRef = image.ImageReference{
+			ImageFSPath: GinkgoT().TempDir(),
+			Files:       image.NewFileIndex(),
+			ImageInfo: &fakecranev1.FakeImage{
+				ConfigFileStub: func() (*cranev1.ConfigFile, error) {
+					return &configFile, nil
+				},
+			},
+		}
+		imgRef.Files.Add(image.FileInfo{Path: "/bin", Mode: fs.ModeSymlink | 0o777, Linkname: "usr/bin"})
+		addFile("/usr/bin/sh", 0o755, elfHeader(elf.EM_X86_64))
+		addFile("/usr/bin/env", 0o755, elfHeader(elf.EM_X86_64))
+		addFile("/opt/app/bin/app", 0o755, elfHeader(elf.EM_X86_64))
+
+		findings = &check.Findings{}
+		ctx = check.ContextWithFindings(context.TODO(), findings)
+	})
+
+	Context("When the entrypoint resolves to a binary for the image architecture", func() {
+		It("should pass Validate", func() {
+			ok, err := entrypointCheck.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When the command is a script", func() {
+		BeforeEach(func() {
+			configFile.Config.Entrypoint = nil
+			configFile.Config.Cmd = []string{"./start.sh"}
+		})
+
+		It("should pass Validate if its interpreter exists through a symbolic link", func() {
+			addFile("/opt/app/start.sh", 0o755, []byte("#!/bin/sh\nexec app\n"))
+			ok, err := entrypointCheck.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should look up the interpreter along the PATH when using env", func() {
+			addFile("/opt/app/start.sh", 0o755, []byte("#!/usr/bin/env -S python3 -u\n"))
+			ok, err := entrypointCheck.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()[0].Message).To(Equal("Cmd /opt/app/start.sh is a script whose interpreter python3 cannot be found in the image"))
+		})
+
+		It("should not pass Validate if it is not executable", func() {
+			addFile("/opt/app/start.sh", 0o644, []byte("#!/bin/sh\n"))
+			ok, err := entrypointCheck.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()[0].Message).To(Equal("Cmd ./start.sh resolves to /opt/app/start.sh, which is not executable (mode 0644)"))
+		})
+	})
+
+	Context("When the entrypoint does not exist", func() {
+		It("should not pass Validate", func() {
+			configFile.Config.Entrypoint = []string{"missing"}
+			ok, err := entrypointCheck.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()[0].Message).To(Equal("Entrypoint missing cannot be found in the image: not found in PATH /opt/app/bin:/usr/bin"))
+		})
+	})
+
+	Context("When the entrypoint is built for another architecture", func() {
+		It("should not pass Validate", func() {
+			addFile("/opt/app/bin/app", 0o755, elfHeader(elf.EM_AARCH64))
+			ok, err := entrypointCheck.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()[0].Message).To(Equal("Entrypoint /opt/app/bin/app is built for EM_AARCH64, but the image architecture is amd64"))
+		})
+	})
+
+	Context("When the image has no entrypoint or command", func() {
+		It("should not apply", func() {
+			configFile.Config.Entrypoint = nil
+			_, err := entrypointCheck.Validate(ctx, imgRef)
+			_, ok := check.IsMissingPrerequisite(err)
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
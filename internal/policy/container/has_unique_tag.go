@@ -12,7 +12,10 @@ import (
 	"github.com/google/go-containerregistry/pkg/crane"
 )
 
-var _ check.Check = &hasUniqueTagCheck{}
+var (
+	_ check.Check             = &hasUniqueTagCheck{}
+	_ check.RequirementsCheck = &hasUniqueTagCheck{}
+)
 
 func NewHasUniqueTagCheck(dockercfg string) *hasUniqueTagCheck {
 	return &hasUniqueTagCheck{
@@ -28,6 +31,12 @@ type hasUniqueTagCheck struct {
 }
 
 func (p *hasUniqueTagCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	// Tags can only be listed from a registry. Images read from the local
+	// filesystem have nothing to query, so this check does not apply.
+	if imgRef.Local {
+		return false, check.MissingPrerequisite("tags can only be verified for images in a registry")
+	}
+
 	imgRepo := fmt.Sprintf("%s/%s", imgRef.ImageRegistry, imgRef.ImageRepository)
 
 	tags := make([]string, 0)
@@ -51,7 +60,15 @@ func (p *hasUniqueTagCheck) Validate(ctx context.Context, imgRef image.ImageRefe
 		tags = append(tags, imgRef.ImageTagOrSha)
 	}
 
-	return p.validate(tags)
+	passed, err := p.validate(tags)
+	if err == nil && !passed {
+		check.AddFinding(ctx, check.Finding{
+			Message:     fmt.Sprintf("%s is only tagged as latest", imgRepo),
+			Severity:    check.SeverityError,
+			Remediation: "Tag the image with a unique tag, such as its version, in addition to latest",
+		})
+	}
+	return passed, err
 }
 
 func (p *hasUniqueTagCheck) getDataToValidate(ctx context.Context, image string) ([]string, error) {
@@ -71,6 +88,12 @@ func (p *hasUniqueTagCheck) validate(tags []string) (bool, error) {
 	return len(tags) > 1 || len(tags) == 1 && strings.ToLower(tags[0]) != "latest", nil
 }
 
+// Requirements implements check.RequirementsCheck. The check queries the
+// registry for the repository's tags.
+func (p *hasUniqueTagCheck) Requirements() check.Requirement {
+	return check.RequiresManifest
+}
+
 func (p *hasUniqueTagCheck) Name() string {
 	return "HasUniqueTag"
 }
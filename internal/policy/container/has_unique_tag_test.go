@@ -17,6 +17,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 )
 
@@ -120,6 +121,13 @@ var _ = Describe("UniqueTag", func() {
 				Expect(ok).To(BeFalse())
 			})
 		})
+		Context("When the image was read from a local source", func() {
+			It("should be skipped without contacting a registry", func() {
+				_, err := hasUniqueTagCheck.Validate(context.TODO(), image.ImageReference{ImageTagOrSha: "sha256:12345", Local: true})
+				_, skipped := check.IsMissingPrerequisite(err)
+				Expect(skipped).To(BeTrue())
+			})
+		})
 	})
 
 	AssertMetaData(&hasUniqueTagCheck)
@@ -16,7 +16,10 @@ const (
 	acceptableLayerMax = 40
 )
 
-var _ check.Check = &MaxLayersCheck{}
+var (
+	_ check.Check             = &MaxLayersCheck{}
+	_ check.RequirementsCheck = &MaxLayersCheck{}
+)
 
 // UnderLayerMaxCheck ensures that the image has less layers in its assembly than a predefined maximum.
 type MaxLayersCheck struct{}
@@ -36,7 +39,21 @@ func (p *MaxLayersCheck) getDataToValidate(image cranev1.Image) ([]cranev1.Layer
 
 func (p *MaxLayersCheck) validate(ctx context.Context, layers []cranev1.Layer) (bool, error) {
 	logr.FromContextOrDiscard(ctx).V(log.DBG).Info("number of layers detected in image", "layerCount", len(layers))
-	return len(layers) <= acceptableLayerMax, nil
+	if len(layers) > acceptableLayerMax {
+		check.AddFinding(ctx, check.Finding{
+			Message:     fmt.Sprintf("the image has %d layers, more than the maximum of %d", len(layers), acceptableLayerMax),
+			Severity:    check.SeverityError,
+			Remediation: "Combine RUN instructions, or use a multi-stage build, to reduce the number of layers",
+		})
+		return false, nil
+	}
+	return true, nil
+}
+
+// Requirements implements check.RequirementsCheck. Layers are counted from the
+// manifest without being downloaded.
+func (p *MaxLayersCheck) Requirements() check.Requirement {
+	return check.RequiresManifest
 }
 
 func (p *MaxLayersCheck) Name() string {
@@ -8,17 +8,19 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 
 	"github.com/go-logr/logr"
-	cranev1 "github.com/google/go-containerregistry/pkg/v1"
 )
 
-var _ check.Check = &RunAsNonRootCheck{}
+var (
+	_ check.Check             = &RunAsNonRootCheck{}
+	_ check.RequirementsCheck = &RunAsNonRootCheck{}
+)
 
 // RunAsNonRootCheck evaluates the image to determine that the runtime UID is not 0,
 // which correlates to the root user.
 type RunAsNonRootCheck struct{}
 
 func (p *RunAsNonRootCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
-	user, err := p.getDataToValidate(imgRef.ImageInfo)
+	user, err := p.getDataToValidate(imgRef.ImageFacts())
 	if err != nil {
 		return false, fmt.Errorf("could not get validation data: %v", err)
 	}
@@ -26,8 +28,8 @@ func (p *RunAsNonRootCheck) Validate(ctx context.Context, imgRef image.ImageRefe
 	return p.validate(ctx, user)
 }
 
-func (p *RunAsNonRootCheck) getDataToValidate(image cranev1.Image) (string, error) {
-	configFile, err := image.ConfigFile()
+func (p *RunAsNonRootCheck) getDataToValidate(facts *image.Facts) (string, error) {
+	configFile, err := facts.Config()
 	if err != nil {
 		return "", fmt.Errorf("could not retrieve ConfigFile from Image: %w", err)
 	}
@@ -40,12 +42,22 @@ func (p *RunAsNonRootCheck) validate(ctx context.Context, user string) (bool, er
 	if user == "" {
 		logger.Info("detected empty USER. Presumed to be running as root")
 		logger.Info("USER value must be provided and be a non-root value for this check to pass")
+		check.AddFinding(ctx, check.Finding{
+			Message:     "the image does not specify a USER, so it runs as root",
+			Severity:    check.SeverityError,
+			Remediation: "Add a USER instruction with a non-root user or UID, e.g. USER 1001, to your Containerfile",
+		})
 		return false, nil
 	}
 
 	if user == "0" || user == "root" {
 		logger.Info("detected USER specified as root or UID 0")
 		logger.Info("USER other than root is required for this check to pass")
+		check.AddFinding(ctx, check.Finding{
+			Message:     fmt.Sprintf("the image runs as USER %s", user),
+			Severity:    check.SeverityError,
+			Remediation: "Change the USER instruction to a non-root user or UID, e.g. USER 1001",
+		})
 		return false, nil
 	}
 
@@ -53,6 +65,11 @@ func (p *RunAsNonRootCheck) validate(ctx context.Context, user string) (bool, er
 	return true, nil
 }
 
+// Requirements implements check.RequirementsCheck.
+func (p *RunAsNonRootCheck) Requirements() check.Requirement {
+	return check.RequiresConfig
+}
+
 func (p *RunAsNonRootCheck) Name() string {
 	return "RunAsNonRoot"
 }
@@ -0,0 +1,254 @@
+package container
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+
+	"github.com/go-logr/logr"
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+var (
+	_ check.Check             = &SupportsArbitraryUIDCheck{}
+	_ check.RequirementsCheck = &SupportsArbitraryUIDCheck{}
+)
+
+// SupportsArbitraryUIDCheck evaluates that the image can run with the random
+// UID OpenShift assigns under the restricted SCC. That UID is always a member
+// of the root group, so the USER must be known to the image, and the
+// directories the container writes to must be owned by group 0 and be group
+// writable. Images are also expected to provide a passwd entry for the random
+// UID, either through nss_wrapper or a group writable /etc/passwd, though its
+// absence is only reported.
+type SupportsArbitraryUIDCheck struct{}
+
+// passwdEntry is a line of /etc/passwd.
+type passwdEntry struct {
+	Name string
+	UID  string
+	Home string
+}
+
+// arbitraryUIDData holds what is needed to evaluate the image.
+type arbitraryUIDData struct {
+	Config *cranev1.Config
+	// Passwd holds the entries of /etc/passwd, or is nil if the image has none.
+	Passwd []passwdEntry
+	Files  *image.FileIndex
+}
+
+func (p *SupportsArbitraryUIDCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	data, err := p.getDataToValidate(imgRef)
+	if err != nil {
+		return false, fmt.Errorf("could not get validation data: %v", err)
+	}
+
+	return p.validate(ctx, data)
+}
+
+func (p *SupportsArbitraryUIDCheck) getDataToValidate(imgRef image.ImageReference) (arbitraryUIDData, error) {
+	if imgRef.Files == nil {
+		return arbitraryUIDData{}, errors.New("the image's file metadata is not available")
+	}
+
+	configFile, err := imgRef.ImageFacts().Config()
+	if err != nil {
+		return arbitraryUIDData{}, fmt.Errorf("could not retrieve ConfigFile from Image: %w", err)
+	}
+
+	passwd, err := readPasswd(filepath.Join(imgRef.ImageFSPath, "etc", "passwd"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return arbitraryUIDData{}, err
+	}
+
+	return arbitraryUIDData{Config: &configFile.Config, Passwd: passwd, Files: imgRef.Files}, nil
+}
+
+// readPasswd parses the passwd file at p.
+func readPasswd(p string) ([]passwdEntry, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("could not open passwd: %w", err)
+	}
+	defer f.Close()
+
+	return parsePasswd(f)
+}
+
+func parsePasswd(r io.Reader) ([]passwdEntry, error) {
+	entries := []passwdEntry{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < 7 {
+			continue
+		}
+		entries = append(entries, passwdEntry{Name: fields[0], UID: fields[2], Home: fields[5]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error while scanning passwd: %w", err)
+	}
+	return entries, nil
+}
+
+func (p *SupportsArbitraryUIDCheck) validate(ctx context.Context, data arbitraryUIDData) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	passed := true
+
+	// The user part of USER may be followed by a group, e.g. 1001:0.
+	user, _, _ := strings.Cut(data.Config.User, ":")
+	var entry *passwdEntry
+	for i := range data.Passwd {
+		if data.Passwd[i].Name == user || data.Passwd[i].UID == user {
+			entry = &data.Passwd[i]
+			break
+		}
+	}
+	_, numericErr := strconv.Atoi(user)
+	if user != "" && numericErr != nil && entry == nil {
+		passed = false
+		check.AddFinding(ctx, check.Finding{
+			Message:     fmt.Sprintf("USER %s is not numeric and does not exist in /etc/passwd", user),
+			Severity:    check.SeverityError,
+			Path:        "/etc/passwd",
+			Remediation: "Add the user to /etc/passwd, or use a numeric UID, e.g. USER 1001",
+		})
+	}
+
+	home := envValue(data.Config.Env, "HOME")
+	if home == "" && entry != nil {
+		home = entry.Home
+	}
+
+	// The directories the container is expected to write to, and why.
+	writable := map[string]string{}
+	if data.Config.WorkingDir != "" {
+		writable[path.Clean(data.Config.WorkingDir)] = "WORKDIR"
+	}
+	if home != "" {
+		writable[path.Clean(home)] = "$HOME"
+	}
+	for volume := range data.Config.Volumes {
+		writable[path.Clean(volume)] = "VOLUME"
+	}
+	// The root directory is never expected to be writable.
+	delete(writable, "/")
+
+	dirs := make([]string, 0, len(writable))
+	for dir := range writable {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		info, ok := resolveDirectory(data.Files, dir)
+		if !ok {
+			logger.V(log.DBG).Info("directory does not exist in the image, it will be created at runtime", "path", dir, "source", writable[dir])
+			continue
+		}
+		if info.GID == 0 && info.Mode&0o020 != 0 {
+			continue
+		}
+		passed = false
+		check.AddFinding(ctx, check.Finding{
+			Message:     fmt.Sprintf("%s %s is owned by group %d with mode %04o, so it is not writable by an arbitrary UID in group 0", writable[dir], dir, info.GID, unixMode(info.Mode)),
+			Severity:    check.SeverityError,
+			Path:        dir,
+			Remediation: fmt.Sprintf("Make the directory writable by the root group, e.g. with chgrp -R 0 %s && chmod -R g=u %s", dir, dir),
+		})
+	}
+
+	if !providesPasswdEntry(data) {
+		check.AddFinding(ctx, check.Finding{
+			Message:     "the image provides no passwd entry for an arbitrary UID: nss_wrapper is not installed and /etc/passwd is not writable by group 0",
+			Severity:    check.SeverityWarning,
+			Path:        "/etc/passwd",
+			Remediation: "Install nss_wrapper and preload it in the entrypoint, or make /etc/passwd group writable and add an entry for the current UID at startup",
+		})
+	}
+
+	return passed, nil
+}
+
+// providesPasswdEntry returns true if the image can provide a passwd entry
+// for the random UID, through nss_wrapper or a group writable /etc/passwd.
+func providesPasswdEntry(data arbitraryUIDData) bool {
+	if strings.Contains(envValue(data.Config.Env, "LD_PRELOAD"), "libnss_wrapper") {
+		return true
+	}
+	if info, ok := data.Files.Lookup("/etc/passwd"); ok && info.GID == 0 && info.Mode&0o020 != 0 {
+		return true
+	}
+
+	errFound := errors.New("found")
+	err := data.Files.Walk(func(info image.FileInfo) error {
+		if strings.HasPrefix(path.Base(info.Path), "libnss_wrapper.so") {
+			return errFound
+		}
+		return nil
+	})
+	return errors.Is(err, errFound)
+}
+
+// resolveDirectory returns the directory at p, following symbolic links.
+// It returns false if p does not exist or is not a directory.
+func resolveDirectory(files *image.FileIndex, p string) (image.FileInfo, bool) {
+	info, err := files.Resolve(p)
+	if err != nil {
+		return image.FileInfo{}, false
+	}
+	return info, info.Mode.IsDir()
+}
+
+// envValue returns the value of the environment variable called name in env.
+func envValue(env []string, name string) string {
+	for _, e := range env {
+		if k, v, ok := strings.Cut(e, "="); ok && k == name {
+			return v
+		}
+	}
+	return ""
+}
+
+// Requirements implements check.RequirementsCheck.
+func (p *SupportsArbitraryUIDCheck) Requirements() check.Requirement {
+	return check.RequiresConfig | check.RequiresFilesystem
+}
+
+func (p *SupportsArbitraryUIDCheck) Name() string {
+	return "SupportsArbitraryUID"
+}
+
+func (p *SupportsArbitraryUIDCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the image can run with an arbitrary UID in the root group, as assigned by OpenShift under the restricted SCC.",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *SupportsArbitraryUIDCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check SupportsArbitraryUID encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Use a USER that exists in /etc/passwd or is numeric, and make the directories the container writes to owned by the root group and group writable, e.g. with chgrp -R 0 <dir> && chmod -R g=u <dir>.",
+	}
+}
@@ -0,0 +1,124 @@
+package container
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	fakecranev1 "github.com/google/go-containerregistry/pkg/v1/fake"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("SupportsArbitraryUID", func() {
+	var (
+		arbitraryUID SupportsArbitraryUIDCheck
+		imgRef       image.ImageReference
+		config       cranev1.Config
+		findings     *check.Findings
+		ctx          context.Context
+	)
+
+	BeforeEach(func() {
+		config = cranev1.Config{
+			User:       "app",
+			WorkingDir: "/opt/app",
+			Env:        []string{"PATH=/usr/bin"},
+			Volumes:    map[string]struct{}{"/var/lib/app/data": {}},
+		}
+
+		fsPath := GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(fsPath, "etc"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(fsPath, "etc", "passwd"), []byte(
+			"root:x:0:0:root:/root:/bin/bash\napp:x:1001:0:app:/home/app:/sbin/nologin\n"), 0o664)).To(Succeed())
+
+		files := image.NewFileIndex()
+		files.Add(image.FileInfo{Path: "/etc/passwd", Mode: 0o664})
+		files.Add(image.FileInfo{Path: "/opt/app", Mode: fs.ModeDir | 0o775})
+		files.Add(image.FileInfo{Path: "/home/app", Mode: fs.ModeDir | 0o770, UID: 1001})
+		files.Add(image.FileInfo{Path: "/var/lib/app", Mode: fs.ModeSymlink | 0o777, Linkname: "../../srv/app"})
+		files.Add(image.FileInfo{Path: "/srv/app/data", Mode: fs.ModeDir | 0o775})
+
+		imgRef = image.ImageReference{
+			ImageFSPath: fsPath,
+			Files:       files,
+			ImageInfo: &fakecranev1.FakeImage{
+				ConfigFileStub: func() (*cranev1.ConfigFile, error) {
+					return &cranev1.ConfigFile{Config: config}, nil
+				},
+			},
+		}
+		findings = &check.Findings{}
+		ctx = check.ContextWithFindings(context.TODO(), findings)
+	})
+
+	Context("When the image supports an arbitrary UID", func() {
+		It("should pass Validate", func() {
+			ok, err := arbitraryUID.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+		})
+
+		It("should pass Validate with a numeric USER that is not in /etc/passwd", func() {
+			config.User = "1002:0"
+			ok, err := arbitraryUID.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When the USER does not exist", func() {
+		It("should not pass Validate", func() {
+			config.User = "missing"
+			ok, err := arbitraryUID.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()[0].Message).To(Equal("USER missing is not numeric and does not exist in /etc/passwd"))
+		})
+	})
+
+	Context("When a writable directory is not writable by group 0", func() {
+		BeforeEach(func() {
+			imgRef.Files.Add(image.FileInfo{Path: "/opt/app", Mode: fs.ModeDir | 0o755, UID: 1001, GID: 1001})
+			config.Env = append(config.Env, "HOME=/srv/home")
+			imgRef.Files.Add(image.FileInfo{Path: "/srv/home", Mode: fs.ModeDir | 0o750})
+		})
+
+		It("should not pass Validate and report each directory", func() {
+			ok, err := arbitraryUID.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(HaveLen(2))
+			Expect(findings.List()[0].Message).To(Equal("WORKDIR /opt/app is owned by group 1001 with mode 0755, so it is not writable by an arbitrary UID in group 0"))
+			Expect(findings.List()[1].Message).To(Equal("$HOME /srv/home is owned by group 0 with mode 0750, so it is not writable by an arbitrary UID in group 0"))
+		})
+	})
+
+	Context("When the image provides no passwd entry for an arbitrary UID", func() {
+		BeforeEach(func() {
+			imgRef.Files.Add(image.FileInfo{Path: "/etc/passwd", Mode: 0o644})
+		})
+
+		It("should pass Validate with a warning", func() {
+			ok, err := arbitraryUID.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(HaveLen(1))
+			Expect(findings.List()[0].Severity).To(Equal(check.SeverityWarning))
+		})
+
+		It("should detect nss_wrapper", func() {
+			imgRef.Files.Add(image.FileInfo{Path: "/usr/lib64/libnss_wrapper.so", Mode: 0o755})
+			ok, err := arbitraryUID.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+		})
+	})
+})
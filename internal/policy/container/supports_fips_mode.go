@@ -0,0 +1,399 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"debug/buildinfo"
+	"debug/elf"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+
+	"github.com/go-logr/logr"
+)
+
+var (
+	_ check.Check             = &SupportsFIPSModeCheck{}
+	_ check.RequirementsCheck = &SupportsFIPSModeCheck{}
+)
+
+// redHatVendor is the vendor of the rpms built by Red Hat.
+const redHatVendor = "Red Hat, Inc."
+
+// Crypto implementations found in binaries.
+const (
+	cryptoOpenSSL     = "openssl"
+	cryptoBoring      = "boringcrypto"
+	cryptoGoFIPS140   = "go-fips140"
+	cryptoGoNative    = "go-native"
+	cryptoSystemLib   = "system-library"
+	cryptoBundled     = "bundled"
+	cryptoLibraryCopy = "library-copy"
+)
+
+// FIPS readiness of a binary or library.
+const (
+	fipsReady    = "ready"
+	fipsNotReady = "not_ready"
+)
+
+// fipsScanChunkSize is the size of the chunks binaries are searched in for
+// signs of crypto libraries, and fipsScanOverlap the length of the longest
+// match, which chunks overlap by.
+const (
+	fipsScanChunkSize = 1 << 20
+	fipsScanOverlap   = 256
+)
+
+var (
+	// goCryptoPattern matches the functions of Go's crypto packages, as named
+	// in the binary's symbol or pcln table.
+	goCryptoPattern = regexp.MustCompile(`crypto/(?:tls|aes|cipher|rsa|ecdsa|ed25519|sha256|sha512|hmac)\.`)
+	// goOpenSSLPattern matches the functions of the package binding Go's
+	// crypto to OpenSSL in the Go toolchain shipped with RHEL.
+	goOpenSSLPattern = regexp.MustCompile(`golang-fips/openssl(?:/v\d+)?\.`)
+	// bundledCryptoPatterns match the version strings of crypto libraries
+	// statically linked into a binary.
+	bundledCryptoPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`OpenSSL \d+\.\d+\.\d+[a-z]*(?:-fips)?\s+\d{1,2} [A-Z][a-z]{2} \d{4}`),
+		regexp.MustCompile(`BoringSSL`),
+		regexp.MustCompile(`LibreSSL \d+\.\d+\.\d+`),
+		regexp.MustCompile(`mbed TLS \d+\.\d+\.\d+`),
+	}
+	// opensslLibraryPattern matches the file names of OpenSSL's libraries.
+	opensslLibraryPattern = regexp.MustCompile(`^lib(?:crypto|ssl)\.so(?:\.|$)`)
+	// systemCryptoLibraries are the crypto libraries shipped by RHEL whose
+	// FIPS mode follows the system's, by the prefix of their file name.
+	systemCryptoLibraries = []string{"libcrypto.so", "libssl.so", "libgnutls.so", "libnss3.so", "libgcrypt.so"}
+)
+
+// SupportsFIPSModeCheck evaluates whether the crypto used by the binaries in
+// the image would run in FIPS mode, to back the fips-compliant claim of an
+// operator with evidence from its operand images. Go binaries must be built
+// with OpenSSL through CGO, as done by RHEL's Go toolchain, or with a FIPS
+// module such as BoringCrypto. Other binaries must link RHEL's crypto
+// libraries dynamically, rather than bundling their own copy. Binaries and
+// libraries installed by Red Hat rpms are not inspected. The findings are
+// written to the fips-evidence.json artifact. The check is not part of any
+// policy by default, as most images make no FIPS claim, and must be enabled.
+type SupportsFIPSModeCheck struct{}
+
+// fipsEvidence is the FIPS readiness of a binary or library in the image.
+type fipsEvidence struct {
+	Path  string `json:"path"`
+	Layer string `json:"layer,omitempty"`
+	// Package is the rpm that installed the file, if any.
+	Package string `json:"package,omitempty"`
+	// Language is go for binaries built with Go.
+	Language string `json:"language,omitempty"`
+	// GoVersion and CGOEnabled are read from the build information of Go
+	// binaries.
+	GoVersion  string `json:"go_version,omitempty"`
+	CGOEnabled *bool  `json:"cgo_enabled,omitempty"`
+	// Crypto is the crypto implementation used, e.g. openssl or go-native.
+	Crypto string `json:"crypto"`
+	// Details describes the crypto found, e.g. a bundled library's version.
+	Details string `json:"details,omitempty"`
+	// Status is ready or not_ready.
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// fipsReport is written to the fips-evidence.json artifact.
+type fipsReport struct {
+	Image string `json:"image"`
+	// FIPSReady is true if no binary or library is expected to break, or to
+	// use unvalidated crypto, in FIPS mode.
+	FIPSReady bool `json:"fips_ready"`
+	// SystemOpenSSL is the name-version-release of the openssl-libs rpm, if
+	// installed from Red Hat.
+	SystemOpenSSL string `json:"system_openssl,omitempty"`
+	// BinariesInspected is the number of ELF files not installed by Red Hat
+	// rpms that were inspected.
+	BinariesInspected int `json:"binaries_inspected"`
+	// Evidence lists the files using crypto, and the library copies found.
+	Evidence []fipsEvidence `json:"evidence"`
+}
+
+func (p *SupportsFIPSModeCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	report, err := p.getDataToValidate(ctx, imgRef)
+	if err != nil {
+		return false, fmt.Errorf("could not inspect image crypto: %v", err)
+	}
+
+	return p.validate(ctx, report)
+}
+
+func (p *SupportsFIPSModeCheck) getDataToValidate(ctx context.Context, imgRef image.ImageReference) (fipsReport, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	if imgRef.Files == nil {
+		return fipsReport{}, errors.New("the image's file metadata is not available")
+	}
+
+	pkgList, err := imgRef.ImageFacts().Packages(ctx)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fipsReport{}, fmt.Errorf("could not get rpm list: %w", err)
+	}
+	packageFiles, err := installedFileMapWithExclusions(ctx, pkgList)
+	if err != nil {
+		return fipsReport{}, fmt.Errorf("could not get rpm file list: %w", err)
+	}
+	packages := extractPackageNameVersionRelease(pkgList)
+
+	layerDigests, err := getLayerDigests(imgRef.ImageInfo)
+	if err != nil {
+		return fipsReport{}, err
+	}
+
+	report := fipsReport{Image: imgRef.ImageURI, Evidence: []fipsEvidence{}}
+	for _, pkg := range pkgList {
+		if pkg.Name == "openssl-libs" && pkg.Vendor == redHatVendor {
+			report.SystemOpenSSL = fmt.Sprintf("%s-%s-%s", pkg.Name, pkg.Version, pkg.Release)
+		}
+	}
+
+	ownedByRedHat := func(info image.FileInfo) bool {
+		owner, owned := packageFiles[normalize(info.Path)]
+		return owned && packages[owner].Vendor == redHatVendor
+	}
+	err = walkELFFiles(ctx, imgRef, ownedByRedHat, func(info image.FileInfo, f *os.File, ef *elf.File) error {
+		evidence, err := inspectCrypto(f, ef, info.Path, report.SystemOpenSSL != "")
+		if err != nil {
+			return err
+		}
+		report.BinariesInspected++
+		if evidence == nil {
+			return nil
+		}
+		evidence.Path = info.Path
+		if owner, owned := packageFiles[normalize(info.Path)]; owned {
+			evidence.Package = packages[owner].NVRA()
+		}
+		if info.Layer < len(layerDigests) {
+			evidence.Layer = layerDigests[info.Layer]
+		}
+		report.Evidence = append(report.Evidence, *evidence)
+		return nil
+	})
+	if err != nil {
+		return fipsReport{}, err
+	}
+	logger.V(log.DBG).Info("inspected binaries for FIPS readiness", "binaries", report.BinariesInspected, "evidence", len(report.Evidence))
+
+	return report, nil
+}
+
+// inspectCrypto returns how the ELF file f, parsed as ef, at imagePath in the
+// image, uses crypto, or nil if it does not. hasSystemOpenSSL is true if the
+// image has Red Hat's OpenSSL.
+func inspectCrypto(f *os.File, ef *elf.File, imagePath string, hasSystemOpenSSL bool) (*fipsEvidence, error) {
+	if opensslLibraryPattern.MatchString(path.Base(imagePath)) {
+		return &fipsEvidence{
+			Crypto: cryptoLibraryCopy,
+			Status: fipsNotReady,
+			Reason: "copy of OpenSSL not provided by RHEL, which does not use the system's FIPS provider; link the openssl-libs rpm instead",
+		}, nil
+	}
+
+	var linked string
+	if libraries, err := ef.ImportedLibraries(); err == nil {
+		for _, lib := range libraries {
+			for _, prefix := range systemCryptoLibraries {
+				if strings.HasPrefix(lib, prefix) {
+					linked = lib
+				}
+			}
+		}
+	}
+
+	if info, err := buildinfo.Read(f); err == nil {
+		return inspectGoCrypto(f, info, linked, hasSystemOpenSSL)
+	}
+
+	if linked != "" {
+		evidence := &fipsEvidence{Crypto: cryptoSystemLib, Details: linked, Status: fipsReady, Reason: "links the system's crypto library dynamically"}
+		if strings.HasPrefix(linked, "libcrypto.so") || strings.HasPrefix(linked, "libssl.so") {
+			evidence.Crypto = cryptoOpenSSL
+			if !hasSystemOpenSSL {
+				evidence.Status = fipsNotReady
+				evidence.Reason = "links OpenSSL, but the image does not have the openssl-libs rpm from Red Hat"
+			}
+		}
+		return evidence, nil
+	}
+
+	matches, err := scanFile(f, bundledCryptoPatterns)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range matches {
+		if m != "" {
+			return &fipsEvidence{
+				Crypto:  cryptoBundled,
+				Details: m,
+				Status:  fipsNotReady,
+				Reason:  "statically links its own crypto library, which ignores the system's FIPS mode; link RHEL's crypto libraries dynamically instead",
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// inspectGoCrypto returns how the Go binary f, described by info, uses crypto.
+// linked is the system crypto library it links dynamically, if any.
+func inspectGoCrypto(f *os.File, info *buildinfo.BuildInfo, linked string, hasSystemOpenSSL bool) (*fipsEvidence, error) {
+	settings := map[string]string{}
+	for _, s := range info.Settings {
+		settings[s.Key] = s.Value
+	}
+	cgo := settings["CGO_ENABLED"] == "1"
+	evidence := &fipsEvidence{Language: "go", GoVersion: info.GoVersion, CGOEnabled: &cgo}
+
+	matches, err := scanFile(f, []*regexp.Regexp{goCryptoPattern, goOpenSSLPattern})
+	if err != nil {
+		return nil, err
+	}
+	usesCrypto, usesOpenSSL := matches[0] != "", matches[1] != ""
+
+	switch {
+	case strings.Contains(settings["GOEXPERIMENT"], "boringcrypto") || strings.Contains(info.GoVersion, "X:boringcrypto"):
+		evidence.Crypto, evidence.Status = cryptoBoring, fipsReady
+		evidence.Reason = "built with GOEXPERIMENT=boringcrypto, using the BoringCrypto module, which is validated independently of RHEL"
+	case settings["GOFIPS140"] != "" && settings["GOFIPS140"] != "off":
+		evidence.Crypto, evidence.Status = cryptoGoFIPS140, fipsReady
+		evidence.Details = settings["GOFIPS140"]
+		evidence.Reason = "built with GOFIPS140, using the Go Cryptographic Module, which is validated independently of RHEL"
+	case cgo && (usesOpenSSL || strings.HasPrefix(linked, "libcrypto.so")):
+		evidence.Crypto, evidence.Status = cryptoOpenSSL, fipsReady
+		evidence.Reason = "built with CGO_ENABLED=1, using OpenSSL for crypto"
+		if !hasSystemOpenSSL {
+			evidence.Status = fipsNotReady
+			evidence.Reason = "built to use OpenSSL, but the image does not have the openssl-libs rpm from Red Hat"
+		}
+	case usesCrypto:
+		evidence.Crypto, evidence.Status = cryptoGoNative, fipsNotReady
+		evidence.Reason = "uses Go's native crypto, which is not FIPS validated and does not follow the system's FIPS mode; build with RHEL's Go toolchain and CGO_ENABLED=1"
+	default:
+		return nil, nil
+	}
+	return evidence, nil
+}
+
+// scanFile returns the first match of each pattern in the contents of f, or
+// an empty string for the patterns that do not match.
+func scanFile(f *os.File, patterns []*regexp.Regexp) ([]string, error) {
+	matches := make([]string, len(patterns))
+	remaining := len(patterns)
+
+	buf := make([]byte, fipsScanChunkSize+fipsScanOverlap)
+	var offset int64
+	carried := 0
+	for remaining > 0 {
+		n, err := f.ReadAt(buf[carried:], offset)
+		if n == 0 && err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		chunk := buf[:carried+n]
+		for i, pattern := range patterns {
+			if matches[i] != "" {
+				continue
+			}
+			if m := pattern.Find(chunk); m != nil {
+				matches[i] = string(m)
+				remaining--
+			}
+		}
+		offset += int64(n)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		// Keep the end of the chunk, so that matches spanning chunks are found.
+		carried = min(len(chunk), fipsScanOverlap)
+		copy(buf, chunk[len(chunk)-carried:])
+	}
+	return matches, nil
+}
+
+func (p *SupportsFIPSModeCheck) validate(ctx context.Context, report fipsReport) (bool, error) {
+	report.FIPSReady = true
+	for _, e := range report.Evidence {
+		if e.Status != fipsNotReady {
+			continue
+		}
+		report.FIPSReady = false
+		message := fmt.Sprintf("%s is not expected to work in FIPS mode: %s", e.Path, e.Reason)
+		if e.Details != "" {
+			message = fmt.Sprintf("%s is not expected to work in FIPS mode: %s (%s)", e.Path, e.Reason, e.Details)
+		}
+		check.AddFinding(ctx, check.Finding{
+			Message:     message,
+			Severity:    check.SeverityWarning,
+			Path:        e.Path,
+			Package:     e.Package,
+			Layer:       e.Layer,
+			Remediation: "Use the crypto libraries provided by RHEL, and build Go binaries with RHEL's Go toolchain and CGO_ENABLED=1",
+		})
+	}
+
+	if err := writeFIPSReport(ctx, report); err != nil {
+		return false, err
+	}
+
+	return report.FIPSReady, nil
+}
+
+// writeFIPSReport writes report to the fips-evidence.json artifact.
+func writeFIPSReport(ctx context.Context, report fipsReport) error {
+	artifactWriter := artifacts.WriterFromContext(ctx)
+	if artifactWriter == nil {
+		return nil
+	}
+
+	contents, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return fmt.Errorf("could not marshal FIPS evidence: %w", err)
+	}
+	if _, err := artifactWriter.WriteFile(check.DefaultFIPSEvidenceFilename, bytes.NewReader(contents)); err != nil {
+		return fmt.Errorf("failed to save file to artifacts directory: %w", err)
+	}
+	return nil
+}
+
+// Requirements implements check.RequirementsCheck.
+func (p *SupportsFIPSModeCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
+func (p *SupportsFIPSModeCheck) Name() string {
+	return "SupportsFIPSMode"
+}
+
+func (p *SupportsFIPSModeCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the binaries in the image use FIPS capable crypto, such as RHEL's OpenSSL, so that they work when the cluster runs in FIPS mode.",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *SupportsFIPSModeCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check SupportsFIPSMode encountered an error. Please review the preflight.log file for more information.",
+		Suggestion: "Build Go binaries with RHEL's Go toolchain and CGO_ENABLED=1, link other binaries dynamically against the crypto libraries provided by RHEL, and remove bundled copies of OpenSSL. The evidence gathered is written to the fips-evidence.json artifact.",
+	}
+}
@@ -0,0 +1,230 @@
+package container
+
+import (
+	"context"
+	"debug/buildinfo"
+	"debug/elf"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("SupportsFIPSMode", func() {
+	var (
+		imgRef   image.ImageReference
+		ctx      context.Context
+		findings *check.Findings
+		aw       *artifacts.MapWriter
+		plain    []byte
+	)
+
+	addFile := func(name string, mode fs.FileMode, contents []byte) {
+		p := filepath.Join(imgRef.ImageFSPath, name)
+		Expect(os.MkdirAll(filepath.Dir(p), 0o755)).To(Succeed())
+		Expect(os.WriteFile(p, contents, 0o644)).To(Succeed())
+		imgRef.Files.Add(image.FileInfo{Path: name, Mode: mode})
+	}
+
+	readReport := func() fipsReport {
+		r, ok := aw.Files()[check.DefaultFIPSEvidenceFilename]
+		Expect(ok).To(BeTrue())
+		contents, err := io.ReadAll(r)
+		Expect(err).ToNot(HaveOccurred())
+		var report fipsReport
+		Expect(json.Unmarshal(contents, &report)).To(Succeed())
+		return report
+	}
+
+	BeforeEach(func() {
+		img, err := random.Image(256, 1)
+		Expect(err).ToNot(HaveOccurred())
+		imgRef = image.ImageReference{
+			ImageURI:    "quay.io/example/app:1.0",
+			ImageFSPath: GinkgoT().TempDir(),
+			ImageInfo:   img,
+			Files:       image.NewFileIndex(),
+		}
+		findings = &check.Findings{}
+		aw, err = artifacts.NewMapWriter()
+		Expect(err).ToNot(HaveOccurred())
+		ctx = check.ContextWithFindings(artifacts.ContextWithWriter(context.TODO(), aw), findings)
+
+		plain = elfBinary(elfSpec{Type: elf.ET_DYN, Symbols: []string{"main"}})
+		addFile("/opt/app/bin/server", 0o755, plain)
+		addFile("/opt/app/bin/start.sh", 0o755, []byte("#!/bin/sh\n"))
+	})
+
+	Context("When no binary uses crypto", func() {
+		It("should pass Validate and write the evidence", func() {
+			ok, err := (&SupportsFIPSModeCheck{}).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+
+			report := readReport()
+			Expect(report.Image).To(Equal("quay.io/example/app:1.0"))
+			Expect(report.FIPSReady).To(BeTrue())
+			Expect(report.BinariesInspected).To(Equal(1))
+			Expect(report.Evidence).To(BeEmpty())
+		})
+	})
+
+	Context("When a binary bundles its own OpenSSL", func() {
+		BeforeEach(func() {
+			bundled := append(elfBinary(elfSpec{Type: elf.ET_DYN}), []byte("\x00OpenSSL 1.1.1k  25 Mar 2021\x00")...)
+			addFile("/opt/app/bin/proxy", 0o755, bundled)
+		})
+		It("should not pass Validate and report the library", func() {
+			ok, err := (&SupportsFIPSModeCheck{}).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(HaveLen(1))
+			Expect(findings.List()[0].Path).To(Equal("/opt/app/bin/proxy"))
+			Expect(findings.List()[0].Severity).To(Equal(check.SeverityWarning))
+
+			report := readReport()
+			Expect(report.FIPSReady).To(BeFalse())
+			Expect(report.BinariesInspected).To(Equal(2))
+			Expect(report.Evidence).To(HaveLen(1))
+			Expect(report.Evidence[0].Crypto).To(Equal(cryptoBundled))
+			Expect(report.Evidence[0].Details).To(Equal("OpenSSL 1.1.1k  25 Mar 2021"))
+			Expect(report.Evidence[0].Status).To(Equal(fipsNotReady))
+			Expect(report.Evidence[0].Layer).ToNot(BeEmpty())
+		})
+	})
+
+	Context("When the image has a copy of OpenSSL not installed by Red Hat", func() {
+		BeforeEach(func() {
+			addFile("/opt/app/lib/libcrypto.so.1.1", 0o644, plain)
+		})
+		It("should not pass Validate", func() {
+			ok, err := (&SupportsFIPSModeCheck{}).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(HaveLen(1))
+
+			report := readReport()
+			Expect(report.Evidence).To(HaveLen(1))
+			Expect(report.Evidence[0].Path).To(Equal("/opt/app/lib/libcrypto.so.1.1"))
+			Expect(report.Evidence[0].Crypto).To(Equal(cryptoLibraryCopy))
+		})
+	})
+
+	Context("When a Go binary uses Go's native crypto", func() {
+		BeforeEach(func() {
+			// The test binary is built with the upstream Go toolchain, and links
+			// crypto/tls through its dependencies.
+			executable, err := os.Executable()
+			Expect(err).ToNot(HaveOccurred())
+			contents, err := os.ReadFile(executable)
+			Expect(err).ToNot(HaveOccurred())
+			addFile("/opt/app/bin/operator", 0o755, contents)
+		})
+		It("should not pass Validate and record the build information", func() {
+			ok, err := (&SupportsFIPSModeCheck{}).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			report := readReport()
+			Expect(report.Evidence).To(HaveLen(1))
+			evidence := report.Evidence[0]
+			Expect(evidence.Path).To(Equal("/opt/app/bin/operator"))
+			Expect(evidence.Language).To(Equal("go"))
+			Expect(evidence.GoVersion).ToNot(BeEmpty())
+			Expect(evidence.CGOEnabled).ToNot(BeNil())
+			Expect(evidence.Crypto).To(Equal(cryptoGoNative))
+			Expect(evidence.Status).To(Equal(fipsNotReady))
+		})
+	})
+
+	Context("When a Go binary is built with a FIPS capable toolchain", func() {
+		var f *os.File
+
+		BeforeEach(func() {
+			var err error
+			f, err = os.CreateTemp(GinkgoT().TempDir(), "binary")
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(f.Close)
+			// The symbol is formatted so that the test binary itself does not
+			// appear to use OpenSSL.
+			_, err = fmt.Fprintf(f, "crypto/tls.(*Conn).Handshake\x00vendor/github.com/%s/openssl/v2.Init\x00", "golang-fips")
+			Expect(err).ToNot(HaveOccurred())
+		})
+		buildInfo := func(goVersion string, settings ...debug.BuildSetting) *buildinfo.BuildInfo {
+			return &buildinfo.BuildInfo{GoVersion: goVersion, Settings: settings}
+		}
+
+		It("should accept BoringCrypto", func() {
+			evidence, err := inspectGoCrypto(f, buildInfo("go1.22.5 X:boringcrypto"), "", false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(evidence.Crypto).To(Equal(cryptoBoring))
+			Expect(evidence.Status).To(Equal(fipsReady))
+		})
+		It("should accept the Go Cryptographic Module", func() {
+			evidence, err := inspectGoCrypto(f, buildInfo("go1.24.0", debug.BuildSetting{Key: "GOFIPS140", Value: "v1.0.0"}), "", false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(evidence.Crypto).To(Equal(cryptoGoFIPS140))
+			Expect(evidence.Details).To(Equal("v1.0.0"))
+			Expect(evidence.Status).To(Equal(fipsReady))
+		})
+		It("should accept OpenSSL through CGO with the system's OpenSSL", func() {
+			evidence, err := inspectGoCrypto(f, buildInfo("go1.22.9 (Red Hat 1.22.9-2.el9_5)", debug.BuildSetting{Key: "CGO_ENABLED", Value: "1"}), "", true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(evidence.Crypto).To(Equal(cryptoOpenSSL))
+			Expect(*evidence.CGOEnabled).To(BeTrue())
+			Expect(evidence.Status).To(Equal(fipsReady))
+		})
+		It("should not accept OpenSSL without the system's OpenSSL", func() {
+			evidence, err := inspectGoCrypto(f, buildInfo("go1.22.9", debug.BuildSetting{Key: "CGO_ENABLED", Value: "1"}), "", false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(evidence.Crypto).To(Equal(cryptoOpenSSL))
+			Expect(evidence.Status).To(Equal(fipsNotReady))
+		})
+		It("should not accept OpenSSL without CGO", func() {
+			evidence, err := inspectGoCrypto(f, buildInfo("go1.22.9", debug.BuildSetting{Key: "CGO_ENABLED", Value: "0"}), "", true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(evidence.Crypto).To(Equal(cryptoGoNative))
+			Expect(evidence.Status).To(Equal(fipsNotReady))
+		})
+	})
+
+	Context("When scanning a file in chunks", func() {
+		It("should find matches spanning two chunks", func() {
+			f, err := os.CreateTemp(GinkgoT().TempDir(), "binary")
+			Expect(err).ToNot(HaveOccurred())
+			defer f.Close()
+			contents := make([]byte, fipsScanChunkSize+fipsScanOverlap+64)
+			copy(contents[fipsScanChunkSize+fipsScanOverlap-5:], "LibreSSL 3.8.2")
+			_, err = f.Write(contents)
+			Expect(err).ToNot(HaveOccurred())
+
+			matches, err := scanFile(f, bundledCryptoPatterns)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matches).To(ContainElement("LibreSSL 3.8.2"))
+		})
+	})
+
+	Context("When the image's file metadata is not available", func() {
+		It("should return an error", func() {
+			imgRef.Files = nil
+			_, err := (&SupportsFIPSModeCheck{}).Validate(ctx, imgRef)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("should only require the filesystem", func() {
+		Expect((&SupportsFIPSModeCheck{}).Requirements()).To(Equal(check.RequiresFilesystem))
+	})
+})
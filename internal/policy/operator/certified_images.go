@@ -15,7 +15,10 @@ import (
 	"github.com/operator-framework/operator-manifest-tools/pkg/pullspec"
 )
 
-var _ check.Check = &certifiedImagesCheck{}
+var (
+	_ check.Check             = &certifiedImagesCheck{}
+	_ check.RequirementsCheck = &certifiedImagesCheck{}
+)
 
 // imageFinder interface is used for testing. It represents the FindImagesByDigest
 // function that is part of the Pyxis client.
@@ -78,6 +81,11 @@ func (p *certifiedImagesCheck) validate(ctx context.Context, imageDigests []stri
 
 	if len(imageDigests) == 0 {
 		logger.Info("warning: pinned images are expected but none were discovered")
+		check.AddFinding(ctx, check.Finding{
+			Message:     "no images pinned by digest were found in the bundle's manifests",
+			Severity:    check.SeverityWarning,
+			Remediation: "Reference the operator's images by digest in the CSV",
+		})
 		return false, nil
 	}
 
@@ -96,18 +104,33 @@ func (p *certifiedImagesCheck) validate(ctx context.Context, imageDigests []stri
 		if !ok {
 			logger.Info("warning: image not found in Pyxis, therefore it is not certified", "digest", digest)
 			p.nonCertifiedImages = append(p.nonCertifiedImages, digest)
+			check.AddFinding(ctx, check.Finding{
+				Message:     fmt.Sprintf("image %s was not found in the Red Hat catalog, so it is not certified", digest),
+				Severity:    check.SeverityWarning,
+				Remediation: "Certify the image before certifying the operator",
+			})
 			continue
 		}
 		if !img.Certified {
 			fullImg := fmt.Sprintf("%s/%s@%s", img.Repositories[0].Registry, img.Repositories[0].Repository, img.DockerImageDigest)
 			logger.Info("warning: image is not certified", "image", fullImg)
 			p.nonCertifiedImages = append(p.nonCertifiedImages, fullImg)
+			check.AddFinding(ctx, check.Finding{
+				Message:     fmt.Sprintf("image %s is not certified", fullImg),
+				Severity:    check.SeverityWarning,
+				Remediation: "Certify the image before certifying the operator",
+			})
 		}
 	}
 
 	return len(p.nonCertifiedImages) == 0, nil
 }
 
+// Requirements implements check.RequirementsCheck.
+func (p *certifiedImagesCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
 func (p *certifiedImagesCheck) Name() string {
 	return "BundleImageRefsAreCertified"
 }
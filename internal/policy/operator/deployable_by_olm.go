@@ -35,7 +35,11 @@ import (
 
 type Option func(*DeployableByOlmCheck)
 
-var _ check.Check = &DeployableByOlmCheck{}
+var (
+	_ check.Check             = &DeployableByOlmCheck{}
+	_ check.SerialCheck       = &DeployableByOlmCheck{}
+	_ check.RequirementsCheck = &DeployableByOlmCheck{}
+)
 
 type operatorData struct {
 	CatalogImage     string
@@ -708,6 +712,18 @@ func (p *DeployableByOlmCheck) getImages(ctx context.Context) (map[string]struct
 	return p.openshiftClient.GetImages(ctx)
 }
 
+// RequiresSerialExecution implements check.SerialCheck. The check installs the
+// operator into the cluster and compares the images running before and after,
+// so it must not overlap with other cluster interactions.
+func (p *DeployableByOlmCheck) RequiresSerialExecution() bool {
+	return true
+}
+
+// Requirements implements check.RequirementsCheck.
+func (p *DeployableByOlmCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem | check.RequiresCluster
+}
+
 func (p *DeployableByOlmCheck) Name() string {
 	return "DeployableByOLM"
 }
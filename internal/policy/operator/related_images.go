@@ -15,7 +15,10 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
-var _ check.Check = &RelatedImagesCheck{}
+var (
+	_ check.Check             = &RelatedImagesCheck{}
+	_ check.RequirementsCheck = &RelatedImagesCheck{}
+)
 
 type RelatedImagesCheck struct{}
 
@@ -70,11 +73,21 @@ func (p *RelatedImagesCheck) validate(ctx context.Context, images []string, rela
 	for _, image := range images {
 		if _, ok := relatedImages[image]; !ok {
 			logger.Info(fmt.Sprintf("warning: image %s is not in relatedImages. This will eventually cause this check to fail", image))
+			check.AddFinding(ctx, check.Finding{
+				Message:     fmt.Sprintf("image %s is not in relatedImages. This will eventually cause this check to fail", image),
+				Severity:    check.SeverityWarning,
+				Remediation: "Add the image to spec.relatedImages in the CSV",
+			})
 		}
 	}
 	return true, nil
 }
 
+// Requirements implements check.RequirementsCheck.
+func (p *RelatedImagesCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
 func (p *RelatedImagesCheck) Name() string {
 	return "AllImageRefsInRelatedImages"
 }
@@ -3,6 +3,7 @@ package operator
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/go-logr/logr"
 	"github.com/operator-framework/api/pkg/manifests"
@@ -27,7 +28,10 @@ var infraAnnotations = map[string]string{
 	libcsv.CSIAnnotation:            "optional",
 }
 
-var _ check.Check = &RequiredAnnotations{}
+var (
+	_ check.Check             = &RequiredAnnotations{}
+	_ check.RequirementsCheck = &RequiredAnnotations{}
+)
 
 type RequiredAnnotations struct{}
 
@@ -70,16 +74,41 @@ func (h RequiredAnnotations) validate(ctx context.Context, bundledir string) (bo
 	if len(missingAnnotations) > 0 {
 		logger.V(log.DBG).Info("expected annotations are missing", "missingAnnotations", missingAnnotations)
 	}
+	sort.Strings(missingAnnotations)
+	for _, annotation := range missingAnnotations {
+		check.AddFinding(ctx, check.Finding{
+			Message:     fmt.Sprintf("required annotation %s is missing from the CSV", annotation),
+			Severity:    check.SeverityError,
+			Remediation: fmt.Sprintf("Add the annotation %s with a value of \"true\" or \"false\" to the CSV", annotation),
+		})
+	}
 
 	if len(incorrectValues) > 0 {
 		for key, value := range incorrectValues {
 			logger.V(log.DBG).Info(fmt.Sprintf("expected annotation: %s to have either 'true' or 'false' value, but had value of: %s.", key, value))
 		}
 	}
+	incorrectKeys := make([]string, 0, len(incorrectValues))
+	for key := range incorrectValues {
+		incorrectKeys = append(incorrectKeys, key)
+	}
+	sort.Strings(incorrectKeys)
+	for _, key := range incorrectKeys {
+		check.AddFinding(ctx, check.Finding{
+			Message:     fmt.Sprintf("annotation %s has a value of %q", key, incorrectValues[key]),
+			Severity:    check.SeverityError,
+			Remediation: fmt.Sprintf("Set the annotation %s to either \"true\" or \"false\"", key),
+		})
+	}
 
 	return len(missingAnnotations) == 0 && len(incorrectValues) == 0, nil
 }
 
+// Requirements implements check.RequirementsCheck.
+func (h RequiredAnnotations) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
 func (h RequiredAnnotations) Name() string {
 	return "RequiredAnnotations"
 }
@@ -2,6 +2,7 @@ package operator
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-logr/logr"
 	"github.com/operator-framework/api/pkg/manifests"
@@ -13,7 +14,10 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 )
 
-var _ check.Check = &FollowsRestrictedNetworkEnablementGuidelines{}
+var (
+	_ check.Check             = &FollowsRestrictedNetworkEnablementGuidelines{}
+	_ check.RequirementsCheck = &FollowsRestrictedNetworkEnablementGuidelines{}
+)
 
 type FollowsRestrictedNetworkEnablementGuidelines struct{}
 
@@ -49,18 +53,33 @@ func (p FollowsRestrictedNetworkEnablementGuidelines) validate(ctx context.Conte
 
 	if !restrictedNetworkSupport {
 		logger.Info("this operator does not indicate it supports installation into restricted networks. This is safe to ignore if you are not intending to deploy in these environments.")
+		check.AddFinding(ctx, check.Finding{
+			Message:     "the CSV does not indicate the operator supports restricted networks",
+			Severity:    check.SeverityInfo,
+			Remediation: fmt.Sprintf("Set the %s annotation to \"true\" if the operator supports restricted networks", libcsv.InfrastructureFeaturesAnnotation),
+		})
 		return false, nil
 	}
 
 	// You must have at least one related image (your controller manager) in order to be considered restricted-network ready
 	if !libcsv.HasRelatedImages(csv) {
 		logger.Info("this operator did not have any related images, and at least one is expected")
+		check.AddFinding(ctx, check.Finding{
+			Message:     "the CSV does not list any related images",
+			Severity:    check.SeverityError,
+			Remediation: "List the operator's images, including its controller, in spec.relatedImages",
+		})
 		return false, nil
 	}
 
 	// All related images must be pinned. No tag references.
 	if !libcsv.RelatedImagesArePinned(csv.Spec.RelatedImages) {
 		logger.Info("a related image is not pinned to a digest reference of the same image, and this is required.")
+		check.AddFinding(ctx, check.Finding{
+			Message:     "a related image is not pinned to a digest",
+			Severity:    check.SeverityError,
+			Remediation: "Reference every image in spec.relatedImages by digest",
+		})
 		return false, nil
 	}
 
@@ -74,12 +93,22 @@ func (p FollowsRestrictedNetworkEnablementGuidelines) validate(ctx context.Conte
 	relatedImagesInContainerEnvironment := libcsv.RelatedImageReferencesInEnvironment(deploymentSpecs...)
 	if len(relatedImagesInContainerEnvironment) == 0 {
 		logger.Info("no environment variables prefixed with \"RELATED_IMAGE_\" were found in your operator's container definitions. These are expected to pass through values into your controller's runtime environment.")
+		check.AddFinding(ctx, check.Finding{
+			Message:     "no RELATED_IMAGE_ environment variables were found in the operator's deployments",
+			Severity:    check.SeverityError,
+			Remediation: "Pass the related images to the controller with environment variables prefixed with RELATED_IMAGE_",
+		})
 		return false, nil
 	}
 
 	return true, nil
 }
 
+// Requirements implements check.RequirementsCheck.
+func (p FollowsRestrictedNetworkEnablementGuidelines) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
 func (p FollowsRestrictedNetworkEnablementGuidelines) Name() string {
 	return "FollowsRestrictedNetworkEnablementGuidelines"
 }
@@ -11,7 +11,10 @@ import (
 	"github.com/go-logr/logr"
 )
 
-var _ check.Check = &securityContextConstraintsInCSV{}
+var (
+	_ check.Check             = &securityContextConstraintsInCSV{}
+	_ check.RequirementsCheck = &securityContextConstraintsInCSV{}
+)
 
 // securityContextConstraintsInCSV evaluates the csv and logs a message if a non default security context constraint is
 // needed by the operator
@@ -63,6 +66,11 @@ func (p *securityContextConstraintsInCSV) validate(ctx context.Context, requeste
 	return true, nil
 }
 
+// Requirements implements check.RequirementsCheck.
+func (p *securityContextConstraintsInCSV) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
 func (p *securityContextConstraintsInCSV) Name() string {
 	return "SecurityContextConstraintsInCSV"
 }
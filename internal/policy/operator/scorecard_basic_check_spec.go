@@ -11,7 +11,11 @@ import (
 	"github.com/go-logr/logr"
 )
 
-var _ check.Check = &ScorecardBasicSpecCheck{}
+var (
+	_ check.Check             = &ScorecardBasicSpecCheck{}
+	_ check.SerialCheck       = &ScorecardBasicSpecCheck{}
+	_ check.RequirementsCheck = &ScorecardBasicSpecCheck{}
+)
 
 // ScorecardBasicSpecCheck evaluates the image to ensure it passes the operator-sdk
 // scorecard check with the basic-check-spec-test suite selected.
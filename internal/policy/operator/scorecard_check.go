@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/operatorsdk"
 
 	"github.com/go-logr/logr"
@@ -19,6 +20,18 @@ type scorecardCheck struct {
 	waitTime       string
 }
 
+// RequiresSerialExecution implements check.SerialCheck. Scorecard runs test pods
+// in the scorecard namespace, and must not overlap with other cluster interactions.
+func (p *scorecardCheck) RequiresSerialExecution() bool {
+	return true
+}
+
+// Requirements implements check.RequirementsCheck. Scorecard runs the bundle's
+// tests in the cluster.
+func (p *scorecardCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem | check.RequiresCluster
+}
+
 //nolint:unparam // ctx is unused. Keep for future use.
 func (p *scorecardCheck) validate(ctx context.Context, items []operatorsdk.OperatorSdkScorecardItem) (bool, error) {
 	logger := logr.FromContextOrDiscard(ctx)
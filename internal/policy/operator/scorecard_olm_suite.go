@@ -11,7 +11,11 @@ import (
 	"github.com/go-logr/logr"
 )
 
-var _ check.Check = &ScorecardOlmSuiteCheck{}
+var (
+	_ check.Check             = &ScorecardOlmSuiteCheck{}
+	_ check.SerialCheck       = &ScorecardOlmSuiteCheck{}
+	_ check.RequirementsCheck = &ScorecardOlmSuiteCheck{}
+)
 
 // ScorecardOlmSuiteCheck evaluates the image to ensure it passes the operator-sdk
 // scorecard check with the olm suite selected.
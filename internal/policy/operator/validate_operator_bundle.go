@@ -12,7 +12,10 @@ import (
 	"github.com/go-logr/logr"
 )
 
-var _ check.Check = &ValidateOperatorBundleCheck{}
+var (
+	_ check.Check             = &ValidateOperatorBundleCheck{}
+	_ check.RequirementsCheck = &ValidateOperatorBundleCheck{}
+)
 
 // ValidateOperatorBundleCheck evaluates the image and ensures that it passes bundle validation
 // as executed by `operator-sdk bundle validate`
@@ -42,15 +45,22 @@ func (p *ValidateOperatorBundleCheck) validate(ctx context.Context, report *bund
 		for _, output := range report.Results {
 			for _, result := range output.Errors {
 				logger.Error(errors.New("validate operator bundle error"), result.Error())
+				check.AddFinding(ctx, check.Finding{Message: result.Error(), Severity: check.SeverityError})
 			}
 			for _, result := range output.Warnings {
 				logger.Info(fmt.Sprintf("warning: %s", result.Error()))
+				check.AddFinding(ctx, check.Finding{Message: result.Error(), Severity: check.SeverityWarning})
 			}
 		}
 	}
 	return report.Passed, nil
 }
 
+// Requirements implements check.RequirementsCheck.
+func (p *ValidateOperatorBundleCheck) Requirements() check.Requirement {
+	return check.RequiresFilesystem
+}
+
 func (p *ValidateOperatorBundleCheck) Name() string {
 	return "ValidateOperatorBundle"
 }
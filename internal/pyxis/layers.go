@@ -6,8 +6,11 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/go-logr/logr"
 	cranev1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/shurcooL/graphql"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 )
 
 // CertifiedImagesContainingLayers takes uncompressedLayerHashes and queries to a Red Hat Pyxis,
@@ -80,3 +83,112 @@ func (p *pyxisClient) CertifiedImagesContainingLayers(ctx context.Context, uncom
 
 	return images, nil
 }
+
+// certifiedImagesPageSize is the number of images requested per page when
+// listing the certified images of a registry.
+const certifiedImagesPageSize = 250
+
+// certifiedImagesPage is a page of find_images results listing the certified
+// images of a registry.
+type certifiedImagesPage struct {
+	ContainerImage []struct {
+		ID                     graphql.String `graphql:"_id"`
+		Architecture           graphql.String `graphql:"architecture"`
+		DockerImageDigest      graphql.String `graphql:"docker_image_digest"`
+		UncompressedTopLayerID graphql.String `graphql:"uncompressed_top_layer_id"`
+		Repositories           []struct {
+			Registry   graphql.String `graphql:"registry"`
+			Repository graphql.String `graphql:"repository"`
+			Tags       []struct {
+				Name graphql.String `graphql:"name"`
+			} `graphql:"tags"`
+		} `graphql:"repositories"`
+	} `graphql:"data"`
+	Error struct {
+		Status graphql.Int    `graphql:"status"`
+		Detail graphql.String `graphql:"detail"`
+	} `graphql:"error"`
+	Total graphql.Int
+	Page  graphql.Int
+}
+
+// CertifiedImagesInRegistry returns the certified images published to registry, e.g.
+// registry.access.redhat.com, with their uncompressed top layer IDs. If repositories is
+// not empty, only the images of those repositories are returned. The images are fetched
+// from Pyxis page by page, which may take a while for a whole registry.
+func (p *pyxisClient) CertifiedImagesInRegistry(ctx context.Context, registry string, repositories []string) ([]CertImage, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	var registryQuery struct {
+		FindImages certifiedImagesPage `graphql:"find_images(filter: {repositories:{registry:{eq:$registry}}}, page: $page, page_size: $pageSize)"`
+	}
+	var repositoriesQuery struct {
+		FindImages certifiedImagesPage `graphql:"find_images(filter: {and:[{repositories:{registry:{eq:$registry}}}{repositories:{repository:{in:$repositories}}}]}, page: $page, page_size: $pageSize)"`
+	}
+
+	graphqlRepositories := make([]graphql.String, 0, len(repositories))
+	for _, repository := range repositories {
+		graphqlRepositories = append(graphqlRepositories, graphql.String(repository))
+	}
+
+	httpClient, ok := p.Client.(*http.Client)
+	if !ok {
+		return nil, fmt.Errorf("client could not be used as http.Client")
+	}
+	client := graphql.NewClient(p.getPyxisGraphqlURL(), httpClient)
+
+	images := []CertImage{}
+	for page := 0; ; page++ {
+		variables := map[string]interface{}{
+			"registry": graphql.String(registry),
+			"page":     graphql.Int(page),
+			"pageSize": graphql.Int(certifiedImagesPageSize),
+		}
+
+		var results *certifiedImagesPage
+		var err error
+		if len(repositories) == 0 {
+			err = client.Query(ctx, &registryQuery, variables)
+			results = &registryQuery.FindImages
+		} else {
+			variables["repositories"] = graphqlRepositories
+			err = client.Query(ctx, &repositoriesQuery, variables)
+			results = &repositoriesQuery.FindImages
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error while executing find_images query: %v", err)
+		}
+		if results.Error.Status != 0 {
+			return nil, fmt.Errorf("find_images query failed with status %d: %s", results.Error.Status, results.Error.Detail)
+		}
+
+		for _, image := range results.ContainerImage {
+			repos := make([]Repository, 0, len(image.Repositories))
+			for _, repo := range image.Repositories {
+				tags := make([]Tag, 0, len(repo.Tags))
+				for _, tag := range repo.Tags {
+					tags = append(tags, Tag{Name: string(tag.Name)})
+				}
+				repos = append(repos, Repository{
+					Registry:   string(repo.Registry),
+					Repository: string(repo.Repository),
+					Tags:       tags,
+				})
+			}
+			images = append(images, CertImage{
+				ID:                     string(image.ID),
+				Architecture:           string(image.Architecture),
+				DockerImageDigest:      string(image.DockerImageDigest),
+				UncompressedTopLayerID: string(image.UncompressedTopLayerID),
+				Repositories:           repos,
+			})
+		}
+		logger.V(log.DBG).Info("fetched certified images", "registry", registry, "page", page, "fetched", len(images), "total", int(results.Total))
+
+		if len(results.ContainerImage) < certifiedImagesPageSize || len(images) >= int(results.Total) {
+			break
+		}
+	}
+
+	return images, nil
+}
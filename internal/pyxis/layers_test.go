@@ -29,3 +29,54 @@ var _ = Describe("Pyxis CheckRedHatLayers", func() {
 		})
 	})
 })
+
+var _ = Describe("Pyxis CertifiedImagesInRegistry", func() {
+	ctx := context.Background()
+	var (
+		pyxisClient *pyxisClient
+		queries     []string
+	)
+
+	BeforeEach(func() {
+		queries = nil
+		mux := http.NewServeMux()
+		mux.HandleFunc("/query/", pyxisGraphqlRegistryImagesHandler(ctx, certifiedImagesPageSize+1, &queries))
+		pyxisClient = NewPyxisClient("my.pyxis.host/query/", "", "", &http.Client{Transport: localRoundTripper{handler: mux}})
+	})
+
+	Context("when no repositories are provided", func() {
+		It("should fetch every page of the registry's images", func() {
+			certImages, err := pyxisClient.CertifiedImagesInRegistry(ctx, "registry.access.redhat.com", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(certImages).To(HaveLen(certifiedImagesPageSize + 1))
+			Expect(queries).To(HaveLen(2))
+			Expect(queries[0]).ToNot(ContainSubstring("$repositories"))
+
+			last := certImages[certifiedImagesPageSize]
+			Expect(last.ID).To(Equal("image-250"))
+			Expect(last.Architecture).To(Equal("amd64"))
+			Expect(last.UncompressedTopLayerID).To(HavePrefix("sha256:"))
+			Expect(last.Repositories).To(HaveLen(1))
+			Expect(last.Repositories[0].Repository).To(Equal("ubi9/ubi"))
+			Expect(last.Repositories[0].Tags).To(ConsistOf(Tag{Name: "9.250"}))
+		})
+	})
+
+	Context("when repositories are provided", func() {
+		It("should filter the query by repository", func() {
+			_, err := pyxisClient.CertifiedImagesInRegistry(ctx, "registry.access.redhat.com", []string{"ubi9/ubi"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queries[0]).To(ContainSubstring("$repositories"))
+		})
+	})
+
+	Context("when the query fails", func() {
+		It("should return an error", func() {
+			errorMux := http.NewServeMux()
+			errorMux.Handle("/query/", &errorHandler{})
+			pyxisClient.Client = &http.Client{Transport: localRoundTripper{handler: errorMux}}
+			_, err := pyxisClient.CertifiedImagesInRegistry(ctx, "registry.access.redhat.com", nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -2,6 +2,7 @@ package pyxis
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -204,6 +205,42 @@ func pyxisGraphqlLayerHandler(ctx context.Context) http.HandlerFunc {
 	}
 }
 
+// pyxisGraphqlRegistryImagesHandler serves total certified images, in pages of
+// certifiedImagesPageSize, and records the queries received.
+func pyxisGraphqlRegistryImagesHandler(ctx context.Context, total int, queries *[]string) http.HandlerFunc {
+	logger := logr.FromContextOrDiscard(ctx)
+	return func(response http.ResponseWriter, request *http.Request) {
+		logger.V(log.TRC).Info("in the graphql registry images handler")
+		response.Header().Set("Content-Type", "application/json")
+		defer request.Body.Close()
+
+		var body struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Page int `json:"page"`
+			} `json:"variables"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			response.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		*queries = append(*queries, body.Query)
+
+		images := make([]string, 0, certifiedImagesPageSize)
+		for i := body.Variables.Page * certifiedImagesPageSize; i < min(total, (body.Variables.Page+1)*certifiedImagesPageSize); i++ {
+			images = append(images, fmt.Sprintf(`{
+				"_id":"image-%d",
+				"architecture":"amd64",
+				"docker_image_digest":"sha256:%064d",
+				"uncompressed_top_layer_id":"sha256:%064d",
+				"repositories":[{"registry":"registry.access.redhat.com","repository":"ubi9/ubi","tags":[{"name":"9.%d"}]}]
+			}`, i, i, i, i))
+		}
+		mustWrite(response, fmt.Sprintf(`{"data":{"find_images":{"error":null,"total":%d,"page":%d,"data":[%s]}}}`,
+			total, body.Variables.Page, strings.Join(images, ",")))
+	}
+}
+
 func pyxisGraphqlFindImagesHandler(ctx context.Context) http.HandlerFunc {
 	logger := logr.FromContextOrDiscard(ctx)
 	return func(response http.ResponseWriter, request *http.Request) {
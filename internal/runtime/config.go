@@ -1,13 +1,19 @@
 package runtime
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layercache"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
 
 	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // Config contains configuration details for running preflight.
@@ -20,6 +26,15 @@ type Config struct {
 	LogFile        string
 	Artifacts      string
 	WriteJUnit     bool
+	Parallelism    int
+	CacheDir       string
+	CacheMaxSize   int64
+	// ExtractionLimits bounds how much of an image is written to disk.
+	ExtractionLimits ExtractionLimits
+	// CheckSelection narrows the policy's checks and overrides their levels.
+	CheckSelection check.Selection
+	// CheckTimeouts bounds how long each check may run.
+	CheckTimeouts CheckTimeouts
 	// Container-Specific Fields
 	CertificationProjectID string
 	PyxisHost              string
@@ -30,6 +45,33 @@ type Config struct {
 	Insecure               bool
 	Offline                bool
 	ManifestListDigest     string
+	// MetadataOnly runs only the checks that do not need the image's
+	// filesystem, so its layers are not downloaded.
+	MetadataOnly bool
+	// ParallelPlatforms checks the platforms of a manifest list concurrently.
+	ParallelPlatforms bool
+	// RequiredArchitectures must be present in the manifest list of the image.
+	RequiredArchitectures []string
+	// AllowedCapabilities are the file capabilities executables may carry.
+	AllowedCapabilities []string
+	// EnforceSecretsCheck fails the certification if secrets are found.
+	EnforceSecretsCheck bool
+	// FIPSCheck adds the SupportsFIPSMode check to the container policy.
+	FIPSCheck bool
+	// EnabledChecks are the optional checks added to the container policy.
+	EnabledChecks []string
+	// SBOMFormats are the formats the image's SBOM is written to the
+	// artifacts directory in, e.g. spdx and cyclonedx.
+	SBOMFormats []string
+	// AdvisoryDB is the advisory file, or directory of them, the image's
+	// packages are matched against for known vulnerabilities.
+	AdvisoryDB string
+	// HardeningMinimum are the hardening properties, e.g. pie and relro,
+	// binaries added to the image must have.
+	HardeningMinimum []string
+	// BaseImageCatalog is the catalog of approved base images the image's
+	// layers are looked up in instead of Pyxis.
+	BaseImageCatalog string
 	// Operator-Specific Fields
 	Namespace           string
 	ServiceAccount      string
@@ -59,11 +101,124 @@ func NewConfigFrom(vcfg viper.Viper) (*Config, error) {
 	cfg.DockerConfig = vcfg.GetString("dockerConfig")
 	cfg.Artifacts = vcfg.GetString("artifacts")
 	cfg.WriteJUnit = vcfg.GetBool("junit")
+	cfg.Parallelism = vcfg.GetInt("parallelism")
+	cfg.CacheDir = vcfg.GetString("cache_dir")
+	cacheMaxSize, err := ParseCacheMaxSize(vcfg.GetString("cache_max_size"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.CacheMaxSize = cacheMaxSize
+	if err := cfg.storeExtractionLimits(vcfg); err != nil {
+		return nil, err
+	}
+	cfg.storeCheckSelection(vcfg)
+	if err := cfg.storeCheckTimeouts(vcfg); err != nil {
+		return nil, err
+	}
 	cfg.storeContainerPolicyConfiguration(vcfg)
 	cfg.storeOperatorPolicyConfiguration(vcfg)
 	return &cfg, nil
 }
 
+// ParseCacheMaxSize converts a quantity such as "10Gi" or "500M" to a number
+// of bytes. An empty value returns the layer cache's default max size.
+func ParseCacheMaxSize(value string) (int64, error) {
+	size, err := parseByteQuantity(value, layercache.DefaultMaxSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cache max size %q: %w", value, err)
+	}
+	return size, nil
+}
+
+// storeExtractionLimits reads the image extraction limits in viper and stores
+// them in Config. Unset values fall back to the defaults.
+func (c *Config) storeExtractionLimits(vcfg viper.Viper) error {
+	maxSize, err := parseByteQuantity(vcfg.GetString("extract_max_size"), DefaultExtractionLimits.MaxSize)
+	if err != nil {
+		return fmt.Errorf("invalid extraction max size %q: %w", vcfg.GetString("extract_max_size"), err)
+	}
+	maxFileSize, err := parseByteQuantity(vcfg.GetString("extract_max_file_size"), DefaultExtractionLimits.MaxFileSize)
+	if err != nil {
+		return fmt.Errorf("invalid extraction max file size %q: %w", vcfg.GetString("extract_max_file_size"), err)
+	}
+	maxEntries := vcfg.GetInt("extract_max_entries")
+	if maxEntries < 0 {
+		return fmt.Errorf("invalid extraction max entries %d: must not be negative", maxEntries)
+	}
+	if maxEntries == 0 {
+		maxEntries = DefaultExtractionLimits.MaxEntries
+	}
+
+	c.ExtractionLimits = ExtractionLimits{
+		MaxSize:     maxSize,
+		MaxFileSize: maxFileSize,
+		MaxEntries:  maxEntries,
+	}
+	return nil
+}
+
+// storeCheckSelection reads the checks to include and exclude, and the check
+// level overrides, and stores them in Config. Lists may be given as repeated
+// values or as comma-separated strings, e.g. from the environment.
+func (c *Config) storeCheckSelection(vcfg viper.Viper) {
+	c.CheckSelection = check.Selection{
+		Include: splitNames(vcfg.GetStringSlice("include_checks")),
+		Exclude: splitNames(vcfg.GetStringSlice("exclude_checks")),
+	}
+	if levels := vcfg.GetStringMapString("check_levels"); len(levels) > 0 {
+		c.CheckSelection.Levels = levels
+	}
+}
+
+// storeCheckTimeouts reads the default check timeout and any per-check
+// overrides in viper and stores them in Config.
+func (c *Config) storeCheckTimeouts(vcfg viper.Viper) error {
+	c.CheckTimeouts = CheckTimeouts{Default: vcfg.GetDuration("check_timeout")}
+
+	overrides := vcfg.GetStringMapString("check_timeouts")
+	if len(overrides) == 0 {
+		return nil
+	}
+	c.CheckTimeouts.Overrides = make(map[string]time.Duration, len(overrides))
+	for name, value := range overrides {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q for check %s: %w", value, name, err)
+		}
+		c.CheckTimeouts.Overrides[name] = timeout
+	}
+	return nil
+}
+
+// splitNames splits any comma-separated values, dropping empty names.
+func splitNames(values []string) []string {
+	var names []string
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// parseByteQuantity converts a quantity such as "10Gi" or "500M" to a number
+// of bytes. An empty value returns def. Negative quantities are rejected.
+func parseByteQuantity(value string, def int64) (int64, error) {
+	if value == "" {
+		return def, nil
+	}
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, err
+	}
+	if q.Sign() < 0 {
+		return 0, errors.New("must not be negative")
+	}
+	return q.Value(), nil
+}
+
 // storeContainerPolicyConfiguration reads container-policy-specific config
 // items in viper, normalizes them, and stores them in Config.
 func (c *Config) storeContainerPolicyConfiguration(vcfg viper.Viper) {
@@ -74,6 +229,17 @@ func (c *Config) storeContainerPolicyConfiguration(vcfg viper.Viper) {
 	c.Platform = vcfg.GetString("platform")
 	c.Insecure = vcfg.GetBool("insecure")
 	c.Offline = vcfg.GetBool("offline")
+	c.MetadataOnly = vcfg.GetBool("metadata_only")
+	c.ParallelPlatforms = vcfg.GetBool("parallel_platforms")
+	c.RequiredArchitectures = splitNames(vcfg.GetStringSlice("required_architectures"))
+	c.AllowedCapabilities = splitNames(vcfg.GetStringSlice("allowed_capabilities"))
+	c.EnforceSecretsCheck = vcfg.GetBool("enforce_secrets_check")
+	c.FIPSCheck = vcfg.GetBool("fips_check")
+	c.EnabledChecks = splitNames(vcfg.GetStringSlice("enable_checks"))
+	c.SBOMFormats = splitNames(vcfg.GetStringSlice("sbom_format"))
+	c.AdvisoryDB = vcfg.GetString("advisory_db")
+	c.HardeningMinimum = splitNames(vcfg.GetStringSlice("binary_hardening_minimum"))
+	c.BaseImageCatalog = vcfg.GetString("base_image_catalog")
 }
 
 // storeOperatorPolicyConfiguration reads operator-policy-specific config
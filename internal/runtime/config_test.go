@@ -3,6 +3,9 @@ package runtime
 import (
 	"os"
 	"reflect"
+	"time"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -28,6 +31,35 @@ var _ = Describe("Viper to Runtime Config", func() {
 		expectedRuntimeCfg.Artifacts = "artifacts"
 		baseViperCfg.Set("junit", true)
 		expectedRuntimeCfg.WriteJUnit = true
+		baseViperCfg.Set("parallelism", 4)
+		expectedRuntimeCfg.Parallelism = 4
+		baseViperCfg.Set("cache_dir", "/var/cache/preflight")
+		expectedRuntimeCfg.CacheDir = "/var/cache/preflight"
+		baseViperCfg.Set("cache_max_size", "1Gi")
+		expectedRuntimeCfg.CacheMaxSize = 1 << 30
+		baseViperCfg.Set("extract_max_size", "2Gi")
+		baseViperCfg.Set("extract_max_entries", 1000)
+		expectedRuntimeCfg.ExtractionLimits = ExtractionLimits{
+			MaxSize:     2 << 30,
+			MaxFileSize: DefaultExtractionLimits.MaxFileSize,
+			MaxEntries:  1000,
+		}
+
+		baseViperCfg.Set("include_checks", []string{"RunAsNonRoot,HasLicense", "HasRequiredLabel"})
+		baseViperCfg.Set("exclude_checks", "HasLicense")
+		baseViperCfg.Set("check_levels", map[string]string{"HasUniqueTag": "warn"})
+		expectedRuntimeCfg.CheckSelection = check.Selection{
+			Include: []string{"RunAsNonRoot", "HasLicense", "HasRequiredLabel"},
+			Exclude: []string{"HasLicense"},
+			Levels:  map[string]string{"HasUniqueTag": "warn"},
+		}
+
+		baseViperCfg.Set("check_timeout", "10m")
+		baseViperCfg.Set("check_timeouts", map[string]string{"DeployableByOLM": "1h"})
+		expectedRuntimeCfg.CheckTimeouts = CheckTimeouts{
+			Default:   10 * time.Minute,
+			Overrides: map[string]time.Duration{"DeployableByOLM": time.Hour},
+		}
 
 		baseViperCfg.Set("pyxis_api_token", "apitoken")
 		expectedRuntimeCfg.PyxisAPIToken = "apitoken"
@@ -41,6 +73,28 @@ var _ = Describe("Viper to Runtime Config", func() {
 		expectedRuntimeCfg.Platform = "s390x"
 		baseViperCfg.Set("insecure", true)
 		expectedRuntimeCfg.Insecure = true
+		baseViperCfg.Set("metadata_only", true)
+		expectedRuntimeCfg.MetadataOnly = true
+		baseViperCfg.Set("parallel_platforms", true)
+		expectedRuntimeCfg.ParallelPlatforms = true
+		baseViperCfg.Set("required_architectures", "amd64, s390x")
+		expectedRuntimeCfg.RequiredArchitectures = []string{"amd64", "s390x"}
+		baseViperCfg.Set("allowed_capabilities", "cap_net_bind_service,cap_net_raw")
+		expectedRuntimeCfg.AllowedCapabilities = []string{"cap_net_bind_service", "cap_net_raw"}
+		baseViperCfg.Set("enforce_secrets_check", true)
+		expectedRuntimeCfg.EnforceSecretsCheck = true
+		baseViperCfg.Set("fips_check", true)
+		expectedRuntimeCfg.FIPSCheck = true
+		baseViperCfg.Set("enable_checks", "HasConsistentArchitectures")
+		expectedRuntimeCfg.EnabledChecks = []string{"HasConsistentArchitectures"}
+		baseViperCfg.Set("sbom_format", "spdx,cyclonedx")
+		expectedRuntimeCfg.SBOMFormats = []string{"spdx", "cyclonedx"}
+		baseViperCfg.Set("advisory_db", "/tmp/advisories")
+		expectedRuntimeCfg.AdvisoryDB = "/tmp/advisories"
+		baseViperCfg.Set("binary_hardening_minimum", "pie,nx")
+		expectedRuntimeCfg.HardeningMinimum = []string{"pie", "nx"}
+		baseViperCfg.Set("base_image_catalog", "/tmp/base-catalog.json")
+		expectedRuntimeCfg.BaseImageCatalog = "/tmp/base-catalog.json"
 
 		baseViperCfg.Set("namespace", "myns")
 		expectedRuntimeCfg.Namespace = "myns"
@@ -66,14 +120,44 @@ var _ = Describe("Viper to Runtime Config", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(*cfg).To(BeEquivalentTo(*expectedRuntimeCfg))
 		})
+		It("should reject an invalid extraction limit", func() {
+			baseViperCfg.Set("extract_max_file_size", "huge")
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(HaveOccurred())
+		})
+		DescribeTable("should reject a negative extraction limit",
+			func(key string, value any) {
+				baseViperCfg.Set(key, value)
+				_, err := NewConfigFrom(*baseViperCfg)
+				Expect(err).To(MatchError(ContainSubstring("must not be negative")))
+			},
+			Entry("max size", "extract_max_size", "-1"),
+			Entry("max file size", "extract_max_file_size", "-1Gi"),
+			Entry("max entries", "extract_max_entries", -1),
+		)
+		It("should reject a negative cache max size", func() {
+			baseViperCfg.Set("cache_max_size", "-1")
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(MatchError(ContainSubstring("must not be negative")))
+		})
+		It("should reject an invalid check timeout", func() {
+			baseViperCfg.Set("check_timeouts", map[string]string{"HasLicense": "soon"})
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(MatchError(ContainSubstring("HasLicense")))
+		})
+		It("should reject an invalid cache max size", func() {
+			baseViperCfg.Set("cache_max_size", "lots")
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
-	It("should only have 26 struct keys for tests to be valid", func() {
+	It("should only have 43 struct keys for tests to be valid", func() {
 		// If this test fails, it means a developer has added or removed
 		// keys from runtime.Config, and so these tests may no longer be
 		// accurate in confirming that the derived configuration from viper
 		// matches.
 		keys := reflect.TypeOf(Config{}).NumField()
-		Expect(keys).To(Equal(26))
+		Expect(keys).To(Equal(43))
 	})
 })
@@ -1,9 +1,66 @@
 package runtime
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 var (
 	DefaultCSVTimeout          = 180 * time.Second
 	DefaultSubscriptionTimeout = 180 * time.Second
 	DefaultScorecardWaitTime   = "240"
+	DefaultParallelism         = 1
+	DefaultCheckTimeout        = 30 * time.Minute
 )
+
+// CheckTimeouts bounds how long each check may run before it is cancelled.
+type CheckTimeouts struct {
+	// Default applies to checks without an override. Zero means no timeout.
+	Default time.Duration
+	// Overrides maps check names to their timeout. Names are matched
+	// case-insensitively. A zero value disables the timeout for that check.
+	Overrides map[string]time.Duration
+}
+
+// For returns the timeout of the check called name, or zero if it has none.
+func (t CheckTimeouts) For(name string) time.Duration {
+	for override, timeout := range t.Overrides {
+		if strings.EqualFold(override, name) {
+			return timeout
+		}
+	}
+	return t.Default
+}
+
+// ExtractionLimits bounds the image filesystem written to disk, protecting
+// against images crafted to exhaust disk space or inodes.
+type ExtractionLimits struct {
+	// MaxSize is the maximum total number of bytes extracted.
+	MaxSize int64
+	// MaxFileSize is the maximum size of any single file, in bytes.
+	MaxFileSize int64
+	// MaxEntries is the maximum number of archive entries extracted.
+	MaxEntries int
+}
+
+// DefaultExtractionLimits are generous enough for any reasonable image.
+var DefaultExtractionLimits = ExtractionLimits{
+	MaxSize:     32 << 30, // 32GiB
+	MaxFileSize: 16 << 30, // 16GiB
+	MaxEntries:  2_000_000,
+}
+
+// WithDefaults returns l with any unset or negative limits replaced by the
+// defaults.
+func (l ExtractionLimits) WithDefaults() ExtractionLimits {
+	if l.MaxSize <= 0 {
+		l.MaxSize = DefaultExtractionLimits.MaxSize
+	}
+	if l.MaxFileSize <= 0 {
+		l.MaxFileSize = DefaultExtractionLimits.MaxFileSize
+	}
+	if l.MaxEntries <= 0 {
+		l.MaxEntries = DefaultExtractionLimits.MaxEntries
+	}
+	return l
+}
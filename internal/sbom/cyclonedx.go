@@ -0,0 +1,124 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
+)
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Type       string        `json:"type"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	Supplier   *cdxSupplier  `json:"supplier,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Licenses   []cdxLicense  `json:"licenses,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxSupplier struct {
+	Name string `json:"name"`
+}
+
+type cdxLicense struct {
+	License cdxLicenseName `json:"license"`
+}
+
+type cdxLicenseName struct {
+	Name string `json:"name"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// MarshalCycloneDX formats s as a CycloneDX 1.5 JSON document. The image is
+// the document's subject, and each component is a library it depends on.
+// Where and in which layer a component was found is recorded in the
+// preflight:location and preflight:layer properties.
+func MarshalCycloneDX(s *SBOM) ([]byte, error) {
+	imageRef := s.Image + "@" + s.Digest
+
+	doc := cdxDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: cdxMetadata{
+			Timestamp: s.Created.Format(time.RFC3339),
+			Tools: cdxTools{Components: []cdxComponent{{
+				Type:    "application",
+				Name:    "preflight",
+				Version: version.Version.Version,
+			}}},
+			Component: cdxComponent{
+				BOMRef:  imageRef,
+				Type:    "container",
+				Name:    s.Image,
+				Version: s.Digest,
+			},
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{{Ref: imageRef, DependsOn: []string{}}},
+	}
+
+	for i, c := range s.Components {
+		// Components found in several places share a package URL, so the
+		// index keeps references unique.
+		ref := fmt.Sprintf("%s-%d", c.PURL, i)
+		if c.PURL == "" {
+			ref = fmt.Sprintf("%s:%s@%s-%d", c.Type, c.Name, c.Version, i)
+		}
+		component := cdxComponent{
+			BOMRef:     ref,
+			Type:       "library",
+			Name:       c.Name,
+			Version:    c.Version,
+			PURL:       c.PURL,
+			Properties: []cdxProperty{{Name: "preflight:type", Value: c.Type}},
+		}
+		if c.Supplier != "" {
+			component.Supplier = &cdxSupplier{Name: c.Supplier}
+		}
+		if c.License != "" {
+			component.Licenses = []cdxLicense{{License: cdxLicenseName{Name: c.License}}}
+		}
+		if c.Path != "" {
+			component.Properties = append(component.Properties, cdxProperty{Name: "preflight:location", Value: c.Path})
+		}
+		if c.Layer != "" {
+			component.Properties = append(component.Properties, cdxProperty{Name: "preflight:layer", Value: c.Layer})
+		}
+		doc.Components = append(doc.Components, component)
+		doc.Dependencies[0].DependsOn = append(doc.Dependencies[0].DependsOn, ref)
+	}
+
+	return json.MarshalIndent(doc, "", "    ")
+}
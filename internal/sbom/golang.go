@@ -0,0 +1,80 @@
+package sbom
+
+import (
+	"bytes"
+	"debug/buildinfo"
+	"debug/elf"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// golangComponents returns the main module, dependencies and standard
+// library of the Go binary at p, as recorded in its embedded build
+// information. Files that are not Go binaries have no components.
+func golangComponents(fileSystem fs.FS, p, imagePath string, c *collector) ([]Component, error) {
+	if info, ok := c.files.Lookup(imagePath); ok && info.Mode&0o111 == 0 {
+		return nil, nil
+	}
+
+	f, err := fileSystem.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, ok := f.(io.ReaderAt)
+	if !ok {
+		return nil, errors.New("file does not support random access")
+	}
+	magic := make([]byte, len(elf.ELFMAG))
+	if _, err := r.ReadAt(magic, 0); err != nil || !bytes.Equal(magic, []byte(elf.ELFMAG)) {
+		return nil, nil
+	}
+
+	info, err := buildinfo.Read(r)
+	if err != nil {
+		// Most binaries are not built with Go.
+		return nil, nil
+	}
+
+	layer := c.layerOf(imagePath)
+	component := func(name, version string) Component {
+		return Component{
+			Type:    TypeGolang,
+			Name:    name,
+			Version: version,
+			PURL:    golangPURL(name, version),
+			Path:    imagePath,
+			Layer:   layer,
+		}
+	}
+
+	components := []Component{component("stdlib", info.GoVersion)}
+	if info.Main.Path != "" {
+		components = append(components, component(info.Main.Path, info.Main.Version))
+	}
+	for _, dep := range info.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		components = append(components, component(dep.Path, dep.Version))
+	}
+	return components, nil
+}
+
+// golangPURL returns the package URL of a Go module, e.g.
+// pkg:golang/github.com/go-logr/logr@v1.4.1. The module path is split
+// into the namespace and name of the package URL.
+func golangPURL(path, version string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = purlEscape(s)
+	}
+	purl := "pkg:golang/" + strings.Join(segments, "/")
+	if version != "" && version != "(devel)" {
+		purl += "@" + purlEscape(version)
+	}
+	return purl
+}
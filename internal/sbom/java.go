@@ -0,0 +1,134 @@
+package sbom
+
+import (
+	"archive/zip"
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// isJavaArchive returns true if p is a Java archive.
+func isJavaArchive(p string) bool {
+	switch path.Ext(p) {
+	case ".jar", ".war", ".ear":
+		return true
+	}
+	return false
+}
+
+// javaComponents returns the Maven artifacts described by the pom.properties
+// files in the Java archive at p. Archives without Maven metadata are
+// described by their manifest instead. Archives nested in the archive are not
+// inspected.
+func javaComponents(fileSystem fs.FS, p, imagePath string, c *collector) ([]Component, error) {
+	f, err := fileSystem.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	r, ok := f.(io.ReaderAt)
+	if !ok {
+		return nil, errors.New("file does not support random access")
+	}
+	archive, err := zip.NewReader(r, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	layer := c.layerOf(imagePath)
+	var components []Component
+	var manifest map[string]string
+	for _, entry := range archive.File {
+		switch {
+		case strings.HasPrefix(entry.Name, "META-INF/maven/") && path.Base(entry.Name) == "pom.properties":
+			properties, err := readJavaProperties(entry, "=")
+			if err != nil {
+				return nil, err
+			}
+			groupID, artifactID, version := properties["groupId"], properties["artifactId"], properties["version"]
+			if artifactID == "" {
+				continue
+			}
+			components = append(components, Component{
+				Type:    TypeJava,
+				Name:    groupID + ":" + artifactID,
+				Version: version,
+				PURL:    mavenPURL(groupID, artifactID, version),
+				Path:    imagePath,
+				Layer:   layer,
+			})
+		case entry.Name == "META-INF/MANIFEST.MF":
+			manifest, err = readJavaProperties(entry, ":")
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(components) > 0 {
+		return components, nil
+	}
+
+	name := manifest["Implementation-Title"]
+	if name == "" {
+		name = manifest["Bundle-SymbolicName"]
+	}
+	if name == "" {
+		name = strings.TrimSuffix(path.Base(imagePath), path.Ext(imagePath))
+	}
+	version := manifest["Implementation-Version"]
+	if version == "" {
+		version = manifest["Bundle-Version"]
+	}
+	return []Component{{
+		Type:    TypeJava,
+		Name:    name,
+		Version: version,
+		Path:    imagePath,
+		Layer:   layer,
+	}}, nil
+}
+
+// readJavaProperties reads the key and value pairs, separated by sep, in a
+// properties file or a manifest. Continuation lines are ignored.
+func readJavaProperties(entry *zip.File, sep string) (map[string]string, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	properties := map[string]string{}
+	scanner := bufio.NewScanner(rc)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, " ") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, sep); ok {
+			properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return properties, scanner.Err()
+}
+
+// mavenPURL returns the package URL of a Maven artifact, e.g.
+// pkg:maven/org.apache.commons/commons-lang3@3.12.0.
+func mavenPURL(groupID, artifactID, version string) string {
+	purl := "pkg:maven/"
+	if groupID != "" {
+		purl += purlEscape(groupID) + "/"
+	}
+	purl += purlEscape(artifactID)
+	if version != "" {
+		purl += "@" + purlEscape(version)
+	}
+	return purl
+}
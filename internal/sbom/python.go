@@ -0,0 +1,82 @@
+package sbom
+
+import (
+	"bufio"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// isPythonMetadata returns true if p is the metadata directory, or file, of
+// an installed Python package.
+func isPythonMetadata(p string) bool {
+	if !strings.Contains(p, "/site-packages/") && !strings.Contains(p, "/dist-packages/") {
+		return false
+	}
+	base := path.Base(p)
+	return strings.HasSuffix(base, ".dist-info") || strings.HasSuffix(base, ".egg-info")
+}
+
+// pythonComponents returns the Python package described by the metadata at
+// p: the METADATA file of a .dist-info directory, the PKG-INFO file of an
+// .egg-info directory, or an .egg-info file.
+func pythonComponents(fileSystem fs.FS, p, imagePath string, c *collector) ([]Component, error) {
+	metadataPath, imageMetadataPath := p, imagePath
+	if info, err := fs.Stat(fileSystem, p); err == nil && info.IsDir() {
+		name := "PKG-INFO"
+		if strings.HasSuffix(p, ".dist-info") {
+			name = "METADATA"
+		}
+		metadataPath, imageMetadataPath = path.Join(p, name), path.Join(imagePath, name)
+	}
+
+	f, err := fileSystem.Open(metadataPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fields := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// The headers end at the first blank line, followed by the description.
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(key, " ") {
+			continue
+		}
+		if _, seen := fields[key]; !seen {
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if fields["Name"] == "" {
+		return nil, nil
+	}
+
+	return []Component{{
+		Type:    TypePython,
+		Name:    fields["Name"],
+		Version: fields["Version"],
+		PURL:    pythonPURL(fields["Name"], fields["Version"]),
+		License: fields["License"],
+		Path:    imageMetadataPath,
+		Layer:   c.layerOf(imageMetadataPath),
+	}}, nil
+}
+
+// pythonPURL returns the package URL of a Python package, e.g.
+// pkg:pypi/requests@2.31.0. Names are normalized as required by PyPI.
+func pythonPURL(name, version string) string {
+	name = strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+	purl := "pkg:pypi/" + purlEscape(name)
+	if version != "" {
+		purl += "@" + purlEscape(version)
+	}
+	return purl
+}
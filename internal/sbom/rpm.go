@@ -0,0 +1,131 @@
+package sbom
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+
+	"github.com/go-logr/logr"
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+)
+
+// rpmDatabases are the locations of the rpm database, newest format first.
+var rpmDatabases = []string{"/var/lib/rpm/rpmdb.sqlite", "/var/lib/rpm/Packages"}
+
+// rpmNamespaces maps os-release IDs to the namespace of their package URLs.
+var rpmNamespaces = map[string]string{
+	"rhel": "redhat",
+}
+
+// rpmComponents returns the packages in the image's rpm database. Each is
+// attributed to the earliest layer holding its files, as a package updated in
+// a later layer rewrites all of them.
+func rpmComponents(ctx context.Context, imgRef image.ImageReference, c *collector) ([]Component, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	pkgList, err := imgRef.ImageFacts().Packages(ctx)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.V(log.DBG).Info("image has no rpm database")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get rpm list: %w", err)
+	}
+
+	var dbPath string
+	for _, p := range rpmDatabases {
+		if _, err := os.Stat(filepath.Join(imgRef.ImageFSPath, filepath.FromSlash(p))); err == nil {
+			dbPath = p
+			break
+		}
+	}
+
+	namespace, distro := "", ""
+	if osRelease, err := imgRef.ImageFacts().OSRelease(); err == nil {
+		namespace = osRelease["ID"]
+		if ns, ok := rpmNamespaces[namespace]; ok {
+			namespace = ns
+		}
+		if osRelease["ID"] != "" && osRelease["VERSION_ID"] != "" {
+			distro = osRelease["ID"] + "-" + osRelease["VERSION_ID"]
+		}
+	}
+
+	components := make([]Component, 0, len(pkgList))
+	for _, pkg := range pkgList {
+		components = append(components, Component{
+			Type:     TypeRPM,
+			Name:     pkg.Name,
+			Version:  rpmVersion(pkg),
+			PURL:     rpmPURL(pkg, namespace, distro),
+			License:  pkg.License,
+			Supplier: pkg.Vendor,
+			Path:     dbPath,
+			Layer:    c.rpmLayer(pkg),
+		})
+	}
+	return components, nil
+}
+
+// rpmVersion returns the [epoch:]version-release of pkg.
+func rpmVersion(pkg *rpmdb.PackageInfo) string {
+	version := pkg.Version + "-" + pkg.Release
+	if pkg.Epoch != nil && *pkg.Epoch != 0 {
+		version = fmt.Sprintf("%d:%s", *pkg.Epoch, version)
+	}
+	return version
+}
+
+// rpmPURL returns the package URL of pkg, e.g.
+// pkg:rpm/redhat/bash@5.1.8-6.el9?arch=x86_64&distro=rhel-9.2.
+func rpmPURL(pkg *rpmdb.PackageInfo, namespace, distro string) string {
+	var b strings.Builder
+	b.WriteString("pkg:rpm/")
+	if namespace != "" {
+		b.WriteString(purlEscape(namespace) + "/")
+	}
+	fmt.Fprintf(&b, "%s@%s", purlEscape(pkg.Name), purlEscape(pkg.Version+"-"+pkg.Release))
+
+	var qualifiers []string
+	if pkg.Arch != "" {
+		qualifiers = append(qualifiers, "arch="+purlEscape(pkg.Arch))
+	}
+	if distro != "" {
+		qualifiers = append(qualifiers, "distro="+purlEscape(distro))
+	}
+	if pkg.Epoch != nil && *pkg.Epoch != 0 {
+		qualifiers = append(qualifiers, fmt.Sprintf("epoch=%d", *pkg.Epoch))
+	}
+	if len(qualifiers) > 0 {
+		b.WriteString("?" + strings.Join(qualifiers, "&"))
+	}
+	return b.String()
+}
+
+// rpmLayer returns the digest of the earliest layer holding the files of pkg.
+func (c *collector) rpmLayer(pkg *rpmdb.PackageInfo) string {
+	files, err := pkg.InstalledFileNames()
+	if err != nil {
+		return ""
+	}
+	layer := -1
+	for _, f := range files {
+		info, ok := c.files.Lookup(f)
+		if !ok || info.Mode.IsDir() {
+			continue
+		}
+		if layer == -1 || info.Layer < layer {
+			layer = info.Layer
+		}
+	}
+	if layer == -1 || layer >= len(c.layerDigests) {
+		return ""
+	}
+	return c.layerDigests[layer]
+}
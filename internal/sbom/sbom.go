@@ -0,0 +1,263 @@
+// Package sbom builds a software bill of materials for an image from its
+// extracted filesystem, and formats it as SPDX or CycloneDX.
+package sbom
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+
+	"github.com/go-logr/logr"
+)
+
+// Format is an SBOM output format.
+type Format string
+
+const (
+	FormatSPDX      Format = "spdx"
+	FormatCycloneDX Format = "cyclonedx"
+)
+
+// Filename returns the name of the artifact the SBOM is written to in f.
+func (f Format) Filename() string {
+	switch f {
+	case FormatSPDX:
+		return "sbom.spdx.json"
+	case FormatCycloneDX:
+		return "sbom.cdx.json"
+	}
+	return ""
+}
+
+// Marshal formats s in f.
+func (s *SBOM) Marshal(f Format) ([]byte, error) {
+	switch f {
+	case FormatSPDX:
+		return MarshalSPDX(s)
+	case FormatCycloneDX:
+		return MarshalCycloneDX(s)
+	}
+	return nil, fmt.Errorf("unknown sbom format %q", f)
+}
+
+// ParseFormats validates the requested SBOM formats. The value none disables
+// SBOM generation. Duplicates are removed.
+func ParseFormats(values []string) ([]Format, error) {
+	var formats []Format
+	for _, v := range values {
+		f := Format(strings.ToLower(strings.TrimSpace(v)))
+		switch f {
+		case "", "none":
+			continue
+		case FormatSPDX, FormatCycloneDX:
+			if !slices.Contains(formats, f) {
+				formats = append(formats, f)
+			}
+		default:
+			return nil, fmt.Errorf("unknown sbom format %q: must be one of %s, %s or none", v, FormatSPDX, FormatCycloneDX)
+		}
+	}
+	return formats, nil
+}
+
+// Types of the components found in an image.
+const (
+	TypeRPM    = "rpm"
+	TypeGolang = "golang"
+	TypePython = "python"
+	TypeJava   = "java"
+)
+
+// Component is a package or module found in the image.
+type Component struct {
+	// Type is one of TypeRPM, TypeGolang, TypePython or TypeJava.
+	Type    string
+	Name    string
+	Version string
+	// PURL is the package URL identifying the component, if known.
+	PURL string
+	// License is the license declared by the component, as recorded in its
+	// metadata. It is not necessarily a valid SPDX license expression.
+	License string
+	// Supplier is the organization that distributed the component.
+	Supplier string
+	// Path is the absolute path in the image of the file the component was
+	// found in, e.g. the rpm database or a Go binary.
+	Path string
+	// Layer is the digest of the layer that added Path.
+	Layer string
+}
+
+// SBOM is the inventory of the components of an image.
+type SBOM struct {
+	// Image is the image reference, e.g. quay.io/example/app:1.0.
+	Image string
+	// Digest is the image's manifest digest.
+	Digest  string
+	Created time.Time
+	// Components are sorted by type, name and version.
+	Components []Component
+}
+
+// excludedPaths are not searched for components.
+var excludedPaths = []string{"/proc", "/sys", "/dev"}
+
+// Collect inventories the components of the image in imgRef. The image must
+// have been extracted, and its file index is used to attribute components to
+// the layer that added them. The SBOM is shared through the image's facts, so
+// the image is only inventoried once per run, and must not be modified.
+func Collect(ctx context.Context, imgRef image.ImageReference) (*SBOM, error) {
+	s, err := imgRef.ImageFacts().SBOM(ctx, func() (any, error) {
+		return collect(ctx, imgRef)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.(*SBOM), nil
+}
+
+// collect inventories the components of the image in imgRef.
+func collect(ctx context.Context, imgRef image.ImageReference) (*SBOM, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	digest, err := imgRef.ImageInfo.Digest()
+	if err != nil {
+		return nil, fmt.Errorf("could not get image digest: %w", err)
+	}
+	layerDigests, err := layerDigests(imgRef)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &collector{files: imgRef.Files, layerDigests: layerDigests}
+
+	components, err := rpmComponents(ctx, imgRef, c)
+	if err != nil {
+		return nil, err
+	}
+
+	fileSystem := os.DirFS(imgRef.ImageFSPath)
+	err = fs.WalkDir(fileSystem, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		imagePath := image.CleanPath(p)
+		if d.IsDir() {
+			for _, excluded := range excludedPaths {
+				if imagePath == excluded {
+					return fs.SkipDir
+				}
+			}
+			if isPythonMetadata(imagePath) {
+				found, err := pythonComponents(fileSystem, p, imagePath, c)
+				if err != nil {
+					logger.V(log.DBG).Info("could not read python package metadata", "path", imagePath, "reason", err)
+				}
+				components = append(components, found...)
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		var found []Component
+		switch {
+		case isPythonMetadata(imagePath):
+			// Packages installed with setup.py may record their metadata
+			// in a single egg-info file.
+			found, err = pythonComponents(fileSystem, p, imagePath, c)
+		case isJavaArchive(imagePath):
+			found, err = javaComponents(fileSystem, p, imagePath, c)
+		default:
+			found, err = golangComponents(fileSystem, p, imagePath, c)
+		}
+		if err != nil {
+			logger.V(log.DBG).Info("could not read components", "path", imagePath, "reason", err)
+		}
+		components = append(components, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read image filesystem: %w", err)
+	}
+
+	sort.SliceStable(components, func(i, j int) bool {
+		a, b := components[i], components[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Path < b.Path
+	})
+	logger.V(log.DBG).Info("collected sbom components", "count", len(components))
+
+	return &SBOM{
+		Image:      imgRef.ImageURI,
+		Digest:     digest.String(),
+		Created:    time.Now().UTC(),
+		Components: components,
+	}, nil
+}
+
+// collector attributes the files components are found in to layers.
+type collector struct {
+	files        *image.FileIndex
+	layerDigests []string
+}
+
+// layerOf returns the digest of the layer that added the file at p, or an
+// empty string if it is not known.
+func (c *collector) layerOf(p string) string {
+	info, ok := c.files.Lookup(p)
+	if !ok || info.Layer >= len(c.layerDigests) {
+		return ""
+	}
+	return c.layerDigests[info.Layer]
+}
+
+func layerDigests(imgRef image.ImageReference) ([]string, error) {
+	layers, err := imgRef.ImageInfo.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("could not get image layers: %w", err)
+	}
+	digests := make([]string, 0, len(layers))
+	for _, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			return nil, fmt.Errorf("could not get layer digest: %w", err)
+		}
+		digests = append(digests, digest.String())
+	}
+	return digests, nil
+}
+
+// purlEscape escapes a component of a package URL.
+func purlEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune(".-_~", r):
+			b.WriteRune(r)
+		default:
+			for _, c := range []byte(string(r)) {
+				fmt.Fprintf(&b, "%%%02X", c)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,272 @@
+package sbom
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SBOM", func() {
+	Context("parsing formats", func() {
+		It("should accept known formats and remove duplicates", func() {
+			formats, err := ParseFormats([]string{"spdx", " CycloneDX", "spdx"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(formats).To(Equal([]Format{FormatSPDX, FormatCycloneDX}))
+		})
+		It("should disable SBOMs with none", func() {
+			formats, err := ParseFormats([]string{"none"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(formats).To(BeEmpty())
+		})
+		It("should reject unknown formats", func() {
+			_, err := ParseFormats([]string{"spdx", "swid"})
+			Expect(err).To(MatchError(ContainSubstring(`unknown sbom format "swid"`)))
+		})
+		It("should name the artifact of each format", func() {
+			Expect(FormatSPDX.Filename()).To(Equal("sbom.spdx.json"))
+			Expect(FormatCycloneDX.Filename()).To(Equal("sbom.cdx.json"))
+		})
+	})
+
+	Context("collecting components", func() {
+		var (
+			imgRef  image.ImageReference
+			digests []string
+		)
+
+		writeFile := func(p string, contents []byte, mode os.FileMode) {
+			fullPath := filepath.Join(imgRef.ImageFSPath, filepath.FromSlash(p))
+			Expect(os.MkdirAll(filepath.Dir(fullPath), 0o755)).To(Succeed())
+			Expect(os.WriteFile(fullPath, contents, mode)).To(Succeed())
+			imgRef.Files.Add(image.FileInfo{Path: p, Mode: mode, Layer: 1})
+		}
+
+		BeforeEach(func() {
+			img, err := random.Image(256, 2)
+			Expect(err).ToNot(HaveOccurred())
+			digests, err = layerDigests(image.ImageReference{ImageInfo: img})
+			Expect(err).ToNot(HaveOccurred())
+
+			imgRef = image.ImageReference{
+				ImageURI:    "quay.io/example/app:1.0",
+				ImageFSPath: GinkgoT().TempDir(),
+				ImageInfo:   img,
+				Files:       image.NewFileIndex(),
+			}
+		})
+
+		It("should find python packages from their metadata", func() {
+			writeFile("/usr/lib/python3.9/site-packages/requests-2.31.0.dist-info/METADATA",
+				[]byte("Metadata-Version: 2.1\nName: requests\nVersion: 2.31.0\nLicense: Apache 2.0\n\nName: not a header\n"), 0o644)
+			writeFile("/usr/lib/python3.9/site-packages/six-1.16.0-py3.9.egg-info",
+				[]byte("Metadata-Version: 1.1\nName: six\nVersion: 1.16.0\n"), 0o644)
+			writeFile("/usr/lib/python3.9/site-packages/Jinja2-3.1.2.egg-info/PKG-INFO",
+				[]byte("Metadata-Version: 1.1\nName: Jinja2\nVersion: 3.1.2\n"), 0o644)
+
+			s, err := Collect(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Components).To(HaveLen(3))
+			Expect(s.Components[0]).To(Equal(Component{
+				Type:    TypePython,
+				Name:    "Jinja2",
+				Version: "3.1.2",
+				PURL:    "pkg:pypi/jinja2@3.1.2",
+				Path:    "/usr/lib/python3.9/site-packages/Jinja2-3.1.2.egg-info/PKG-INFO",
+				Layer:   digests[1],
+			}))
+			Expect(s.Components[1].Name).To(Equal("requests"))
+			Expect(s.Components[1].License).To(Equal("Apache 2.0"))
+			Expect(s.Components[2].PURL).To(Equal("pkg:pypi/six@1.16.0"))
+		})
+
+		It("should find maven artifacts in java archives", func() {
+			writeFile("/opt/app/lib/commons-lang3.jar", jarWith(map[string]string{
+				"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\nImplementation-Title: ignored\n",
+				"META-INF/maven/org.apache.commons/commons-lang3/pom.properties": "#Generated\ngroupId=org.apache.commons\nartifactId=commons-lang3\nversion=3.12.0\n",
+			}), 0o644)
+			writeFile("/opt/app/lib/plain.jar", jarWith(map[string]string{
+				"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\r\nImplementation-Title: plain-lib\r\nImplementation-Version: 2.0\r\n",
+			}), 0o644)
+
+			s, err := Collect(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Components).To(HaveLen(2))
+			Expect(s.Components[0].Name).To(Equal("org.apache.commons:commons-lang3"))
+			Expect(s.Components[0].PURL).To(Equal("pkg:maven/org.apache.commons/commons-lang3@3.12.0"))
+			Expect(s.Components[0].Layer).To(Equal(digests[1]))
+			Expect(s.Components[1].Name).To(Equal("plain-lib"))
+			Expect(s.Components[1].Version).To(Equal("2.0"))
+			Expect(s.Components[1].PURL).To(BeEmpty())
+		})
+
+		It("should find the modules of go binaries", func() {
+			executable, err := os.Executable()
+			Expect(err).ToNot(HaveOccurred())
+			contents, err := os.ReadFile(executable)
+			Expect(err).ToNot(HaveOccurred())
+			writeFile("/usr/bin/app", contents, 0o755)
+			// Go binaries are expected to be executable.
+			writeFile("/usr/share/app/copy", contents, 0o644)
+
+			s, err := Collect(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Components).To(ContainElement(And(
+				HaveField("Type", TypeGolang),
+				HaveField("Name", "stdlib"),
+				HaveField("Path", "/usr/bin/app"),
+				HaveField("Layer", digests[1]),
+			)))
+			Expect(s.Components).To(ContainElement(HaveField("Name", "github.com/onsi/ginkgo/v2")))
+			Expect(s.Components).ToNot(ContainElement(HaveField("Path", "/usr/share/app/copy")))
+		})
+
+		It("should record the image and its digest", func() {
+			digest, err := imgRef.ImageInfo.Digest()
+			Expect(err).ToNot(HaveOccurred())
+
+			s, err := Collect(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Image).To(Equal("quay.io/example/app:1.0"))
+			Expect(s.Digest).To(Equal(digest.String()))
+			Expect(s.Components).To(BeEmpty())
+		})
+
+		It("should only inventory the image once per run", func() {
+			imgRef.Facts = image.NewFacts(imgRef.ImageInfo, imgRef.ImageFSPath)
+			first, err := Collect(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+
+			writeFile("/usr/lib/python3.9/site-packages/six-1.16.0-py3.9.egg-info",
+				[]byte("Metadata-Version: 1.1\nName: six\nVersion: 1.16.0\n"), 0o644)
+			second, err := Collect(context.TODO(), imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(second).To(BeIdenticalTo(first))
+			Expect(second.Components).To(BeEmpty())
+		})
+	})
+
+	Context("formatting", func() {
+		var s *SBOM
+
+		BeforeEach(func() {
+			s = &SBOM{
+				Image:   "quay.io/example/app:1.0",
+				Digest:  "sha256:0123456789abcdef",
+				Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Components: []Component{
+					{
+						Type:     TypeRPM,
+						Name:     "bash",
+						Version:  "5.1.8-6.el9",
+						PURL:     "pkg:rpm/redhat/bash@5.1.8-6.el9?arch=x86_64",
+						License:  "GPLv3+",
+						Supplier: "Red Hat, Inc.",
+						Path:     "/var/lib/rpm/rpmdb.sqlite",
+						Layer:    "sha256:aaaa",
+					},
+					{
+						Type:    TypeJava,
+						Name:    "plain-lib",
+						Version: "2.0",
+						Path:    "/opt/app/lib/plain.jar",
+					},
+				},
+			}
+		})
+
+		It("should write an SPDX document", func() {
+			out, err := s.Marshal(FormatSPDX)
+			Expect(err).ToNot(HaveOccurred())
+
+			var doc spdxDocument
+			Expect(json.Unmarshal(out, &doc)).To(Succeed())
+			Expect(doc.SPDXVersion).To(Equal("SPDX-2.3"))
+			Expect(doc.DocumentNamespace).To(HaveSuffix("quay.io%2Fexample%2Fapp:1.0-0123456789abcdef"))
+			Expect(doc.CreationInfo.Created).To(Equal("2024-01-02T03:04:05Z"))
+			Expect(doc.Packages).To(HaveLen(3))
+			Expect(doc.Packages[0].PrimaryPackagePurpose).To(Equal("CONTAINER"))
+
+			bash := doc.Packages[1]
+			Expect(bash.SPDXID).To(Equal("SPDXRef-rpm-bash-0"))
+			Expect(bash.Supplier).To(Equal("Organization: Red Hat, Inc."))
+			Expect(bash.LicenseComments).To(Equal("GPLv3+"))
+			Expect(bash.SourceInfo).To(Equal("found in /var/lib/rpm/rpmdb.sqlite, added by layer sha256:aaaa"))
+			Expect(bash.ExternalRefs).To(ConsistOf(spdxExternalRef{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  "pkg:rpm/redhat/bash@5.1.8-6.el9?arch=x86_64",
+			}))
+			Expect(doc.Packages[2].ExternalRefs).To(BeEmpty())
+			Expect(doc.Packages[2].Supplier).To(Equal("NOASSERTION"))
+
+			Expect(doc.Relationships).To(ConsistOf(
+				spdxRelationship{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Image"},
+				spdxRelationship{SPDXElementID: "SPDXRef-Image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-rpm-bash-0"},
+				spdxRelationship{SPDXElementID: "SPDXRef-Image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-java-plain-lib-1"},
+			))
+		})
+
+		It("should write a CycloneDX document", func() {
+			out, err := s.Marshal(FormatCycloneDX)
+			Expect(err).ToNot(HaveOccurred())
+
+			var doc cdxDocument
+			Expect(json.Unmarshal(out, &doc)).To(Succeed())
+			Expect(doc.BOMFormat).To(Equal("CycloneDX"))
+			Expect(doc.SpecVersion).To(Equal("1.5"))
+			Expect(doc.Metadata.Component.Type).To(Equal("container"))
+			Expect(doc.Metadata.Component.BOMRef).To(Equal("quay.io/example/app:1.0@sha256:0123456789abcdef"))
+			Expect(doc.Components).To(HaveLen(2))
+
+			bash := doc.Components[0]
+			Expect(bash.PURL).To(Equal("pkg:rpm/redhat/bash@5.1.8-6.el9?arch=x86_64"))
+			Expect(bash.Supplier).To(Equal(&cdxSupplier{Name: "Red Hat, Inc."}))
+			Expect(bash.Licenses).To(ConsistOf(cdxLicense{License: cdxLicenseName{Name: "GPLv3+"}}))
+			Expect(bash.Properties).To(ConsistOf(
+				cdxProperty{Name: "preflight:type", Value: TypeRPM},
+				cdxProperty{Name: "preflight:location", Value: "/var/lib/rpm/rpmdb.sqlite"},
+				cdxProperty{Name: "preflight:layer", Value: "sha256:aaaa"},
+			))
+			Expect(doc.Components[1].BOMRef).To(Equal("java:plain-lib@2.0-1"))
+			Expect(doc.Dependencies).To(ConsistOf(cdxDependency{
+				Ref:       doc.Metadata.Component.BOMRef,
+				DependsOn: []string{bash.BOMRef, doc.Components[1].BOMRef},
+			}))
+		})
+
+		It("should reject unknown formats", func() {
+			_, err := s.Marshal(Format("swid"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+// jarWith returns a zip archive holding files.
+func jarWith(files map[string]string) []byte {
+	f, err := os.CreateTemp(GinkgoT().TempDir(), "*.jar")
+	Expect(err).ToNot(HaveOccurred())
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, contents := range files {
+		entry, err := w.Create(name)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = io.WriteString(entry, contents)
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(w.Close()).To(Succeed())
+
+	contents, err := os.ReadFile(f.Name())
+	Expect(err).ToNot(HaveOccurred())
+	return contents
+}
@@ -0,0 +1,140 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
+)
+
+// spdxNamespacePrefix is prepended to the image and its digest to form the
+// namespace of an SPDX document, so that the SBOMs of an image share it.
+const spdxNamespacePrefix = "https://github.com/redhat-openshift-ecosystem/openshift-preflight/spdx/"
+
+// spdxInvalidIDChars are the characters not allowed in an SPDX identifier.
+var spdxInvalidIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	Supplier              string            `json:"supplier,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	LicenseComments       string            `json:"licenseComments,omitempty"`
+	SourceInfo            string            `json:"sourceInfo,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// MarshalSPDX formats s as an SPDX 2.3 JSON document. The image is the
+// document's only described package, and contains a package per component.
+// Component licenses are not validated as SPDX license expressions, so they
+// are recorded as license comments.
+func MarshalSPDX(s *SBOM) ([]byte, error) {
+	const imageID = "SPDXRef-Image"
+
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              s.Image,
+		DocumentNamespace: spdxNamespacePrefix + url.PathEscape(s.Image) + "-" + strings.TrimPrefix(s.Digest, "sha256:"),
+		CreationInfo: spdxCreationInfo{
+			Created:  s.Created.Format(time.RFC3339),
+			Creators: []string{"Tool: preflight-" + version.Version.Version},
+		},
+		Packages: []spdxPackage{{
+			Name:                  s.Image,
+			SPDXID:                imageID,
+			VersionInfo:           s.Digest,
+			DownloadLocation:      "NOASSERTION",
+			LicenseConcluded:      "NOASSERTION",
+			LicenseDeclared:       "NOASSERTION",
+			PrimaryPackagePurpose: "CONTAINER",
+		}},
+		Relationships: []spdxRelationship{{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: imageID,
+		}},
+	}
+
+	for i, c := range s.Components {
+		id := spdxInvalidIDChars.ReplaceAllString(fmt.Sprintf("SPDXRef-%s-%s-%d", c.Type, c.Name, i), "-")
+		pkg := spdxPackage{
+			Name:             c.Name,
+			SPDXID:           id,
+			VersionInfo:      c.Version,
+			Supplier:         "NOASSERTION",
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  "NOASSERTION",
+			LicenseComments:  c.License,
+			SourceInfo:       sourceInfo(c),
+		}
+		if c.Supplier != "" {
+			pkg.Supplier = "Organization: " + c.Supplier
+		}
+		if c.PURL != "" {
+			pkg.ExternalRefs = []spdxExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  c.PURL,
+			}}
+		}
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      imageID,
+			RelationshipType:   "CONTAINS",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	return json.MarshalIndent(doc, "", "    ")
+}
+
+// sourceInfo describes where in the image c was found.
+func sourceInfo(c Component) string {
+	if c.Path == "" {
+		return ""
+	}
+	if c.Layer == "" {
+		return fmt.Sprintf("found in %s", c.Path)
+	}
+	return fmt.Sprintf("found in %s, added by layer %s", c.Path, c.Layer)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
 	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/events"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/engine"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
@@ -25,6 +26,8 @@ func NewCheck(image, indeximage string, kubeconfig []byte, opts ...Option) *oper
 		scorecardWaitTime:   runtime.DefaultScorecardWaitTime,
 		csvTimeout:          runtime.DefaultCSVTimeout,
 		subscriptionTimeout: runtime.DefaultSubscriptionTimeout,
+		extractionLimits:    runtime.DefaultExtractionLimits,
+		checkTimeouts:       runtime.CheckTimeouts{Default: runtime.DefaultCheckTimeout},
 	}
 
 	for _, opt := range opts {
@@ -42,12 +45,20 @@ func (c operatorCheck) Run(ctx context.Context) (certification.Results, error) {
 	}
 
 	cfg := runtime.Config{
-		Image:        c.image,
-		DockerConfig: c.dockerConfigFilePath,
-		Scratch:      true,
-		Bundle:       true,
-		Insecure:     c.insecure,
-		Platform:     goruntime.GOARCH,
+		Image:            c.image,
+		DockerConfig:     c.dockerConfigFilePath,
+		Scratch:          true,
+		Bundle:           true,
+		Insecure:         c.insecure,
+		Platform:         goruntime.GOARCH,
+		Parallelism:      c.parallelism,
+		CacheDir:         c.cacheDir,
+		CacheMaxSize:     c.cacheMaxSize,
+		ExtractionLimits: c.extractionLimits,
+		CheckTimeouts:    c.checkTimeouts,
+	}
+	if c.eventHandler != nil {
+		ctx = events.ContextWithHandler(ctx, c.eventHandler)
 	}
 	eng, err := engine.New(ctx, c.checks, c.kubeconfig, cfg)
 	if err != nil {
@@ -65,7 +76,10 @@ func (c operatorCheck) Run(ctx context.Context) (certification.Results, error) {
 		return certification.Results{}, err
 	}
 
-	return eng.Results(ctx), nil
+	results := eng.Results(ctx)
+	results.Partial = c.partial
+	results.Skipped = append(results.Skipped, c.skipped...)
+	return results, nil
 }
 
 func (c *operatorCheck) resolve(ctx context.Context) error {
@@ -73,13 +87,8 @@ func (c *operatorCheck) resolve(ctx context.Context) error {
 		return nil
 	}
 
-	switch {
-	case c.image == "":
+	if c.image == "" {
 		return preflighterr.ErrImageEmpty
-	case c.kubeconfig == nil:
-		return preflighterr.ErrKubeconfigEmpty
-	case c.indeximage == "":
-		return preflighterr.ErrIndexImageEmpty
 	}
 
 	c.policy = policy.PolicyOperator
@@ -98,12 +107,49 @@ func (c *operatorCheck) resolve(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("%w: %s", preflighterr.ErrCannotInitializeChecks, err)
 	}
+	if !c.selection.IsZero() {
+		selected, err := c.selection.Apply(newChecks)
+		if err != nil {
+			return fmt.Errorf("%w: %s", preflighterr.ErrCannotInitializeChecks, err)
+		}
+		c.partial = len(selected) != len(newChecks) || len(check.LevelOverrides(selected)) > 0
+		c.skipped = nil
+		for _, chk := range check.Unselected(newChecks, selected) {
+			c.skipped = append(c.skipped, certification.Result{
+				Check:       chk,
+				SkipReason:  certification.SkipReasonUserExclusion,
+				SkipMessage: "excluded by the check selection",
+			})
+		}
+		newChecks = selected
+	}
+
+	// A cluster, and the index image deployed to it, are only needed
+	// if a selected check requires one.
+	if requiresCluster(newChecks) {
+		switch {
+		case c.kubeconfig == nil:
+			return preflighterr.ErrKubeconfigEmpty
+		case c.indeximage == "":
+			return preflighterr.ErrIndexImageEmpty
+		}
+	}
 	c.checks = newChecks
 	c.resolved = true
 
 	return nil
 }
 
+// requiresCluster returns true if any of checks requires a cluster.
+func requiresCluster(checks []check.Check) bool {
+	for _, c := range checks {
+		if check.RequirementsOf(c).Has(check.RequiresCluster) {
+			return true
+		}
+	}
+	return false
+}
+
 // List the available operator checks.
 func (c operatorCheck) List(ctx context.Context) (policy.Policy, []check.Check, error) {
 	return c.policy, c.checks, c.resolve(ctx)
@@ -180,6 +226,78 @@ func WithSubscriptionTimeout(subscriptionTimeout time.Duration) Option {
 	}
 }
 
+// WithParallelism sets the maximum number of checks that may run concurrently.
+// Checks that interact with the cluster always run by themselves. Values less
+// than 1 are treated as 1, running checks one at a time.
+func WithParallelism(n int) Option {
+	return func(oc *operatorCheck) {
+		oc.parallelism = n
+	}
+}
+
+// WithLayerCache stores image layers in the persistent cache at dir, so they
+// can be reused by later runs. The cache is pruned to maxSize bytes after the
+// check has run, if no other process is using it. A maxSize of zero or less
+// leaves the cache unbounded.
+func WithLayerCache(dir string, maxSize int64) Option {
+	return func(oc *operatorCheck) {
+		oc.cacheDir = dir
+		oc.cacheMaxSize = maxSize
+	}
+}
+
+// WithExtractionLimits bounds how much of the image is extracted to disk:
+// the total number of bytes, the size of any single file, and the number of
+// archive entries. Exceeding a limit returns an error matching
+// errors.ErrExtractionLimitExceeded. Zero values use preflight's defaults.
+func WithExtractionLimits(maxSize, maxFileSize int64, maxEntries int) Option {
+	return func(oc *operatorCheck) {
+		oc.extractionLimits = runtime.ExtractionLimits{
+			MaxSize:     maxSize,
+			MaxFileSize: maxFileSize,
+			MaxEntries:  maxEntries,
+		}.WithDefaults()
+	}
+}
+
+// WithCheckTimeouts cancels any check running for longer than its timeout: the
+// timeout in overrides for its name, matched case-insensitively, or def. A zero
+// timeout disables cancellation. Cancelled checks are recorded in the TimedOut
+// results, and the remaining checks keep running. The default timeout is 30 minutes.
+func WithCheckTimeouts(def time.Duration, overrides map[string]time.Duration) Option {
+	return func(oc *operatorCheck) {
+		oc.checkTimeouts = runtime.CheckTimeouts{
+			Default:   def,
+			Overrides: overrides,
+		}
+	}
+}
+
+// WithCheckSelection runs only the checks of the policy named in include, or all
+// of them if include is empty, except those named in exclude. The level of any
+// check named in levels is overridden, e.g. to "warn". Excluding DeployableByOLM
+// and the scorecard checks allows the remaining checks to run without a cluster.
+// Results of a run whose checks differ from the policy are marked as partial,
+// and cannot be submitted.
+func WithCheckSelection(include, exclude []string, levels map[string]string) Option {
+	return func(oc *operatorCheck) {
+		oc.selection = check.Selection{
+			Include: include,
+			Exclude: exclude,
+			Levels:  levels,
+		}
+	}
+}
+
+// WithEventHandler calls h with the events of the run as it progresses: the
+// image being pulled and extracted, checks starting and finishing, and artifacts
+// being written. Use events.Channel to receive events on a channel instead.
+func WithEventHandler(h events.Handler) Option {
+	return func(oc *operatorCheck) {
+		oc.eventHandler = h
+	}
+}
+
 type operatorCheck struct {
 	// required
 	image      string
@@ -198,4 +316,13 @@ type operatorCheck struct {
 	policy                  policy.Policy
 	csvTimeout              time.Duration
 	subscriptionTimeout     time.Duration
+	parallelism             int
+	cacheDir                string
+	cacheMaxSize            int64
+	extractionLimits        runtime.ExtractionLimits
+	checkTimeouts           runtime.CheckTimeouts
+	selection               check.Selection
+	partial                 bool
+	skipped                 []certification.Result
+	eventHandler            events.Handler
 }
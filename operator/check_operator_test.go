@@ -6,6 +6,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
 	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
 )
 
@@ -91,5 +92,14 @@ var _ = Describe("Operator Check Execution", func() {
 			_, err := chk.Run(context.TODO())
 			Expect(err).To(MatchError(preflighterr.ErrIndexImageEmpty))
 		})
+
+		It("should not need a cluster if the checks requiring one were excluded", func() {
+			chk := NewCheck("image", "", nil, WithCheckSelection(nil, []string{"DeployableByOLM", "ScorecardBasicSpecCheck", "ScorecardOlmSuiteCheck"}, nil))
+			Expect(chk.resolve(context.TODO())).To(Succeed())
+			Expect(chk.checks).To(HaveLen(6))
+			Expect(chk.partial).To(BeTrue())
+			Expect(chk.skipped).To(HaveLen(3))
+			Expect(chk.skipped[0].SkipReason).To(Equal(certification.SkipReasonUserExclusion))
+		})
 	})
 })